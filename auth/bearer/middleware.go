@@ -49,16 +49,44 @@ func (m *AuthenticationMiddleware) ID() string {
 	return authenticationMiddlewareID
 }
 
+type credentialsOverrideKey struct{}
+
+// SetCredentialsOverride returns a context with the bearer token that will be
+// used to sign the operation's request, instead of retrieving a token from
+// the AuthenticationMiddleware's TokenProvider, (e.g. a token for another
+// account). Only the operation invoked with the returned context is affected,
+// other operations sharing the same stack continue to use the TokenProvider.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetCredentialsOverride(ctx context.Context, token Token) context.Context {
+	return middleware.WithStackValue(ctx, credentialsOverrideKey{}, token)
+}
+
+// GetCredentialsOverride returns the bearer token overriding the
+// TokenProvider for the operation, and if one was set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetCredentialsOverride(ctx context.Context) (Token, bool) {
+	v, ok := middleware.GetStackValue(ctx, credentialsOverrideKey{}).(Token)
+	return v, ok
+}
+
 // HandleFinalize implements the FinalizeMiddleware interface in order to
-// update the request with bearer token authentication.
+// update the request with bearer token authentication. The token set with
+// SetCredentialsOverride is preferred over the TokenProvider's token.
 func (m *AuthenticationMiddleware) HandleFinalize(
 	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
 ) (
 	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
 ) {
-	token, err := m.tokenProvider.RetrieveBearerToken(ctx)
-	if err != nil {
-		return out, metadata, fmt.Errorf("failed AuthenticationMiddleware wrap message, %w", err)
+	token, ok := GetCredentialsOverride(ctx)
+	if !ok {
+		token, err = m.tokenProvider.RetrieveBearerToken(ctx)
+		if err != nil {
+			return out, metadata, fmt.Errorf("failed AuthenticationMiddleware wrap message, %w", err)
+		}
 	}
 
 	signedMessage, err := m.signer.SignWithBearerToken(ctx, token, in.Request)
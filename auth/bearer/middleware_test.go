@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/aws/smithy-go/middleware"
 	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -76,3 +78,71 @@ func TestSignHTTPSMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticationMiddleware_CredentialsOverride(t *testing.T) {
+	stack := middleware.NewStack("stack", smithyhttp.NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("OperationSerializer", func(
+		ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+	) (
+		middleware.SerializeOutput, middleware.Metadata, error,
+	) {
+		in.Request.(*smithyhttp.Request).URL, _ = url.Parse("https://example.aws")
+		return next.HandleSerialize(ctx, in)
+	}), middleware.After)
+
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer", func(
+		ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+	) (
+		out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+	) {
+		out, metadata, err = next.HandleDeserialize(ctx, in)
+		out.Result = out.RawResponse
+		return out, metadata, err
+	}), middleware.After)
+
+	err := AddAuthenticationMiddleware(stack, NewSignHTTPSMessage(), TokenProviderFunc(
+		func(context.Context) (Token, error) {
+			return Token{Value: "default"}, nil
+		}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		return input.(*smithyhttp.Request).Header.Get("Authorization"), middleware.Metadata{}, nil
+	}), stack)
+
+	// Invoke operations with and without the override concurrently, sharing
+	// the same stack.
+	const count = 10
+	results := make([]interface{}, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.Background()
+			if i%2 == 0 {
+				ctx = SetCredentialsOverride(ctx, Token{Value: "override"})
+			}
+			result, _, err := handler.Handle(ctx, struct{}{})
+			if err != nil {
+				t.Errorf("expect no error, got %v", err)
+			}
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		expect := "Bearer default"
+		if i%2 == 0 {
+			expect = "Bearer override"
+		}
+		if e, a := expect, result; e != a {
+			t.Errorf("expect operation %d authorization %v, got %v", i, e, a)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package httpbinding
+
+import (
+	"net/url"
+)
+
+// PathEncoding is the policy of how a request's URI path is encoded in the
+// canonical request a signature is computed from. The request's path is
+// always sent single encoded, as encoded by the Encoder.
+type PathEncoding int
+
+// Enumeration of the canonical path encoding policies.
+const (
+	// PathEncodingDouble encodes the escaped path of the request again,
+	// (e.g. "a%20b" is signed as "a%2520b"). The default for most services.
+	PathEncodingDouble PathEncoding = iota
+
+	// PathEncodingSingle uses the escaped path of the request as is, (e.g.
+	// Amazon S3).
+	PathEncodingSingle
+)
+
+// CanonicalPath returns the URI path of the URL to be used in the canonical
+// request of a signature, encoded according to the policy. The URL's escaped
+// path, as sent on the wire, is not modified. An empty path is canonicalized
+// as "/".
+func CanonicalPath(u *url.URL, encoding PathEncoding) string {
+	path := u.EscapedPath()
+	if len(path) == 0 {
+		return "/"
+	}
+
+	if encoding == PathEncodingDouble {
+		path = EscapePath(path, false)
+	}
+
+	return path
+}
@@ -0,0 +1,77 @@
+package httpbinding
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestCanonicalPath(t *testing.T) {
+	cases := map[string]struct {
+		Path         string
+		Key          string
+		ExpectWire   string
+		ExpectSingle string
+		ExpectDouble string
+	}{
+		"special characters": {
+			Path:         "/{Bucket}/{Key+}",
+			Key:          "my dir/a+b=c%é.txt",
+			ExpectWire:   "/bucket/my%20dir/a%2Bb%3Dc%25%C3%A9.txt",
+			ExpectSingle: "/bucket/my%20dir/a%2Bb%3Dc%25%C3%A9.txt",
+			ExpectDouble: "/bucket/my%2520dir/a%252Bb%253Dc%2525%25C3%25A9.txt",
+		},
+		"unreserved characters": {
+			Path:         "/{Bucket}/{Key+}",
+			Key:          "plain-key_1.0~",
+			ExpectWire:   "/bucket/plain-key_1.0~",
+			ExpectSingle: "/bucket/plain-key_1.0~",
+			ExpectDouble: "/bucket/plain-key_1.0~",
+		},
+		"escaped separator": {
+			Path:         "/{Bucket}/{Key}",
+			Key:          "a/b",
+			ExpectWire:   "/bucket/a%2Fb",
+			ExpectSingle: "/bucket/a%2Fb",
+			ExpectDouble: "/bucket/a%252Fb",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := NewEncoder(c.Path, "", http.Header{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := encoder.SetURI("Bucket").String("bucket"); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := encoder.SetURI("Key").String(c.Key); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			req, err := encoder.Encode(&http.Request{URL: &url.URL{}, Header: http.Header{}})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectWire, req.URL.EscapedPath(); e != a {
+				t.Errorf("expect %v wire path, got %v", e, a)
+			}
+			if e, a := c.ExpectSingle, CanonicalPath(req.URL, PathEncodingSingle); e != a {
+				t.Errorf("expect %v single encoded path, got %v", e, a)
+			}
+			if e, a := c.ExpectDouble, CanonicalPath(req.URL, PathEncodingDouble); e != a {
+				t.Errorf("expect %v double encoded path, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestCanonicalPath_empty(t *testing.T) {
+	for _, encoding := range []PathEncoding{PathEncodingSingle, PathEncodingDouble} {
+		if e, a := "/", CanonicalPath(&url.URL{}, encoding); e != a {
+			t.Errorf("expect %v path, got %v", e, a)
+		}
+	}
+}
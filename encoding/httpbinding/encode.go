@@ -116,6 +116,49 @@ func (e *Encoder) AddQuery(key string) QueryValue {
 	return NewQueryValue(e.query, key, true)
 }
 
+// SetQueryList sets the query key to each of the values, as repeated query
+// parameters, (e.g. "?key=a&key=b"). Replaces any existing values of the key.
+// The key is removed if values is empty.
+func (e *Encoder) SetQueryList(key string, values []string) {
+	e.query.Del(key)
+	for _, v := range values {
+		e.query.Add(key, v)
+	}
+}
+
+// SetQueryMap sets a query parameter for each entry of the map, named by the
+// entry's key with the prefix, (e.g. "?tag.key=value" for prefix "tag.").
+// With an empty prefix, the map is bound as a smithy httpQueryParams member.
+//
+// Entries whose query key already has a value are skipped, so that query
+// parameters explicitly bound with SetQuery or AddQuery take precedence over
+// the map's entries. The map should be set after the explicitly bound query
+// parameters.
+func (e *Encoder) SetQueryMap(prefix string, m map[string]string) {
+	for k, v := range m {
+		key := prefix + k
+		if _, ok := e.query[key]; ok {
+			continue
+		}
+		e.query.Set(key, v)
+	}
+}
+
+// SetQueryMapList sets repeated query parameters for each entry of the map
+// of lists, named by the entry's key with the prefix. Entries whose query key
+// already has a value are skipped, as with SetQueryMap.
+func (e *Encoder) SetQueryMapList(prefix string, m map[string][]string) {
+	for k, vs := range m {
+		key := prefix + k
+		if _, ok := e.query[key]; ok {
+			continue
+		}
+		for _, v := range vs {
+			e.query.Add(key, v)
+		}
+	}
+}
+
 // HasQuery returns if a query with the key specified exists with one or
 // more values.
 func (e *Encoder) HasQuery(key string) bool {
@@ -144,3 +144,77 @@ func TestEncodeContentLength(t *testing.T) {
 		})
 	}
 }
+
+func TestEncoderQueryMapAndList(t *testing.T) {
+	cases := map[string]struct {
+		Encode      func(*Encoder)
+		ExpectQuery string
+	}{
+		"list": {
+			Encode: func(e *Encoder) {
+				e.SetQueryList("id", []string{"a b", "c&d", "e"})
+			},
+			ExpectQuery: "existing=value&id=a+b&id=c%26d&id=e",
+		},
+		"empty list": {
+			Encode: func(e *Encoder) {
+				e.SetQueryList("existing", nil)
+			},
+			ExpectQuery: "",
+		},
+		"list replaces existing": {
+			Encode: func(e *Encoder) {
+				e.SetQueryList("existing", []string{"1", "2"})
+			},
+			ExpectQuery: "existing=1&existing=2",
+		},
+		"map with prefix": {
+			Encode: func(e *Encoder) {
+				e.SetQueryMap("tag.", map[string]string{
+					"env":  "prod",
+					"team": "a/b",
+				})
+			},
+			ExpectQuery: "existing=value&tag.env=prod&tag.team=a%2Fb",
+		},
+		"query params map": {
+			Encode: func(e *Encoder) {
+				e.SetQuery("explicit").String("bound")
+				e.SetQueryMap("", map[string]string{
+					"explicit": "ignored",
+					"other":    "value=1",
+				})
+			},
+			ExpectQuery: "existing=value&explicit=bound&other=value%3D1",
+		},
+		"map of lists": {
+			Encode: func(e *Encoder) {
+				e.SetQueryMapList("", map[string][]string{
+					"a":        {"1", "2"},
+					"existing": {"ignored"},
+				})
+			},
+			ExpectQuery: "a=1&a=2&existing=value",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := NewEncoder("/", "existing=value", http.Header{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			c.Encode(encoder)
+
+			req := &http.Request{URL: &url.URL{}}
+			if req, err = encoder.Encode(req); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if e, a := c.ExpectQuery, req.URL.RawQuery; e != a {
+				t.Errorf("expect %v query, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package httpbinding
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	smithytime "github.com/aws/smithy-go/time"
+)
+
+// Binding is the location of an HTTP request or response a member is bound
+// to.
+type Binding int
+
+// Enumeration of the HTTP binding locations.
+const (
+	BindingHeader Binding = iota
+	BindingQuery
+	BindingLabel
+	BindingPayload
+)
+
+func (b Binding) String() string {
+	switch b {
+	case BindingHeader:
+		return "header"
+	case BindingQuery:
+		return "query"
+	case BindingLabel:
+		return "label"
+	case BindingPayload:
+		return "payload"
+	default:
+		return fmt.Sprintf("Binding(%d)", int(b))
+	}
+}
+
+// TimestampFormat is the Smithy timestampFormat trait value a timestamp is
+// serialized with.
+type TimestampFormat string
+
+// Enumeration of the Smithy timestamp formats.
+const (
+	TimestampFormatDateTime     TimestampFormat = "date-time"
+	TimestampFormatHTTPDate     TimestampFormat = "http-date"
+	TimestampFormatEpochSeconds TimestampFormat = "epoch-seconds"
+)
+
+// DefaultTimestampFormat returns the timestamp format used for the binding
+// location when the member does not have a timestampFormat trait. Headers
+// default to http-date, query string and URI labels to date-time, and
+// payloads to epoch-seconds. Protocols whose documents use a different
+// default, (e.g. XML date-time), must provide the format explicitly.
+func DefaultTimestampFormat(binding Binding) TimestampFormat {
+	switch binding {
+	case BindingHeader:
+		return TimestampFormatHTTPDate
+	case BindingQuery, BindingLabel:
+		return TimestampFormatDateTime
+	default:
+		return TimestampFormatEpochSeconds
+	}
+}
+
+// FormatTimestampForBinding formats the timestamp for the binding location.
+// If format is empty the binding location's default format is used, see
+// DefaultTimestampFormat. Returns an error if the format is unknown.
+func FormatTimestampForBinding(t time.Time, binding Binding, format TimestampFormat) (string, error) {
+	if len(format) == 0 {
+		format = DefaultTimestampFormat(binding)
+	}
+
+	switch format {
+	case TimestampFormatDateTime:
+		return smithytime.FormatDateTime(t), nil
+	case TimestampFormatHTTPDate:
+		return smithytime.FormatHTTPDate(t), nil
+	case TimestampFormatEpochSeconds:
+		return strconv.FormatFloat(smithytime.FormatEpochSeconds(t), 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unknown timestamp format %q for %v binding", format, binding)
+	}
+}
+
+// FormatEnum returns the serialized value of an enum or intEnum member, so
+// that enum values are serialized identically regardless of binding location.
+// String enums are serialized as their value, and integer enums as their
+// decimal value. Returns an error if the value is not a string or integer
+// kind.
+func FormatEnum(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	default:
+		return "", fmt.Errorf("unsupported enum type %T", v)
+	}
+}
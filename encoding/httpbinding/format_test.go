@@ -0,0 +1,114 @@
+package httpbinding
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTimestampForBinding(t *testing.T) {
+	ts := time.Date(2015, 10, 21, 7, 28, 0, 500000000, time.UTC)
+
+	cases := map[string]struct {
+		Binding   Binding
+		Format    TimestampFormat
+		Expect    string
+		ExpectErr bool
+	}{
+		"header default": {
+			Binding: BindingHeader,
+			Expect:  "Wed, 21 Oct 2015 07:28:00 GMT",
+		},
+		"query default": {
+			Binding: BindingQuery,
+			Expect:  "2015-10-21T07:28:00.5Z",
+		},
+		"label default": {
+			Binding: BindingLabel,
+			Expect:  "2015-10-21T07:28:00.5Z",
+		},
+		"payload default": {
+			Binding: BindingPayload,
+			Expect:  "1445412480.5",
+		},
+		"header epoch seconds": {
+			Binding: BindingHeader,
+			Format:  TimestampFormatEpochSeconds,
+			Expect:  "1445412480.5",
+		},
+		"query http date": {
+			Binding: BindingQuery,
+			Format:  TimestampFormatHTTPDate,
+			Expect:  "Wed, 21 Oct 2015 07:28:00 GMT",
+		},
+		"payload date time": {
+			Binding: BindingPayload,
+			Format:  TimestampFormatDateTime,
+			Expect:  "2015-10-21T07:28:00.5Z",
+		},
+		"unknown format": {
+			Binding:   BindingHeader,
+			Format:    "unix",
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := FormatTimestampForBinding(ts, c.Binding, c.Format)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Expect, v; e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestFormatEnum(t *testing.T) {
+	type stringEnum string
+	type intEnum int32
+
+	cases := map[string]struct {
+		Value     interface{}
+		Expect    string
+		ExpectErr bool
+	}{
+		"string enum": {
+			Value:  stringEnum("FOO_BAR"),
+			Expect: "FOO_BAR",
+		},
+		"int enum": {
+			Value:  intEnum(2),
+			Expect: "2",
+		},
+		"unsupported": {
+			Value:     1.5,
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := FormatEnum(c.Value)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Expect, v; e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ type Array struct {
 	w          *bytes.Buffer
 	writeComma bool
 	scratch    *[]byte
+	sortKeys   bool
 }
 
 func newArray(w *bytes.Buffer, scratch *[]byte) *Array {
@@ -26,7 +27,9 @@ func (a *Array) Value() Value {
 		a.writeComma = true
 	}
 
-	return newValue(a.w, a.scratch)
+	v := newValue(a.w, a.scratch)
+	v.sortKeys = a.sortKeys
+	return v
 }
 
 // Close encodes the end of the JSON Array
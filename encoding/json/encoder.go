@@ -11,12 +11,32 @@ type Encoder struct {
 	Value
 }
 
+// EncoderOptions is the set of options that can be configured for an Encoder.
+type EncoderOptions struct {
+	// Sets if the members of JSON objects, including map entries, are
+	// emitted sorted by key, instead of in the order they were added. Allows
+	// the encoding of maps to be deterministic, regardless of Go's map
+	// iteration order, (e.g. for bodies that are signed, or golden tested).
+	//
+	// Members are buffered until the object is closed, so each member's
+	// value must be fully encoded before the next member is added.
+	SortKeys bool
+}
+
 // NewEncoder returns a new JSON encoder
-func NewEncoder() *Encoder {
+func NewEncoder(optFns ...func(*EncoderOptions)) *Encoder {
+	var options EncoderOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
 	writer := bytes.NewBuffer(nil)
 	scratch := make([]byte, 64)
 
-	return &Encoder{w: writer, Value: newValue(writer, &scratch)}
+	value := newValue(writer, &scratch)
+	value.sortKeys = options.SortKeys
+
+	return &Encoder{w: writer, Value: value}
 }
 
 // String returns the String output of the JSON encoder
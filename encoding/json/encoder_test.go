@@ -32,3 +32,43 @@ func TestEncoder(t *testing.T) {
 		t.Errorf("expected %s, but got %s", e, a)
 	}
 }
+
+func TestEncoder_SortKeys(t *testing.T) {
+	m := map[string]map[string]int64{
+		"zeta":  {"b": 2, "a": 1},
+		"alpha": {"y": 25, "x": 24},
+		"mu":    {},
+		"beta":  {"c": 3},
+	}
+
+	encode := func() string {
+		encoder := json.NewEncoder(func(o *json.EncoderOptions) {
+			o.SortKeys = true
+		})
+
+		object := encoder.Object()
+		for k, v := range m {
+			inner := object.Key(k).Object()
+			for ik, iv := range v {
+				inner.Key(ik).Long(iv)
+			}
+			inner.Close()
+		}
+		list := object.Key("list").Array()
+		listObj := list.Value().Object()
+		listObj.Key("2").Boolean(true)
+		listObj.Key("1").Null()
+		listObj.Close()
+		list.Close()
+		object.Close()
+
+		return encoder.String()
+	}
+
+	expect := `{"alpha":{"x":24,"y":25},"beta":{"c":3},"list":[{"1":null,"2":true}],"mu":{},"zeta":{"a":1,"b":2}}`
+	for i := 0; i < 20; i++ {
+		if e, a := expect, encode(); e != a {
+			t.Fatalf("expect encode %d to be\n%s\ngot\n%s", i, e, a)
+		}
+	}
+}
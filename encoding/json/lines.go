@@ -0,0 +1,91 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// LineDecoder decodes newline delimited JSON records, (JSON Lines), from a
+// reader one record at a time, without buffering the whole body. Blank lines
+// are skipped.
+type LineDecoder struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewLineDecoder returns an initialized LineDecoder reading records from r.
+func NewLineDecoder(r io.Reader) *LineDecoder {
+	return &LineDecoder{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Decode decodes the next record into v. Returns io.EOF when there are no
+// more records.
+//
+// The last record may omit the trailing newline. If the last line is an
+// incomplete record, (e.g. the body was truncated), an error wrapping
+// io.ErrUnexpectedEOF is returned.
+func (d *LineDecoder) Decode(v interface{}) error {
+	line, err := d.next()
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(line, v); err != nil {
+		return fmt.Errorf("failed to decode JSON line %d, %w", d.line, err)
+	}
+	return nil
+}
+
+// next returns the next non-blank line, without the newline. Records split
+// across reads of the underlying reader are joined.
+func (d *LineDecoder) next() ([]byte, error) {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read JSON line %d, %w", d.line+1, err)
+		}
+		atEOF := err == io.EOF
+
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			if atEOF {
+				return nil, io.EOF
+			}
+			d.line++
+			continue
+		}
+		d.line++
+
+		if atEOF && !json.Valid(trimmed) {
+			return nil, fmt.Errorf("incomplete JSON line %d, %w", d.line, io.ErrUnexpectedEOF)
+		}
+		return trimmed, nil
+	}
+}
+
+// DecodeLines reads newline delimited JSON records from r, invoking fn with
+// each record as it is read. Stops at the first error returned by fn.
+func DecodeLines(r io.Reader, fn func(record json.RawMessage) error) error {
+	d := NewLineDecoder(r)
+	for {
+		line, err := d.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !json.Valid(line) {
+			return fmt.Errorf("failed to decode JSON line %d, invalid JSON", d.line)
+		}
+		if err := fn(json.RawMessage(line)); err != nil {
+			return err
+		}
+	}
+}
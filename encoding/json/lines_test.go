@@ -0,0 +1,151 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type lineRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// splitReader returns the content in reads of at most n bytes.
+type splitReader struct {
+	content string
+	n       int
+}
+
+func (r *splitReader) Read(p []byte) (int, error) {
+	if len(r.content) == 0 {
+		return 0, io.EOF
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	n := copy(p, r.content)
+	r.content = r.content[n:]
+	return n, nil
+}
+
+func TestLineDecoder(t *testing.T) {
+	cases := map[string]struct {
+		Reader    io.Reader
+		Expect    []lineRecord
+		ExpectErr error
+	}{
+		"records split across reads": {
+			Reader: &splitReader{
+				content: "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n{\"id\":3,\"name\":\"c\"}\n",
+				n:       7,
+			},
+			Expect: []lineRecord{{1, "a"}, {2, "b"}, {3, "c"}},
+		},
+		"one byte reads": {
+			Reader: iotest.OneByteReader(strings.NewReader(
+				"{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n{\"id\":3,\"name\":\"c\"}\n")),
+			Expect: []lineRecord{{1, "a"}, {2, "b"}, {3, "c"}},
+		},
+		"blank lines and CRLF": {
+			Reader: strings.NewReader("\n{\"id\":1,\"name\":\"a\"}\r\n\r\n{\"id\":2,\"name\":\"b\"}\r\n"),
+			Expect: []lineRecord{{1, "a"}, {2, "b"}},
+		},
+		"no trailing newline": {
+			Reader: strings.NewReader("{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}"),
+			Expect: []lineRecord{{1, "a"}, {2, "b"}},
+		},
+		"incomplete trailing line": {
+			Reader:    strings.NewReader("{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"na"),
+			Expect:    []lineRecord{{1, "a"}},
+			ExpectErr: io.ErrUnexpectedEOF,
+		},
+		"empty": {
+			Reader: strings.NewReader(""),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := NewLineDecoder(c.Reader)
+
+			var actual []lineRecord
+			var err error
+			for {
+				var record lineRecord
+				if err = d.Decode(&record); err != nil {
+					break
+				}
+				actual = append(actual, record)
+			}
+
+			if c.ExpectErr != nil {
+				if !errors.Is(err, c.ExpectErr) {
+					t.Errorf("expect %v error, got %v", c.ExpectErr, err)
+				}
+			} else if err != io.EOF {
+				t.Errorf("expect EOF, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.Expect, actual); len(diff) != 0 {
+				t.Errorf("expect records to match\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLineDecoder_InvalidRecord(t *testing.T) {
+	d := NewLineDecoder(strings.NewReader("{\"id\":1}\nnot json\n"))
+
+	var record lineRecord
+	if err := d.Decode(&record); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	err := d.Decode(&record)
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := "line 2", err.Error(); !strings.Contains(a, e) {
+		t.Errorf("expect error to contain %q, got %q", e, a)
+	}
+}
+
+func TestDecodeLines(t *testing.T) {
+	r := &splitReader{
+		content: "{\"id\":1}\n[1,2]\n\"three\"\n",
+		n:       3,
+	}
+
+	var actual []string
+	err := DecodeLines(r, func(record json.RawMessage) error {
+		actual = append(actual, string(record))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expect := []string{`{"id":1}`, `[1,2]`, `"three"`}
+	if diff := cmp.Diff(expect, actual); len(diff) != 0 {
+		t.Errorf("expect records to match\n%s", diff)
+	}
+
+	stopErr := errors.New("stop")
+	var count int
+	err = DecodeLines(strings.NewReader("1\n2\n3\n"), func(json.RawMessage) error {
+		count++
+		return stopErr
+	})
+	if !errors.Is(err, stopErr) {
+		t.Errorf("expect %v error, got %v", stopErr, err)
+	}
+	if e, a := 1, count; e != a {
+		t.Errorf("expect %v records, got %v", e, a)
+	}
+}
@@ -2,6 +2,7 @@ package json
 
 import (
 	"bytes"
+	"sort"
 )
 
 // Object represents the encoding of a JSON Object type
@@ -9,6 +10,16 @@ type Object struct {
 	w          *bytes.Buffer
 	writeComma bool
 	scratch    *[]byte
+
+	// If set, members are buffered, and written sorted by key when the
+	// object is closed.
+	sortKeys bool
+	members  []objectMember
+}
+
+type objectMember struct {
+	key string
+	buf *bytes.Buffer
 }
 
 func newObject(w *bytes.Buffer, scratch *[]byte) *Object {
@@ -16,25 +27,47 @@ func newObject(w *bytes.Buffer, scratch *[]byte) *Object {
 	return &Object{w: w, scratch: scratch}
 }
 
-func (o *Object) writeKey(key string) {
-	escapeStringBytes(o.w, []byte(key))
-	o.w.WriteRune(colon)
+func (o *Object) writeKey(w *bytes.Buffer, key string) {
+	escapeStringBytes(w, []byte(key))
+	w.WriteRune(colon)
 }
 
 // Key adds the given named key to the JSON object.
 // Returns a Value encoder that should be used to encode
 // a JSON value type.
 func (o *Object) Key(name string) Value {
+	if o.sortKeys {
+		buf := bytes.NewBuffer(nil)
+		o.members = append(o.members, objectMember{key: name, buf: buf})
+		o.writeKey(buf, name)
+
+		v := newValue(buf, o.scratch)
+		v.sortKeys = true
+		return v
+	}
+
 	if o.writeComma {
 		o.w.WriteRune(comma)
 	} else {
 		o.writeComma = true
 	}
-	o.writeKey(name)
+	o.writeKey(o.w, name)
 	return newValue(o.w, o.scratch)
 }
 
 // Close encodes the end of the JSON Object
 func (o *Object) Close() {
+	if o.sortKeys {
+		sort.SliceStable(o.members, func(i, j int) bool {
+			return o.members[i].key < o.members[j].key
+		})
+		for i, m := range o.members {
+			if i > 0 {
+				o.w.WriteRune(comma)
+			}
+			o.w.Write(m.buf.Bytes())
+		}
+		o.members = nil
+	}
 	o.w.WriteRune(rightBrace)
 }
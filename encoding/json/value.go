@@ -12,8 +12,9 @@ import (
 // Value represents a JSON Value type
 // JSON Value types: Object, Array, String, Number, Boolean, and Null
 type Value struct {
-	w       *bytes.Buffer
-	scratch *[]byte
+	w        *bytes.Buffer
+	scratch  *[]byte
+	sortKeys bool
 }
 
 // newValue returns a new Value encoder
@@ -86,12 +87,16 @@ func (jv Value) Write(v []byte) {
 
 // Array returns a new Array encoder
 func (jv Value) Array() *Array {
-	return newArray(jv.w, jv.scratch)
+	a := newArray(jv.w, jv.scratch)
+	a.sortKeys = jv.sortKeys
+	return a
 }
 
 // Object returns a new Object encoder
 func (jv Value) Object() *Object {
-	return newObject(jv.w, jv.scratch)
+	o := newObject(jv.w, jv.scratch)
+	o.sortKeys = jv.sortKeys
+	return o
 }
 
 // Null encodes a null JSON value
@@ -9,8 +9,9 @@ import (
 // ErrorComponents represents the error response fields
 // that will be deserialized from an xml error response body
 type ErrorComponents struct {
-	Code    string
-	Message string
+	Code      string
+	Message   string
+	RequestID string
 }
 
 // GetErrorResponseComponents returns the error fields from an xml error response body
@@ -21,8 +22,9 @@ func GetErrorResponseComponents(r io.Reader, noErrorWrapping bool) (ErrorCompone
 			return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
 		}
 		return ErrorComponents{
-			Code:    errResponse.Code,
-			Message: errResponse.Message,
+			Code:      errResponse.Code,
+			Message:   errResponse.Message,
+			RequestID: errResponse.RequestID,
 		}, nil
 	}
 
@@ -31,21 +33,134 @@ func GetErrorResponseComponents(r io.Reader, noErrorWrapping bool) (ErrorCompone
 		return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
 	}
 	return ErrorComponents{
-		Code:    errResponse.Code,
-		Message: errResponse.Message,
+		Code:      errResponse.Code,
+		Message:   errResponse.Message,
+		RequestID: errResponse.RequestID,
 	}, nil
 }
 
 // noWrappedErrorResponse represents the error response body with
 // no internal <Error></Error wrapping
 type noWrappedErrorResponse struct {
-	Code    string `xml:"Code"`
-	Message string `xml:"Message"`
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+	RequestID string `xml:"RequestId"`
 }
 
 // wrappedErrorResponse represents the error response body
 // wrapped within <Error>...</Error>
 type wrappedErrorResponse struct {
-	Code    string `xml:"Error>Code"`
-	Message string `xml:"Error>Message"`
+	Code      string `xml:"Error>Code"`
+	Message   string `xml:"Error>Message"`
+	RequestID string `xml:"RequestId"`
+}
+
+// ErrorResponseOptions provides the options for decoding the components of an
+// XML error response body with DecodeErrorResponseComponents.
+type ErrorResponseOptions struct {
+	// The names of elements that wrap the error response fields. Wrapper
+	// elements are descended into at any depth when searching for the error
+	// fields. The root element is always descended into.
+	//
+	// Defaults to ErrorResponse, Errors, and Error.
+	WrapperElements []string
+
+	// The names of the element containing the request ID.
+	//
+	// Defaults to RequestId, and RequestID.
+	RequestIDElements []string
+}
+
+// DecodeErrorResponseComponents returns the error fields from an XML error
+// response body, regardless of whether the error fields are wrapped within an
+// error element. Supports both the wrapped, (e.g.
+// <ErrorResponse><Error><Code>...</Code></Error></ErrorResponse>), and
+// unwrapped, (e.g. <Error><Code>...</Code></Error>), forms. The first value
+// found for each field is used.
+//
+// An empty response body returns empty components, and no error.
+func DecodeErrorResponseComponents(r io.Reader, optFns ...func(*ErrorResponseOptions)) (ErrorComponents, error) {
+	options := ErrorResponseOptions{
+		WrapperElements:   []string{"ErrorResponse", "Errors", "Error"},
+		RequestIDElements: []string{"RequestId", "RequestID"},
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	var ec ErrorComponents
+
+	decoder := xml.NewDecoder(r)
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			return ec, nil
+		}
+		if err != nil {
+			return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
+		}
+
+		if start, ok := t.(xml.StartElement); ok {
+			if err := decodeErrorComponents(decoder, start, options, &ec); err != nil {
+				return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
+			}
+			return ec, nil
+		}
+	}
+}
+
+// decodeErrorComponents walks the children of the element, populating the
+// error components, and descending into wrapper elements.
+func decodeErrorComponents(decoder *xml.Decoder, start xml.StartElement, options ErrorResponseOptions, ec *ErrorComponents) error {
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch el := t.(type) {
+		case xml.EndElement:
+			return nil
+
+		case xml.StartElement:
+			name := el.Name.Local
+
+			var field *string
+			switch {
+			case name == "Code":
+				field = &ec.Code
+			case name == "Message":
+				field = &ec.Message
+			case containsString(options.RequestIDElements, name):
+				field = &ec.RequestID
+			case containsString(options.WrapperElements, name):
+				if err := decodeErrorComponents(decoder, el, options, ec); err != nil {
+					return err
+				}
+				continue
+			default:
+				if err := decoder.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+
+			var v string
+			if err := decoder.DecodeElement(&v, &el); err != nil {
+				return err
+			}
+			if len(*field) == 0 {
+				*field = v
+			}
+		}
+	}
+}
+
+func containsString(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+	return false
 }
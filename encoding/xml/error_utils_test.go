@@ -60,3 +60,102 @@ func TestGetResponseErrorCode(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeErrorResponseComponents(t *testing.T) {
+	cases := map[string]struct {
+		errorResponse string
+		optFns        []func(*ErrorResponseOptions)
+		expect        ErrorComponents
+	}{
+		"wrapped": {
+			errorResponse: `<ErrorResponse>
+    <Error>
+        <Type>Sender</Type>
+        <Code>InvalidGreeting</Code>
+        <Message>Hi</Message>
+        <AnotherSetting>setting</AnotherSetting>
+    </Error>
+    <RequestId>foo-id</RequestId>
+</ErrorResponse>`,
+			expect: ErrorComponents{
+				Code:      "InvalidGreeting",
+				Message:   "Hi",
+				RequestID: "foo-id",
+			},
+		},
+		"unwrapped": {
+			errorResponse: `<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+    <Code>NoSuchKey</Code>
+    <Message>The resource you requested does not exist</Message>
+    <Resource>/mybucket/myfoto.jpg</Resource>
+    <RequestId>4442587FB7D0A2F9</RequestId>
+</Error>`,
+			expect: ErrorComponents{
+				Code:      "NoSuchKey",
+				Message:   "The resource you requested does not exist",
+				RequestID: "4442587FB7D0A2F9",
+			},
+		},
+		"unwrapped fields in response element": {
+			errorResponse: `<ErrorResponse>
+    <Type>Sender</Type>
+    <Code>InvalidGreeting</Code>
+    <Message>Hi</Message>
+    <RequestId>foo-id</RequestId>
+</ErrorResponse>`,
+			expect: ErrorComponents{
+				Code:      "InvalidGreeting",
+				Message:   "Hi",
+				RequestID: "foo-id",
+			},
+		},
+		"nested errors list": {
+			errorResponse: `<Response>
+    <Errors>
+        <Error>
+            <Code>InvalidInstanceID.Malformed</Code>
+            <Message>Invalid id</Message>
+        </Error>
+    </Errors>
+    <RequestID>ea966190-f9aa-478e-9ede-example</RequestID>
+</Response>`,
+			expect: ErrorComponents{
+				Code:      "InvalidInstanceID.Malformed",
+				Message:   "Invalid id",
+				RequestID: "ea966190-f9aa-478e-9ede-example",
+			},
+		},
+		"custom wrapper element": {
+			errorResponse: `<Fault>
+    <Detail>
+        <Code>InvalidGreeting</Code>
+        <Message>Hi</Message>
+    </Detail>
+</Fault>`,
+			optFns: []func(*ErrorResponseOptions){
+				func(o *ErrorResponseOptions) {
+					o.WrapperElements = []string{"Detail"}
+				},
+			},
+			expect: ErrorComponents{
+				Code:    "InvalidGreeting",
+				Message: "Hi",
+			},
+		},
+		"no response body": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ec, err := DecodeErrorResponseComponents(strings.NewReader(c.errorResponse), c.optFns...)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if e, a := c.expect, ec; e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package smithy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOperationError(t *testing.T) {
+	baseErr := fmt.Errorf("connection reset")
+	apiErr := &GenericAPIError{Code: "ThrottlingException", Message: "slow down"}
+
+	cases := map[string]struct {
+		Err error
+	}{
+		"generic error": {Err: baseErr},
+		"api error":     {Err: apiErr},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var err error = &OperationError{
+				ServiceID:     "DynamoDB",
+				OperationName: "GetItem",
+				Err:           c.Err,
+			}
+
+			if e, a := c.Err, errors.Unwrap(err); e != a {
+				t.Errorf("expect %v unwrapped, got %v", e, a)
+			}
+			if !errors.Is(err, c.Err) {
+				t.Errorf("expect error to be %v", c.Err)
+			}
+
+			msg := err.Error()
+			for _, v := range []string{"DynamoDB", "GetItem", c.Err.Error()} {
+				if !strings.Contains(msg, v) {
+					t.Errorf("expect error to contain %q, got %q", v, msg)
+				}
+			}
+		})
+	}
+
+	var err error = &OperationError{ServiceID: "DynamoDB", OperationName: "GetItem", Err: apiErr}
+	var target APIError
+	if !errors.As(err, &target) {
+		t.Fatalf("expect API error accessible, got %v", err)
+	}
+	if e, a := "ThrottlingException", target.ErrorCode(); e != a {
+		t.Errorf("expect %v error code, got %v", e, a)
+	}
+}
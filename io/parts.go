@@ -0,0 +1,89 @@
+package io
+
+import (
+	"fmt"
+	"io"
+)
+
+// Part is a section of a source to be uploaded as a single part of a
+// multipart upload. Each part has an independent reader over the source, so
+// parts may be uploaded concurrently.
+type Part struct {
+	// The 1-based part number of the part.
+	Number int
+
+	// The offset of the first byte of the part within the source.
+	Offset int64
+
+	// The length of the part in bytes.
+	Size int64
+
+	// The reader of the part's bytes. The reader is seekable, and may be
+	// rewound to retry the part's upload.
+	Reader *io.SectionReader
+}
+
+// Range returns the inclusive byte range of the part within the source,
+// formatted as in the Content-Range header, (e.g. "bytes 0-1023/*"). Returns
+// an empty string if the part is empty.
+func (p Part) Range() string {
+	if p.Size == 0 {
+		return ""
+	}
+	return fmt.Sprintf("bytes %d-%d/*", p.Offset, p.Offset+p.Size-1)
+}
+
+// SplitParts returns the parts of the source, each partSize bytes long
+// except for the last part, which contains the remaining bytes. A source of
+// zero bytes has a single part of zero bytes.
+//
+// The source must implement io.ReaderAt, (e.g. *os.File, *bytes.Reader), so
+// that the parts can be read concurrently. The source's size is determined
+// by seeking to its end, and the source's offset is restored before
+// returning. The parts read the source from its start, not its current
+// offset.
+func SplitParts(source io.ReadSeeker, partSize int64) ([]Part, error) {
+	if partSize <= 0 {
+		return nil, fmt.Errorf("part size must be greater than zero, got %d", partSize)
+	}
+
+	readerAt, ok := source.(io.ReaderAt)
+	if !ok {
+		return nil, fmt.Errorf("source must implement io.ReaderAt, got %T", source)
+	}
+
+	offset, err := source.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source offset, %w", err)
+	}
+	size, err := source.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source size, %w", err)
+	}
+	if _, err := source.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to restore source offset, %w", err)
+	}
+
+	if size == 0 {
+		return []Part{{
+			Number: 1,
+			Reader: io.NewSectionReader(readerAt, 0, 0),
+		}}, nil
+	}
+
+	parts := make([]Part, 0, (size+partSize-1)/partSize)
+	for off := int64(0); off < size; off += partSize {
+		n := partSize
+		if remain := size - off; remain < n {
+			n = remain
+		}
+		parts = append(parts, Part{
+			Number: len(parts) + 1,
+			Offset: off,
+			Size:   n,
+			Reader: io.NewSectionReader(readerAt, off, n),
+		})
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,99 @@
+package io
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSplitParts(t *testing.T) {
+	cases := map[string]struct {
+		Source       string
+		PartSize     int64
+		ExpectRanges []string
+	}{
+		"uneven parts": {
+			Source:       "abcdefghij",
+			PartSize:     4,
+			ExpectRanges: []string{"bytes 0-3/*", "bytes 4-7/*", "bytes 8-9/*"},
+		},
+		"even parts": {
+			Source:       "abcdefgh",
+			PartSize:     4,
+			ExpectRanges: []string{"bytes 0-3/*", "bytes 4-7/*"},
+		},
+		"single part": {
+			Source:       "abc",
+			PartSize:     4,
+			ExpectRanges: []string{"bytes 0-2/*"},
+		},
+		"empty source": {
+			Source:       "",
+			PartSize:     4,
+			ExpectRanges: []string{""},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			parts, err := SplitParts(strings.NewReader(c.Source), c.PartSize)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := len(c.ExpectRanges), len(parts); e != a {
+				t.Fatalf("expect %v parts, got %v", e, a)
+			}
+
+			// Read the parts concurrently, and reassemble them in order.
+			bodies := make([][]byte, len(parts))
+			var wg sync.WaitGroup
+			for i, part := range parts {
+				wg.Add(1)
+				go func(i int, part Part) {
+					defer wg.Done()
+					b, err := ioutil.ReadAll(part.Reader)
+					if err != nil {
+						t.Errorf("expect no error, got %v", err)
+					}
+					bodies[i] = b
+				}(i, part)
+			}
+			wg.Wait()
+
+			var offset int64
+			for i, part := range parts {
+				if e, a := i+1, part.Number; e != a {
+					t.Errorf("expect part number %v, got %v", e, a)
+				}
+				if e, a := offset, part.Offset; e != a {
+					t.Errorf("expect part %v offset %v, got %v", part.Number, e, a)
+				}
+				if e, a := part.Size, int64(len(bodies[i])); e != a {
+					t.Errorf("expect part %v size %v, got %v", part.Number, e, a)
+				}
+				if e, a := c.ExpectRanges[i], part.Range(); e != a {
+					t.Errorf("expect part %v range %v, got %v", part.Number, e, a)
+				}
+				offset += part.Size
+			}
+
+			if e, a := c.Source, string(bytes.Join(bodies, nil)); e != a {
+				t.Errorf("expect parts to cover source %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestSplitParts_Errors(t *testing.T) {
+	if _, err := SplitParts(strings.NewReader("abc"), 0); err == nil {
+		t.Errorf("expect error for zero part size, got none")
+	}
+
+	source := ReadSeekNopCloser{ReadSeeker: strings.NewReader("abc")}
+	if _, err := SplitParts(source, 1); err == nil {
+		t.Errorf("expect error for source without ReadAt, got none")
+	}
+}
@@ -0,0 +1,86 @@
+package io
+
+import (
+	"fmt"
+	"io"
+)
+
+// RewindBufferExceededError is the error returned when a RewindBuffer cannot
+// be rewound because more bytes were read than the buffer retains.
+type RewindBufferExceededError struct {
+	// The number of bytes the buffer retains.
+	Size int
+
+	// The number of bytes read from the underlying reader.
+	Offset int64
+}
+
+func (e *RewindBufferExceededError) Error() string {
+	return fmt.Sprintf("cannot rewind stream, read %d bytes exceeds rewind buffer size %d",
+		e.Offset, e.Size)
+}
+
+// RewindBuffer wraps a reader, retaining the first bytes read in memory so
+// that the reader can be rewound to its start, as long as no more than the
+// buffer's size was read. Allows a stream to be retried if it failed early,
+// without buffering the whole stream.
+//
+// Once more than the buffer's size is read, the retained bytes are released,
+// and the reader can no longer be rewound.
+type RewindBuffer struct {
+	reader io.Reader
+	size   int
+
+	buf      []byte
+	pos      int
+	offset   int64
+	exceeded bool
+}
+
+// NewRewindBuffer returns a RewindBuffer reading from the reader, retaining
+// up to size bytes for rewinding.
+func NewRewindBuffer(reader io.Reader, size int) *RewindBuffer {
+	return &RewindBuffer{
+		reader: reader,
+		size:   size,
+	}
+}
+
+// Size returns the number of bytes the buffer retains for rewinding.
+func (r *RewindBuffer) Size() int {
+	return r.size
+}
+
+// Read reads the bytes retained from before the buffer was rewound, then
+// from the underlying reader.
+func (r *RewindBuffer) Read(p []byte) (int, error) {
+	if r.pos < len(r.buf) {
+		n := copy(p, r.buf[r.pos:])
+		r.pos += n
+		return n, nil
+	}
+
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.offset += int64(n)
+		if !r.exceeded && len(r.buf)+n <= r.size {
+			r.buf = append(r.buf, p[:n]...)
+			r.pos = len(r.buf)
+		} else if !r.exceeded {
+			r.exceeded = true
+			r.buf = nil
+			r.pos = 0
+		}
+	}
+	return n, err
+}
+
+// Rewind rewinds the reader to its start. Returns a
+// RewindBufferExceededError if more bytes were read than the buffer retains.
+func (r *RewindBuffer) Rewind() error {
+	if r.exceeded {
+		return &RewindBufferExceededError{Size: r.size, Offset: r.offset}
+	}
+	r.pos = 0
+	return nil
+}
@@ -0,0 +1,74 @@
+package io
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestRewindBuffer(t *testing.T) {
+	cases := map[string]struct {
+		Size      int
+		ReadFirst int64
+		ExpectErr bool
+	}{
+		"within buffer": {
+			Size:      8,
+			ReadFirst: 5,
+		},
+		"at buffer size": {
+			Size:      8,
+			ReadFirst: 8,
+		},
+		"past buffer": {
+			Size:      8,
+			ReadFirst: 9,
+			ExpectErr: true,
+		},
+		"fully read within buffer": {
+			Size:      32,
+			ReadFirst: 32,
+		},
+	}
+
+	const content = "abcdefghijklmnopqrstuvwxyz"
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewRewindBuffer(strings.NewReader(content), c.Size)
+
+			first, err := ioutil.ReadAll(io.LimitReader(r, c.ReadFirst))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			err = r.Rewind()
+			if c.ExpectErr {
+				var rewindErr *RewindBufferExceededError
+				if !errors.As(err, &rewindErr) {
+					t.Fatalf("expect %T error, got %v", rewindErr, err)
+				}
+				if e, a := c.Size, rewindErr.Size; e != a {
+					t.Errorf("expect %v size, got %v", e, a)
+				}
+				if e, a := int64(len(first)), rewindErr.Offset; e != a {
+					t.Errorf("expect %v offset, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			all, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := content, string(all); e != a {
+				t.Errorf("expect %q after rewind, got %q", e, a)
+			}
+		})
+	}
+}
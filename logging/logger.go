@@ -12,6 +12,7 @@ type Classification string
 // Set of standard classifications that can be used by clients and middleware
 const (
 	Warn  Classification = "WARN"
+	Info  Classification = "INFO"
 	Debug Classification = "DEBUG"
 )
 
@@ -29,6 +30,22 @@ func (f LoggerFunc) Logf(classification Classification, format string, v ...inte
 	f(classification, format, v...)
 }
 
+// StructuredLogger is an interface for logging entries at certain
+// classifications as a message, and a list of alternating key value pairs,
+// (e.g. "elapsed", time.Second).
+type StructuredLogger interface {
+	Log(classification Classification, msg string, keyvals ...interface{})
+}
+
+// StructuredLoggerFunc is a wrapper around a function to satisfy the
+// StructuredLogger interface.
+type StructuredLoggerFunc func(classification Classification, msg string, keyvals ...interface{})
+
+// Log delegates the logging request to the wrapped function.
+func (f StructuredLoggerFunc) Log(classification Classification, msg string, keyvals ...interface{}) {
+	f(classification, msg, keyvals...)
+}
+
 // ContextLogger is an optional interface a Logger implementation may expose that provides
 // the ability to create context aware log entries.
 type ContextLogger interface {
@@ -51,7 +68,8 @@ func WithContext(ctx context.Context, logger Logger) Logger {
 	return cl.WithContext(ctx)
 }
 
-// Nop is a Logger implementation that simply does not perform any logging.
+// Nop is a Logger, and StructuredLogger implementation that simply does not
+// perform any logging.
 type Nop struct{}
 
 // Logf simply returns without performing any action
@@ -59,6 +77,11 @@ func (n Nop) Logf(Classification, string, ...interface{}) {
 	return
 }
 
+// Log simply returns without performing any action
+func (n Nop) Log(Classification, string, ...interface{}) {
+	return
+}
+
 // StandardLogger is a Logger implementation that wraps the standard library logger, and delegates logging to it's
 // Printf method.
 type StandardLogger struct {
@@ -30,6 +30,7 @@ func TestNewStandardLogger(t *testing.T) {
 
 func TestNop(t *testing.T) {
 	logging.Nop{}.Logf(logging.Debug, "foo")
+	logging.Nop{}.Log(logging.Debug, "foo", "key", "value")
 }
 
 func TestWithContext(t *testing.T) {
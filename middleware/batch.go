@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/smithy-go"
+)
+
+// BatchResult is the result of invoking the handler for a single input of a
+// batch.
+type BatchResult struct {
+	Output   interface{}
+	Metadata Metadata
+}
+
+// BatchRunner invokes a handler for each input of a batch concurrently, with
+// a bounded number of workers. The handler is typically a stack decorating a
+// transport handler, (e.g. DecorateHandler(clientHandler, stack)), and must be
+// safe for concurrent use.
+type BatchRunner struct {
+	handler     Handler
+	concurrency int
+}
+
+// NewBatchRunner returns an initialized BatchRunner that will invoke the
+// handler with at most concurrency inputs at a time. A concurrency less than
+// one is treated as one.
+func NewBatchRunner(handler Handler, concurrency int) *BatchRunner {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &BatchRunner{
+		handler:     handler,
+		concurrency: concurrency,
+	}
+}
+
+// Run invokes the handler for each of the inputs, returning the results and
+// errors in the same order as the inputs. The error of an input that
+// succeeded is nil.
+//
+// If the Context is canceled, no further inputs will be dispatched, and the
+// error of each input not dispatched will be a smithy.CanceledError. Inputs
+// already dispatched are passed the Context, and are expected to react to its
+// cancellation.
+func (r *BatchRunner) Run(ctx context.Context, inputs []interface{}) ([]BatchResult, []error) {
+	results := make([]BatchResult, len(inputs))
+	errs := make([]error, len(inputs))
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.concurrency && i < len(inputs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				out, metadata, err := r.handler.Handle(ctx, inputs[idx])
+				results[idx] = BatchResult{Output: out, Metadata: metadata}
+				errs[idx] = err
+			}
+		}()
+	}
+
+	var next int
+dispatch:
+	for ; next < len(inputs); next++ {
+		// Prefer stopping dispatch over dispatching, if the Context has
+		// already been canceled.
+		select {
+		case <-ctx.Done():
+			break dispatch
+		default:
+		}
+
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+
+	for ; next < len(inputs); next++ {
+		errs[next] = &smithy.CanceledError{Err: ctx.Err()}
+	}
+
+	wg.Wait()
+
+	return results, errs
+}
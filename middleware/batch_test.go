@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestBatchRunner(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+
+		v := input.(int)
+		if v%3 == 0 {
+			return nil, Metadata{}, fmt.Errorf("input %d failed", v)
+		}
+		return v * 10, Metadata{}, nil
+	})
+
+	inputs := make([]interface{}, 10)
+	for i := range inputs {
+		inputs[i] = i
+	}
+
+	results, errs := NewBatchRunner(handler, 3).Run(context.Background(), inputs)
+
+	if e, a := len(inputs), len(results); e != a {
+		t.Fatalf("expect %v results, got %v", e, a)
+	}
+	if e, a := len(inputs), len(errs); e != a {
+		t.Fatalf("expect %v errors, got %v", e, a)
+	}
+
+	for i := range inputs {
+		if i%3 == 0 {
+			if errs[i] == nil {
+				t.Errorf("expect %d error, got none", i)
+			} else if e, a := fmt.Sprintf("input %d failed", i), errs[i].Error(); e != a {
+				t.Errorf("expect %d error %v, got %v", i, e, a)
+			}
+			continue
+		}
+
+		if errs[i] != nil {
+			t.Errorf("expect %d no error, got %v", i, errs[i])
+		}
+		if e, a := i*10, results[i].Output; e != a {
+			t.Errorf("expect %d output %v, got %v", i, e, a)
+		}
+	}
+
+	if max := atomic.LoadInt32(&maxInFlight); max > 3 {
+		t.Errorf("expect at most 3 concurrent invocations, got %v", max)
+	}
+}
+
+func TestBatchRunner_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var invoked int32
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		atomic.AddInt32(&invoked, 1)
+		// Cancel the batch once the first input is being handled.
+		cancel()
+		return input, Metadata{}, nil
+	})
+
+	inputs := []interface{}{0, 1, 2, 3, 4}
+	_, errs := NewBatchRunner(handler, 1).Run(ctx, inputs)
+
+	if n := atomic.LoadInt32(&invoked); n == int32(len(inputs)) {
+		t.Fatalf("expect dispatch to stop on cancel, all %d inputs invoked", n)
+	}
+
+	var canceled int
+	for _, err := range errs {
+		var cancelErr *smithy.CanceledError
+		if errors.As(err, &cancelErr) {
+			canceled++
+		}
+	}
+	if e, a := len(inputs)-int(atomic.LoadInt32(&invoked)), canceled; e != a {
+		t.Errorf("expect %v canceled inputs, got %v", e, a)
+	}
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/smithy-go"
+)
+
+type requestPriorityKey struct{}
+
+// SetRequestPriority returns a context with the priority the operation will
+// acquire a ConcurrencyLimiter slot with. Operations with a higher priority
+// are granted free slots before waiting operations with a lower priority.
+// Operations without a priority have priority zero.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetRequestPriority(ctx context.Context, level int) context.Context {
+	return WithStackValue(ctx, requestPriorityKey{}, level)
+}
+
+// GetRequestPriority returns the priority of the operation, or zero if not
+// set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetRequestPriority(ctx context.Context) int {
+	v, _ := GetStackValue(ctx, requestPriorityKey{}).(int)
+	return v
+}
+
+// ConcurrencyLimiter bounds the number of operations that may be in flight at
+// the same time. When all slots are in use, waiting operations are granted
+// freed slots in priority order, and in the order they started waiting for
+// operations with the same priority.
+//
+// A ConcurrencyLimiter is safe for concurrent use, and is typically shared by
+// the stacks of all operations of a client.
+type ConcurrencyLimiter struct {
+	mu        sync.Mutex
+	available int
+	waiters   limiterWaiters
+	seq       uint64
+}
+
+// NewConcurrencyLimiter returns an initialized ConcurrencyLimiter with the
+// number of slots. A number of slots less than one is treated as one.
+func NewConcurrencyLimiter(slots int) *ConcurrencyLimiter {
+	if slots < 1 {
+		slots = 1
+	}
+	return &ConcurrencyLimiter{
+		available: slots,
+	}
+}
+
+// Acquire blocks until a slot is granted, or the Context is done. If the
+// Context is done first, a smithy.CanceledError is returned. A granted slot
+// must be released with Release.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, priority int) error {
+	l.mu.Lock()
+	if l.available > 0 && len(l.waiters) == 0 {
+		l.available--
+		l.mu.Unlock()
+		return nil
+	}
+
+	w := &limiterWaiter{
+		priority: priority,
+		seq:      l.seq,
+		ready:    make(chan struct{}),
+	}
+	l.seq++
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		granted := w.index < 0
+		if !granted {
+			heap.Remove(&l.waiters, w.index)
+		}
+		l.mu.Unlock()
+
+		// The slot was granted concurrently with the context being done, and
+		// must be passed on.
+		if granted {
+			l.Release()
+		}
+		return &smithy.CanceledError{Err: ctx.Err()}
+	}
+}
+
+// Release returns a slot to the limiter, granting it to the waiting operation
+// with the highest priority, if any.
+func (l *ConcurrencyLimiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.waiters) == 0 {
+		l.available++
+		return
+	}
+
+	w := heap.Pop(&l.waiters).(*limiterWaiter)
+	close(w.ready)
+}
+
+type limiterWaiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{}
+
+	// The index of the waiter within the queue, or -1 once granted a slot.
+	index int
+}
+
+// limiterWaiters is a priority queue of waiters, implementing heap.Interface.
+type limiterWaiters []*limiterWaiter
+
+func (q limiterWaiters) Len() int { return len(q) }
+
+func (q limiterWaiters) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q limiterWaiters) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *limiterWaiters) Push(x interface{}) {
+	w := x.(*limiterWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *limiterWaiters) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
+
+// AddConcurrencyLimitMiddleware adds the middleware acquiring a slot of the
+// limiter to the end of the stack's Finalize step, so that each attempt of
+// the operation holds a slot only while it is in flight. The priority of the
+// operation is read from the context, see SetRequestPriority.
+func AddConcurrencyLimitMiddleware(stack *Stack, limiter *ConcurrencyLimiter) error {
+	if err := stack.Finalize.Add(&concurrencyLimit{limiter: limiter}, After); err != nil {
+		return fmt.Errorf("failed to add %s finalize middleware, %w",
+			(*concurrencyLimit)(nil).ID(), err)
+	}
+	return nil
+}
+
+type concurrencyLimit struct {
+	limiter *ConcurrencyLimiter
+}
+
+// ID returns the middleware identifier.
+func (*concurrencyLimit) ID() string { return "ConcurrencyLimit" }
+
+// HandleFinalize acquires a slot of the limiter before invoking the next
+// handler, and releases it when the handler returns.
+func (m *concurrencyLimit) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	if err := m.limiter.Acquire(ctx, GetRequestPriority(ctx)); err != nil {
+		return out, metadata, err
+	}
+	defer m.limiter.Release()
+
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/google/go-cmp/cmp"
+)
+
+func waitForWaiters(t *testing.T, limiter *ConcurrencyLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		limiter.mu.Lock()
+		count := len(limiter.waiters)
+		limiter.mu.Unlock()
+		if count == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expect %v waiters, timed out", n)
+}
+
+func TestConcurrencyLimitMiddleware_Priority(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddConcurrencyLimitMiddleware(stack, limiter); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []int
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, Metadata, error,
+	) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, GetRequestPriority(ctx))
+		return nil, Metadata{}, nil
+	}), stack)
+
+	// Hold the only slot, so that the operations must wait.
+	if err := limiter.Acquire(context.Background(), 0); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	invoke := func(priority int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := SetRequestPriority(context.Background(), priority)
+			if _, _, err := handler.Handle(ctx, struct{}{}); err != nil {
+				t.Errorf("expect no error, got %v", err)
+			}
+		}()
+	}
+
+	invoke(1)
+	waitForWaiters(t, limiter, 1)
+	invoke(10)
+	waitForWaiters(t, limiter, 2)
+
+	limiter.Release()
+	wg.Wait()
+
+	if diff := cmp.Diff([]int{10, 1}, order); len(diff) != 0 {
+		t.Errorf("expect acquire order to match\n%s", diff)
+	}
+}
+
+func TestConcurrencyLimiter_Canceled(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	if err := limiter.Acquire(context.Background(), 0); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() { errCh <- limiter.Acquire(ctx, 5) }()
+	waitForWaiters(t, limiter, 1)
+	cancel()
+
+	err := <-errCh
+	var cErr *smithy.CanceledError
+	if !errors.As(err, &cErr) {
+		t.Fatalf("expect %T error, got %v", cErr, err)
+	}
+	waitForWaiters(t, limiter, 0)
+
+	// The held slot is returned to the limiter, not to the canceled waiter.
+	limiter.Release()
+	if err := limiter.Acquire(context.Background(), 0); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+}
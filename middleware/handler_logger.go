@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+// HandlerLoggerOptions provides the options for the HandlerLogger
+// middleware.
+type HandlerLoggerOptions struct {
+	// The identifier of the middleware, and the name of the handler logged
+	// with each entry. Set to log more than one handler of the same step.
+	// Defaults to "HandlerLogger".
+	ID string
+
+	// The classification entries are logged at. Defaults to logging.Debug.
+	Classification logging.Classification
+}
+
+// HandlerLogger is a middleware that logs an entry when the next handler is
+// invoked, and an entry with the elapsed time, and error if any, when the
+// next handler returns. The output, and error of the next handler are
+// returned as is. The logged error is redacted, see RedactError.
+//
+// HandlerLogger implements Middleware, and the middleware interface of each
+// stack step, so that it can be added to any step, (e.g. the front of a step
+// to log the time taken by the step, and all steps after it).
+type HandlerLogger struct {
+	logger  logging.StructuredLogger
+	options HandlerLoggerOptions
+}
+
+// NewHandlerLogger returns an initialized HandlerLogger middleware logging to
+// the logger. If the logger is nil, entries are not logged.
+func NewHandlerLogger(logger logging.StructuredLogger, optFns ...func(*HandlerLoggerOptions)) *HandlerLogger {
+	options := HandlerLoggerOptions{
+		ID:             "HandlerLogger",
+		Classification: logging.Debug,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	if logger == nil {
+		logger = logging.Nop{}
+	}
+
+	return &HandlerLogger{
+		logger:  logger,
+		options: options,
+	}
+}
+
+// ID returns the middleware identifier.
+func (m *HandlerLogger) ID() string { return m.options.ID }
+
+// HandleMiddleware logs the invocation of the next handler.
+func (m *HandlerLogger) HandleMiddleware(ctx context.Context, input interface{}, next Handler) (
+	output interface{}, metadata Metadata, err error,
+) {
+	defer m.logExit(ctx, m.logEnter(), &err)
+	return next.Handle(ctx, input)
+}
+
+// HandleInitialize logs the invocation of the next handler.
+func (m *HandlerLogger) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	defer m.logExit(ctx, m.logEnter(), &err)
+	return next.HandleInitialize(ctx, in)
+}
+
+// HandleSerialize logs the invocation of the next handler.
+func (m *HandlerLogger) HandleSerialize(ctx context.Context, in SerializeInput, next SerializeHandler) (
+	out SerializeOutput, metadata Metadata, err error,
+) {
+	defer m.logExit(ctx, m.logEnter(), &err)
+	return next.HandleSerialize(ctx, in)
+}
+
+// HandleBuild logs the invocation of the next handler.
+func (m *HandlerLogger) HandleBuild(ctx context.Context, in BuildInput, next BuildHandler) (
+	out BuildOutput, metadata Metadata, err error,
+) {
+	defer m.logExit(ctx, m.logEnter(), &err)
+	return next.HandleBuild(ctx, in)
+}
+
+// HandleFinalize logs the invocation of the next handler.
+func (m *HandlerLogger) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	defer m.logExit(ctx, m.logEnter(), &err)
+	return next.HandleFinalize(ctx, in)
+}
+
+// HandleDeserialize logs the invocation of the next handler.
+func (m *HandlerLogger) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	defer m.logExit(ctx, m.logEnter(), &err)
+	return next.HandleDeserialize(ctx, in)
+}
+
+func (m *HandlerLogger) logEnter() time.Time {
+	m.logger.Log(m.options.Classification, "handler enter",
+		"handler", m.options.ID)
+	return time.Now()
+}
+
+func (m *HandlerLogger) logExit(ctx context.Context, start time.Time, err *error) {
+	keyvals := []interface{}{
+		"handler", m.options.ID,
+		"elapsed", time.Since(start),
+	}
+	if *err != nil {
+		keyvals = append(keyvals, "error", RedactError(ctx, *err))
+	}
+	m.logger.Log(m.options.Classification, "handler exit", keyvals...)
+}
+
+var (
+	_ Middleware            = (*HandlerLogger)(nil)
+	_ InitializeMiddleware  = (*HandlerLogger)(nil)
+	_ SerializeMiddleware   = (*HandlerLogger)(nil)
+	_ BuildMiddleware       = (*HandlerLogger)(nil)
+	_ FinalizeMiddleware    = (*HandlerLogger)(nil)
+	_ DeserializeMiddleware = (*HandlerLogger)(nil)
+)
@@ -0,0 +1,32 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func ExampleNewHandlerLogger() {
+	// Capturing logger, printing each entry without the elapsed time value.
+	logger := logging.StructuredLoggerFunc(func(classification logging.Classification, msg string, keyvals ...interface{}) {
+		fmt.Println(classification, msg, keyvals[:2])
+	})
+
+	stack := middleware.NewStack("stack", func() interface{} { return struct{}{} })
+	stack.Build.Add(middleware.NewHandlerLogger(logger), middleware.Before)
+
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(
+		func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+			return nil, middleware.Metadata{}, nil
+		}), stack)
+
+	_, _, err := handler.Handle(context.Background(), struct{}{})
+	fmt.Println("error:", err)
+
+	// Output:
+	// DEBUG handler enter [handler HandlerLogger]
+	// DEBUG handler exit [handler HandlerLogger]
+	// error: <nil>
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+type logEntry struct {
+	Classification logging.Classification
+	Msg            string
+	Keyvals        []interface{}
+}
+
+func TestHandlerLogger(t *testing.T) {
+	handlerErr := errors.New("handler error")
+
+	cases := map[string]struct {
+		Err     error
+		Options func(*HandlerLoggerOptions)
+		Expect  logging.Classification
+		ID      string
+	}{
+		"success": {
+			Expect: logging.Debug,
+			ID:     "HandlerLogger",
+		},
+		"error": {
+			Err:    handlerErr,
+			Expect: logging.Debug,
+			ID:     "HandlerLogger",
+		},
+		"options": {
+			Options: func(o *HandlerLoggerOptions) {
+				o.ID = "SerializeTiming"
+				o.Classification = logging.Info
+			},
+			Expect: logging.Info,
+			ID:     "SerializeTiming",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var entries []logEntry
+			logger := logging.StructuredLoggerFunc(func(classification logging.Classification, msg string, keyvals ...interface{}) {
+				entries = append(entries, logEntry{classification, msg, keyvals})
+			})
+
+			var optFns []func(*HandlerLoggerOptions)
+			if c.Options != nil {
+				optFns = append(optFns, c.Options)
+			}
+
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			m := NewHandlerLogger(logger, optFns...)
+			if err := s.Serialize.Add(m, Before); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ID, m.ID(); e != a {
+				t.Errorf("expect %q ID, got %q", e, a)
+			}
+
+			handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+				time.Sleep(time.Millisecond)
+				return "output", Metadata{}, c.Err
+			})
+
+			_, _, err := DecorateHandler(handler, s).Handle(context.Background(), struct{}{})
+			if c.Err != nil {
+				if err != c.Err {
+					t.Errorf("expect handler error returned as is, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := 2, len(entries); e != a {
+				t.Fatalf("expect %v log entries, got %v", e, a)
+			}
+
+			enter, exit := entries[0], entries[1]
+			if e, a := "handler enter", enter.Msg; e != a {
+				t.Errorf("expect %q message, got %q", e, a)
+			}
+			if e, a := "handler exit", exit.Msg; e != a {
+				t.Errorf("expect %q message, got %q", e, a)
+			}
+			for _, entry := range entries {
+				if e, a := c.Expect, entry.Classification; e != a {
+					t.Errorf("expect %v classification, got %v", e, a)
+				}
+				if e, a := c.ID, entry.Keyvals[1]; e != a {
+					t.Errorf("expect %v handler, got %v", e, a)
+				}
+			}
+
+			elapsed, ok := exit.Keyvals[3].(time.Duration)
+			if !ok || elapsed < time.Millisecond {
+				t.Errorf("expect elapsed of at least 1ms, got %v", exit.Keyvals[3])
+			}
+
+			if c.Err != nil {
+				if e, a := 6, len(exit.Keyvals); e != a {
+					t.Fatalf("expect %v exit keyvals, got %v", e, a)
+				}
+				if e, a := c.Err, exit.Keyvals[5]; e != a {
+					t.Errorf("expect %v logged error, got %v", e, a)
+				}
+			} else if e, a := 4, len(exit.Keyvals); e != a {
+				t.Errorf("expect %v exit keyvals, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestHandlerLogger_NilLogger(t *testing.T) {
+	handler := DecorateHandler(
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			return "output", Metadata{}, nil
+		}),
+		NewHandlerLogger(nil),
+	)
+
+	out, _, err := handler.Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "output", out; e != a {
+		t.Errorf("expect %v output, got %v", e, a)
+	}
+}
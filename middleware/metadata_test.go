@@ -1,6 +1,11 @@
 package middleware
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 func TestMetadataClone(t *testing.T) {
 	original := map[interface{}]interface{}{
@@ -28,3 +33,82 @@ func TestMetadataClone(t *testing.T) {
 		t.Errorf("expect cloned metadata to not leak in to original")
 	}
 }
+
+func TestMetadataPropagation(t *testing.T) {
+	type requestIDKey struct{}
+	type stepsKey struct{}
+
+	appendStep := func(md *Metadata, step string) {
+		steps, _ := md.Get(stepsKey{}).([]string)
+		md.Set(stepsKey{}, append(steps, step))
+	}
+
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	s.Deserialize.Add(DeserializeMiddlewareFunc("setRequestID",
+		func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+			out DeserializeOutput, metadata Metadata, err error,
+		) {
+			out, metadata, err = next.HandleDeserialize(ctx, in)
+			metadata.Set(requestIDKey{}, "abc123")
+			appendStep(&metadata, "deserialize")
+			return out, metadata, err
+		}), After)
+	s.Finalize.Add(FinalizeMiddlewareFunc("finalize",
+		func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+			out FinalizeOutput, metadata Metadata, err error,
+		) {
+			out, metadata, err = next.HandleFinalize(ctx, in)
+			appendStep(&metadata, "finalize")
+			return out, metadata, err
+		}), After)
+	s.Build.Add(BuildMiddlewareFunc("build",
+		func(ctx context.Context, in BuildInput, next BuildHandler) (
+			out BuildOutput, metadata Metadata, err error,
+		) {
+			out, metadata, err = next.HandleBuild(ctx, in)
+			appendStep(&metadata, "build")
+			return out, metadata, err
+		}), After)
+	s.Serialize.Add(SerializeMiddlewareFunc("serialize",
+		func(ctx context.Context, in SerializeInput, next SerializeHandler) (
+			out SerializeOutput, metadata Metadata, err error,
+		) {
+			out, metadata, err = next.HandleSerialize(ctx, in)
+			appendStep(&metadata, "serialize")
+			return out, metadata, err
+		}), After)
+
+	var initializeRequestID interface{}
+	s.Initialize.Add(InitializeMiddlewareFunc("initialize",
+		func(ctx context.Context, in InitializeInput, next InitializeHandler) (
+			out InitializeOutput, metadata Metadata, err error,
+		) {
+			out, metadata, err = next.HandleInitialize(ctx, in)
+			initializeRequestID = metadata.Get(requestIDKey{})
+			appendStep(&metadata, "initialize")
+			return out, metadata, err
+		}), After)
+
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		var metadata Metadata
+		appendStep(&metadata, "handler")
+		return nil, metadata, nil
+	})
+
+	_, metadata, err := DecorateHandler(handler, s).Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := "abc123", initializeRequestID; e != a {
+		t.Errorf("expect %v request ID in initialize step, got %v", e, a)
+	}
+	if e, a := "abc123", metadata.Get(requestIDKey{}); e != a {
+		t.Errorf("expect %v request ID returned to caller, got %v", e, a)
+	}
+
+	expectSteps := []string{"handler", "deserialize", "finalize", "build", "serialize", "initialize"}
+	if diff := cmp.Diff(expectSteps, metadata.Get(stepsKey{})); len(diff) != 0 {
+		t.Errorf("expect metadata enriched by each step\n%s", diff)
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestMockResponseMiddleware(t *testing.T) {
+	type mockRaw struct {
+		Value string
+	}
+	type mockOutput struct {
+		Value string
+	}
+
+	cases := map[string]struct {
+		WithMock          func(context.Context) context.Context
+		Expect            *mockOutput
+		ExpectTransport   bool
+		ExpectDeserialize bool
+	}{
+		"no mock": {
+			WithMock:          func(ctx context.Context) context.Context { return ctx },
+			Expect:            &mockOutput{Value: "decoded transport"},
+			ExpectTransport:   true,
+			ExpectDeserialize: true,
+		},
+		"mock response": {
+			WithMock: func(ctx context.Context) context.Context {
+				return SetMockResponse(ctx, &mockRaw{Value: "mock"})
+			},
+			Expect:            &mockOutput{Value: "decoded mock"},
+			ExpectDeserialize: true,
+		},
+		"mock result": {
+			WithMock: func(ctx context.Context) context.Context {
+				return SetMockResult(ctx, &mockOutput{Value: "mock result"})
+			},
+			Expect: &mockOutput{Value: "mock result"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+			var deserialized bool
+			stack.Deserialize.Add(DeserializeMiddlewareFunc("OperationDeserializer",
+				func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+					out DeserializeOutput, metadata Metadata, err error,
+				) {
+					out, metadata, err = next.HandleDeserialize(ctx, in)
+					if err != nil {
+						return out, metadata, err
+					}
+					deserialized = true
+					raw, ok := out.RawResponse.(*mockRaw)
+					if !ok {
+						return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+					}
+					out.Result = &mockOutput{Value: "decoded " + raw.Value}
+					return out, metadata, nil
+				}), After)
+
+			if err := AddMockResponseMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var sent bool
+			handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+				sent = true
+				return &mockRaw{Value: "transport"}, Metadata{}, nil
+			})
+
+			result, _, err := DecorateHandler(handler, stack).Handle(c.WithMock(context.Background()), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := *c.Expect, *result.(*mockOutput); e != a {
+				t.Errorf("expect %v result, got %v", e, a)
+			}
+			if e, a := c.ExpectTransport, sent; e != a {
+				t.Errorf("expect %v transport invoked, got %v", e, a)
+			}
+			if e, a := c.ExpectDeserialize, deserialized; e != a {
+				t.Errorf("expect %v deserialize invoked, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/smithy-go"
+)
+
+// AddOperationErrorMiddleware adds the middleware wrapping errors returned by
+// the stack in a smithy.OperationError with the service ID, and operation
+// name, to the front of the Initialize step, so that errors from all other
+// middleware are wrapped. Errors already wrapped in an OperationError are
+// returned as is.
+func AddOperationErrorMiddleware(stack *Stack, serviceID, operationName string) error {
+	return stack.Initialize.Add(&operationError{
+		serviceID:     serviceID,
+		operationName: operationName,
+	}, Before)
+}
+
+type operationError struct {
+	serviceID     string
+	operationName string
+}
+
+// ID returns the middleware identifier.
+func (*operationError) ID() string { return "OperationError" }
+
+// HandleInitialize wraps any error returned by the next handler in a
+// smithy.OperationError.
+func (m *operationError) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	if err == nil {
+		return out, metadata, err
+	}
+
+	var opErr *smithy.OperationError
+	if errors.As(err, &opErr) {
+		return out, metadata, err
+	}
+
+	return out, metadata, &smithy.OperationError{
+		ServiceID:     m.serviceID,
+		OperationName: m.operationName,
+		Err:           err,
+	}
+}
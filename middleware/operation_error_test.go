@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestOperationErrorMiddleware(t *testing.T) {
+	baseErr := fmt.Errorf("serialization failed")
+
+	cases := map[string]struct {
+		Err          error
+		ExpectNested bool
+	}{
+		"no error": {},
+		"error": {
+			Err: baseErr,
+		},
+		"already wrapped": {
+			Err: &smithy.OperationError{
+				ServiceID:     "Inner",
+				OperationName: "InnerOperation",
+				Err:           baseErr,
+			},
+			ExpectNested: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+			if err := AddOperationErrorMiddleware(stack, "Service", "Operation"); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			stack.Serialize.Add(SerializeMiddlewareFunc("fail",
+				func(ctx context.Context, in SerializeInput, next SerializeHandler) (
+					SerializeOutput, Metadata, error,
+				) {
+					if c.Err != nil {
+						return SerializeOutput{}, Metadata{}, c.Err
+					}
+					return next.HandleSerialize(ctx, in)
+				}), After)
+
+			_, _, err := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, Metadata, error,
+			) {
+				return nil, Metadata{}, nil
+			}), stack).Handle(context.Background(), struct{}{})
+			if c.Err == nil {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+
+			var opErr *smithy.OperationError
+			if !errors.As(err, &opErr) {
+				t.Fatalf("expect %T error, got %v", opErr, err)
+			}
+			if c.ExpectNested {
+				if e, a := c.Err, err; e != a {
+					t.Errorf("expect error not wrapped again, got %v", a)
+				}
+				return
+			}
+			if e, a := "Service", opErr.Service(); e != a {
+				t.Errorf("expect %v service, got %v", e, a)
+			}
+			if e, a := "Operation", opErr.Operation(); e != a {
+				t.Errorf("expect %v operation, got %v", e, a)
+			}
+			if !errors.Is(err, baseErr) {
+				t.Errorf("expect original error accessible, got %v", err)
+			}
+		})
+	}
+}
@@ -66,12 +66,36 @@ func (g *orderedIDs) Insert(m ider, relativeTo string, pos RelativePosition) err
 	return nil
 }
 
+// InsertAt injects the item at the index of the group's order. A negative
+// index counts from the end of the order, (e.g. -1 places the item last).
+// Returns an error if the index is out of range, or the item already exists.
+func (g *orderedIDs) InsertAt(m ider, index int) error {
+	id := m.ID()
+	if len(id) == 0 {
+		return fmt.Errorf("insert ID must not be empty")
+	}
+
+	if err := g.order.InsertAt(index, id); err != nil {
+		return err
+	}
+
+	g.items[id] = m
+	return nil
+}
+
 // Get returns the ider identified by id. If ider is not present, returns false.
 func (g *orderedIDs) Get(id string) (ider, bool) {
 	v, ok := g.items[id]
 	return v, ok
 }
 
+// Has returns if the item identified by id is present. Uses the same lookup
+// as Add, and Insert, to detect duplicate items.
+func (g *orderedIDs) Has(id string) bool {
+	_, ok := g.order.has(id)
+	return ok
+}
+
 // Swap removes the item by id, replacing it with the new item. Returns an error
 // if the original item doesn't exist.
 func (g *orderedIDs) Swap(id string, m ider) (ider, error) {
@@ -125,6 +149,23 @@ func (g *orderedIDs) Clear() {
 	g.items = map[string]ider{}
 }
 
+// clone returns a copy of the group with its own ordering and items. The items
+// themselves are not copied.
+func (g *orderedIDs) clone() *orderedIDs {
+	items := make(map[string]ider, len(g.items))
+	for id, m := range g.items {
+		items[id] = m
+	}
+
+	order := make([]string, len(g.order.order), cap(g.order.order))
+	copy(order, g.order.order)
+
+	return &orderedIDs{
+		order: &relativeOrder{order: order},
+		items: items,
+	}
+}
+
 // GetOrder returns the item in the order it should be invoked in.
 func (g *orderedIDs) GetOrder() []interface{} {
 	order := g.order.List()
@@ -194,6 +235,29 @@ func (s *relativeOrder) Insert(relativeTo string, pos RelativePosition, ids ...s
 	return s.insert(i, pos, ids...)
 }
 
+// InsertAt injects an item at the index. A negative index counts from the end,
+// with -1 placing the item last. Returns an error if the index is out of
+// range.
+func (s *relativeOrder) InsertAt(index int, id string) error {
+	if _, ok := s.has(id); ok {
+		return fmt.Errorf("already exists, %v", id)
+	}
+
+	n := len(s.order)
+	i := index
+	if i < 0 {
+		i = n + 1 + i
+	}
+	if i < 0 || i > n {
+		return fmt.Errorf("index %d out of range, must be within [%d, %d]", index, -(n + 1), n)
+	}
+
+	s.order = append(s.order, "")
+	copy(s.order[i+1:], s.order[i:])
+	s.order[i] = id
+	return nil
+}
+
 // Swap will replace the item id with the to item. Returns an
 // error if the original item id does not exist. Allows swapping out an
 // item for another item with the same id.
@@ -64,6 +64,95 @@ func TestOrderedIDsInsert(t *testing.T) {
 	}
 }
 
+func TestOrderedIDsInsertAt(t *testing.T) {
+	cases := map[string]struct {
+		Index     int
+		Expect    []string
+		ExpectErr string
+	}{
+		"front": {
+			Index:  0,
+			Expect: []string{"new", "first", "second", "third"},
+		},
+		"middle": {
+			Index:  2,
+			Expect: []string{"first", "second", "new", "third"},
+		},
+		"end": {
+			Index:  3,
+			Expect: []string{"first", "second", "third", "new"},
+		},
+		"negative end": {
+			Index:  -1,
+			Expect: []string{"first", "second", "third", "new"},
+		},
+		"negative middle": {
+			Index:  -2,
+			Expect: []string{"first", "second", "new", "third"},
+		},
+		"negative front": {
+			Index:  -4,
+			Expect: []string{"new", "first", "second", "third"},
+		},
+		"out of range": {
+			Index:     4,
+			ExpectErr: "index 4 out of range, must be within [-4, 3]",
+		},
+		"negative out of range": {
+			Index:     -5,
+			ExpectErr: "index -5 out of range, must be within [-4, 3]",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := newOrderedIDs()
+			noError(t, o.Add(&mockIder{"first"}, After))
+			noError(t, o.Add(&mockIder{"second"}, After))
+			noError(t, o.Add(&mockIder{"third"}, After))
+
+			err := o.InsertAt(&mockIder{"new"}, c.Index)
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); e != a {
+					t.Errorf("expect %q error, got %q", e, a)
+				}
+				if e, a := []string{"first", "second", "third"}, o.List(); !reflect.DeepEqual(e, a) {
+					t.Errorf("expect %v order unchanged, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.Expect, o.List(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v order, got %v", e, a)
+			}
+			if _, ok := o.Get("new"); !ok {
+				t.Errorf("expect inserted item to be found")
+			}
+		})
+	}
+}
+
+func TestOrderedIDsInsertAt_Invalid(t *testing.T) {
+	o := newOrderedIDs()
+	noError(t, o.InsertAt(&mockIder{"first"}, 0))
+
+	if err := o.InsertAt(&mockIder{"first"}, 0); err == nil {
+		t.Errorf("expect error inserting existing ID, got none")
+	}
+	if err := o.InsertAt(&mockIder{""}, 0); err == nil {
+		t.Errorf("expect error inserting empty ID, got none")
+	}
+	if e, a := []string{"first"}, o.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v order, got %v", e, a)
+	}
+}
+
 func TestOrderedIDsGet(t *testing.T) {
 	o := newOrderedIDs()
 
@@ -84,6 +173,37 @@ func TestOrderedIDsGet(t *testing.T) {
 	}
 }
 
+func TestOrderedIDsHas(t *testing.T) {
+	o := newOrderedIDs()
+
+	if o.Has("first") {
+		t.Errorf("expect id not to be found in empty group")
+	}
+
+	noError(t, o.Add(&mockIder{"first"}, After))
+	noError(t, o.Insert(&mockIder{"second"}, "first", Before))
+
+	for _, id := range []string{"first", "second"} {
+		if !o.Has(id) {
+			t.Errorf("expect %v id to be found, was not", id)
+		}
+		if err := o.Add(&mockIder{id}, After); err == nil {
+			t.Errorf("expect error adding %v id that is present, got none", id)
+		}
+	}
+
+	if o.Has("not-found") {
+		t.Errorf("expect id not to be found, but was")
+	}
+
+	_, err := o.Remove("first")
+	noError(t, err)
+	if o.Has("first") {
+		t.Errorf("expect removed id not to be found, but was")
+	}
+	noError(t, o.Add(&mockIder{"first"}, After))
+}
+
 func TestOrderedIDsSwap(t *testing.T) {
 	o := newOrderedIDs()
 
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error returned by the RecoverPanic middleware when a
+// panic is recovered from the next handler.
+type PanicError struct {
+	// The value the handler panicked with.
+	Value interface{}
+
+	// The stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered from panic, %v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the value the handler panicked with if it is an error, or
+// nil.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RecoverPanic is a middleware that recovers from panics of the next
+// handler, returning the recovered value as a PanicError instead. RecoverPanic
+// implements Middleware, and the middleware interface of each stack step, so
+// that it can be added to the front of any step, (e.g.
+// stack.Serialize.Add(middleware.NewRecoverPanic(), middleware.Before)).
+//
+// Only panics of the same goroutine the handler is invoked in are recovered.
+type RecoverPanic struct{}
+
+// NewRecoverPanic returns an initialized RecoverPanic middleware.
+func NewRecoverPanic() *RecoverPanic {
+	return &RecoverPanic{}
+}
+
+// ID returns the middleware identifier.
+func (*RecoverPanic) ID() string { return "RecoverPanic" }
+
+// HandleMiddleware invokes the next handler, recovering from any panic.
+func (*RecoverPanic) HandleMiddleware(ctx context.Context, input interface{}, next Handler) (
+	output interface{}, metadata Metadata, err error,
+) {
+	defer recoverPanic(&err)
+	return next.Handle(ctx, input)
+}
+
+// HandleInitialize invokes the next handler, recovering from any panic.
+func (*RecoverPanic) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	defer recoverPanic(&err)
+	return next.HandleInitialize(ctx, in)
+}
+
+// HandleSerialize invokes the next handler, recovering from any panic.
+func (*RecoverPanic) HandleSerialize(ctx context.Context, in SerializeInput, next SerializeHandler) (
+	out SerializeOutput, metadata Metadata, err error,
+) {
+	defer recoverPanic(&err)
+	return next.HandleSerialize(ctx, in)
+}
+
+// HandleBuild invokes the next handler, recovering from any panic.
+func (*RecoverPanic) HandleBuild(ctx context.Context, in BuildInput, next BuildHandler) (
+	out BuildOutput, metadata Metadata, err error,
+) {
+	defer recoverPanic(&err)
+	return next.HandleBuild(ctx, in)
+}
+
+// HandleFinalize invokes the next handler, recovering from any panic.
+func (*RecoverPanic) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	defer recoverPanic(&err)
+	return next.HandleFinalize(ctx, in)
+}
+
+// HandleDeserialize invokes the next handler, recovering from any panic.
+func (*RecoverPanic) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	defer recoverPanic(&err)
+	return next.HandleDeserialize(ctx, in)
+}
+
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{
+			Value: r,
+			Stack: debug.Stack(),
+		}
+	}
+}
+
+var (
+	_ Middleware            = (*RecoverPanic)(nil)
+	_ InitializeMiddleware  = (*RecoverPanic)(nil)
+	_ SerializeMiddleware   = (*RecoverPanic)(nil)
+	_ BuildMiddleware       = (*RecoverPanic)(nil)
+	_ FinalizeMiddleware    = (*RecoverPanic)(nil)
+	_ DeserializeMiddleware = (*RecoverPanic)(nil)
+)
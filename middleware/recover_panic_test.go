@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	panicErr := fmt.Errorf("panic error")
+
+	cases := map[string]struct {
+		Add         func(*Stack, *RecoverPanic) error
+		PanicValue  interface{}
+		ExpectValue string
+		ExpectWraps error
+	}{
+		"serialize step": {
+			Add: func(s *Stack, m *RecoverPanic) error {
+				if err := s.Serialize.Add(m, Before); err != nil {
+					return err
+				}
+				return s.Serialize.Add(SerializeMiddlewareFunc("panic",
+					func(context.Context, SerializeInput, SerializeHandler) (SerializeOutput, Metadata, error) {
+						panic("serialize failed")
+					}), After)
+			},
+			ExpectValue: "serialize failed",
+		},
+		"initialize step": {
+			Add: func(s *Stack, m *RecoverPanic) error {
+				return s.Initialize.Add(m, Before)
+			},
+			PanicValue:  panicErr,
+			ExpectValue: "panic error",
+			ExpectWraps: panicErr,
+		},
+		"build step": {
+			Add: func(s *Stack, m *RecoverPanic) error {
+				return s.Build.Add(m, Before)
+			},
+			PanicValue:  "handler failed",
+			ExpectValue: "handler failed",
+		},
+		"finalize step": {
+			Add: func(s *Stack, m *RecoverPanic) error {
+				return s.Finalize.Add(m, Before)
+			},
+			PanicValue:  42,
+			ExpectValue: "42",
+		},
+		"deserialize step": {
+			Add: func(s *Stack, m *RecoverPanic) error {
+				return s.Deserialize.Add(m, Before)
+			},
+			PanicValue:  "handler failed",
+			ExpectValue: "handler failed",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			if err := c.Add(s, NewRecoverPanic()); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+				if c.PanicValue != nil {
+					panic(c.PanicValue)
+				}
+				return nil, Metadata{}, nil
+			})
+
+			_, _, err := DecorateHandler(handler, s).Handle(context.Background(), struct{}{})
+			if err == nil {
+				t.Fatalf("expect error, got none")
+			}
+
+			var pErr *PanicError
+			if !errors.As(err, &pErr) {
+				t.Fatalf("expect %T error, got %v", pErr, err)
+			}
+			if e, a := c.ExpectValue, fmt.Sprint(pErr.Value); e != a {
+				t.Errorf("expect %q panic value, got %q", e, a)
+			}
+			if e, a := "recover_panic_test.go", string(pErr.Stack); !strings.Contains(a, e) {
+				t.Errorf("expect stack trace to contain %q, got %q", e, a)
+			}
+			if c.ExpectWraps != nil && !errors.Is(err, c.ExpectWraps) {
+				t.Errorf("expect error to wrap %v, got %v", c.ExpectWraps, err)
+			}
+		})
+	}
+}
+
+func TestRecoverPanic_Middleware(t *testing.T) {
+	handler := DecorateHandler(
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			panic("handler failed")
+		}),
+		NewRecoverPanic(),
+	)
+
+	_, _, err := handler.Handle(context.Background(), struct{}{})
+	var pErr *PanicError
+	if !errors.As(err, &pErr) {
+		t.Fatalf("expect %T error, got %v", pErr, err)
+	}
+
+	handler = DecorateHandler(
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			return "output", Metadata{}, nil
+		}),
+		NewRecoverPanic(),
+	)
+
+	out, _, err := handler.Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "output", out; e != a {
+		t.Errorf("expect %v output, got %v", e, a)
+	}
+}
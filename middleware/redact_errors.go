@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"strings"
+)
+
+// redactedValue is the value sensitive values are replaced with.
+const redactedValue = "*****"
+
+// SensitiveFieldsProvider provides the interface for operation input types to
+// mark fields as sensitive. Field paths are the dot separated names of the
+// struct fields, (e.g. "Credentials.SecretKey"). Pointers and slices are
+// traversed. Only string values are redacted.
+type SensitiveFieldsProvider interface {
+	SensitiveFields() []string
+}
+
+// AddRedactSensitiveErrorsMiddleware adds the middleware to redact the values
+// of sensitive input fields from errors returned by the stack. The middleware
+// is added to the front of the Initialize step, so that errors from all other
+// middleware are redacted.
+//
+// Only the error returned by the stack is redacted. Errors logged by
+// middleware within the stack are redacted only if the middleware redacts
+// them with RedactError, (e.g. HandlerLogger, and the retry middleware).
+func AddRedactSensitiveErrorsMiddleware(stack *Stack) error {
+	return stack.Initialize.Add(&redactSensitiveErrors{}, Before)
+}
+
+type redactSensitiveErrors struct{}
+
+// ID returns the middleware identifier.
+func (*redactSensitiveErrors) ID() string { return "RedactSensitiveErrors" }
+
+// HandleInitialize wraps any error returned by the next handler in a
+// RedactedError if the input has sensitive field values.
+func (*redactSensitiveErrors) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	if provider, ok := in.Parameters.(SensitiveFieldsProvider); ok {
+		values := sensitiveFieldValues(in.Parameters, provider.SensitiveFields())
+		if len(values) != 0 {
+			ctx = WithStackValue(ctx, sensitiveValuesKey{}, values)
+		}
+	}
+
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	return out, metadata, RedactError(ctx, err)
+}
+
+type sensitiveValuesKey struct{}
+
+// RedactError returns the error wrapped in a RedactedError if the operation's
+// input has sensitive field values, see AddRedactSensitiveErrorsMiddleware.
+// Otherwise the error is returned as is. Middleware should redact errors with
+// RedactError before logging them.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func RedactError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*RedactedError); ok {
+		return err
+	}
+
+	values, _ := GetStackValue(ctx, sensitiveValuesKey{}).([]string)
+	if len(values) == 0 {
+		return err
+	}
+	return &RedactedError{Err: err, values: values}
+}
+
+// RedactedError wraps an error whose message may contain the values of
+// sensitive input fields. The message of the error will have the sensitive
+// values redacted.
+//
+// Only the message of the RedactedError is redacted. The wrapped error is not
+// modified, and is returned by Unwrap unredacted, so that errors.Is, and
+// errors.As match the underlying errors. The messages of errors retrieved
+// from the wrapped error, (e.g. with errors.As), may contain the sensitive
+// values, and must not be logged.
+type RedactedError struct {
+	Err    error
+	values []string
+}
+
+// Unwrap returns the underlying, unredacted, error.
+func (e *RedactedError) Unwrap() error { return e.Err }
+
+func (e *RedactedError) Error() string {
+	msg := e.Err.Error()
+	for _, v := range e.values {
+		msg = strings.ReplaceAll(msg, v, redactedValue)
+	}
+	return msg
+}
+
+// sensitiveFieldValues returns the non-empty string values of the field paths
+// within v.
+func sensitiveFieldValues(v interface{}, paths []string) []string {
+	var values []string
+	for _, path := range paths {
+		collectFieldValues(reflect.ValueOf(v), strings.Split(path, "."), &values)
+	}
+	return values
+}
+
+func collectFieldValues(v reflect.Value, path []string, values *[]string) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectFieldValues(v.Index(i), path, values)
+		}
+		return
+	case reflect.String:
+		if len(path) == 0 && v.Len() != 0 {
+			*values = append(*values, v.String())
+		}
+		return
+	case reflect.Struct:
+		if len(path) == 0 {
+			return
+		}
+		f := v.FieldByName(path[0])
+		if !f.IsValid() {
+			return
+		}
+		collectFieldValues(f, path[1:], values)
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+)
+
+type mockSensitiveInput struct {
+	Name        string
+	Credentials *mockCredentials
+}
+
+type mockCredentials struct {
+	AccessKey string
+	SecretKey string
+}
+
+func (mockSensitiveInput) SensitiveFields() []string {
+	return []string{"Credentials.SecretKey"}
+}
+
+func TestRedactSensitiveErrors(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddRedactSensitiveErrorsMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	input := mockSensitiveInput{
+		Name: "bucket",
+		Credentials: &mockCredentials{
+			AccessKey: "AKID",
+			SecretKey: "SECRET",
+		},
+	}
+
+	origErr := fmt.Errorf("invalid input %s: %s/%s", input.Name,
+		input.Credentials.AccessKey, input.Credentials.SecretKey)
+
+	_, _, err := stack.HandleMiddleware(context.Background(), input,
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			return nil, Metadata{}, origErr
+		}))
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+
+	err = &smithy.OperationError{ServiceID: "Service", OperationName: "Operation", Err: err}
+
+	msg := err.Error()
+	if strings.Contains(msg, "SECRET") {
+		t.Errorf("expect sensitive value to be redacted, got %v", msg)
+	}
+	if e, a := "invalid input bucket: AKID/*****", msg; !strings.Contains(a, e) {
+		t.Errorf("expect %v in error, got %v", e, a)
+	}
+
+	if !errors.Is(err, origErr) {
+		t.Errorf("expect original error to be unwrapped")
+	}
+}
+
+func TestRedactSensitiveErrors_Logged(t *testing.T) {
+	var logged []string
+	logger := logging.StructuredLoggerFunc(func(classification logging.Classification, msg string, keyvals ...interface{}) {
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			if keyvals[i] == "error" {
+				logged = append(logged, fmt.Sprint(keyvals[i+1]))
+			}
+		}
+	})
+
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddRedactSensitiveErrorsMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := stack.Finalize.Add(NewHandlerLogger(logger), After); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	input := mockSensitiveInput{
+		Credentials: &mockCredentials{SecretKey: "SECRET"},
+	}
+	_, _, err := stack.HandleMiddleware(context.Background(), input,
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			return nil, Metadata{}, fmt.Errorf("invalid secret SECRET")
+		}))
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+
+	if e, a := 1, len(logged); e != a {
+		t.Fatalf("expect %v logged errors, got %v", e, a)
+	}
+	if e, a := "invalid secret *****", logged[0]; e != a {
+		t.Errorf("expect %q logged error, got %q", e, a)
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	origErr := fmt.Errorf("invalid secret SECRET")
+
+	if e, a := origErr, RedactError(context.Background(), origErr); e != a {
+		t.Errorf("expect error not redacted without sensitive values, got %v", a)
+	}
+	if err := RedactError(context.Background(), nil); err != nil {
+		t.Errorf("expect no error, got %v", err)
+	}
+
+	ctx := WithStackValue(context.Background(), sensitiveValuesKey{}, []string{"SECRET"})
+	err := RedactError(ctx, origErr)
+	if e, a := "invalid secret *****", err.Error(); e != a {
+		t.Errorf("expect %q error, got %q", e, a)
+	}
+	if e, a := err, RedactError(ctx, err); e != a {
+		t.Errorf("expect redacted error not wrapped again, got %v", a)
+	}
+	if !errors.Is(err, origErr) {
+		t.Errorf("expect original error to be unwrapped")
+	}
+}
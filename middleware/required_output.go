@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RequiredOutputFieldsValidator returns the names of the required fields
+// missing from the operation's decoded output. Provided by the operation's
+// generated code.
+type RequiredOutputFieldsValidator func(output interface{}) (missing []string)
+
+// MissingRequiredFieldError is the error returned when the operation's
+// response is missing fields the operation's output requires, (e.g. a service
+// bug, or truncated response).
+type MissingRequiredFieldError struct {
+	// The type of the operation's output.
+	OutputType string
+
+	// The names of the missing required fields.
+	Fields []string
+}
+
+func (e *MissingRequiredFieldError) Error() string {
+	return fmt.Sprintf("response missing required output fields of %s, [%s]",
+		e.OutputType, strings.Join(e.Fields, ", "))
+}
+
+// AddRequiredOutputFieldsMiddleware adds the middleware validating the
+// operation's decoded output has all required fields, to the front of the
+// stack's Deserialize step, so that the output is validated after it is
+// deserialized. Returns a MissingRequiredFieldError if any required fields
+// are missing.
+func AddRequiredOutputFieldsMiddleware(stack *Stack, validator RequiredOutputFieldsValidator) error {
+	return stack.Deserialize.Add(&requiredOutputFields{validator: validator}, Before)
+}
+
+type requiredOutputFields struct {
+	validator RequiredOutputFieldsValidator
+}
+
+// ID returns the middleware identifier.
+func (*requiredOutputFields) ID() string { return "RequiredOutputFields" }
+
+// HandleDeserialize validates the decoded output returned by the next
+// handler.
+func (m *requiredOutputFields) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil || out.Result == nil {
+		return out, metadata, err
+	}
+
+	if missing := m.validator(out.Result); len(missing) != 0 {
+		return out, metadata, &MissingRequiredFieldError{
+			OutputType: fmt.Sprintf("%T", out.Result),
+			Fields:     missing,
+		}
+	}
+
+	return out, metadata, err
+}
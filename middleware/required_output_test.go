@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockGetItemOutput struct {
+	ID   *string
+	Name *string
+	Tags []string
+}
+
+func validateMockGetItemOutput(v interface{}) []string {
+	output := v.(*mockGetItemOutput)
+
+	var missing []string
+	if output.ID == nil {
+		missing = append(missing, "ID")
+	}
+	if output.Name == nil {
+		missing = append(missing, "Name")
+	}
+	return missing
+}
+
+func TestRequiredOutputFieldsMiddleware(t *testing.T) {
+	id, name := "1234", "item"
+
+	cases := map[string]struct {
+		Output    *mockGetItemOutput
+		ExpectErr *MissingRequiredFieldError
+	}{
+		"all required fields": {
+			Output: &mockGetItemOutput{ID: &id, Name: &name},
+		},
+		"missing required field": {
+			Output: &mockGetItemOutput{ID: &id, Tags: []string{"a"}},
+			ExpectErr: &MissingRequiredFieldError{
+				OutputType: "*middleware.mockGetItemOutput",
+				Fields:     []string{"Name"},
+			},
+		},
+		"missing all required fields": {
+			Output: &mockGetItemOutput{},
+			ExpectErr: &MissingRequiredFieldError{
+				OutputType: "*middleware.mockGetItemOutput",
+				Fields:     []string{"ID", "Name"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+			stack.Deserialize.Add(DeserializeMiddlewareFunc("OperationDeserializer",
+				func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+					out DeserializeOutput, metadata Metadata, err error,
+				) {
+					out, metadata, err = next.HandleDeserialize(ctx, in)
+					out.Result = c.Output
+					return out, metadata, err
+				}), After)
+			if err := AddRequiredOutputFieldsMiddleware(stack, validateMockGetItemOutput); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			result, _, err := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, Metadata, error,
+			) {
+				return nil, Metadata{}, nil
+			}), stack).Handle(context.Background(), struct{}{})
+
+			if c.ExpectErr == nil {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if e, a := c.Output, result; e != a {
+					t.Errorf("expect %v result, got %v", e, a)
+				}
+				return
+			}
+
+			var fieldErr *MissingRequiredFieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("expect %T error, got %v", fieldErr, err)
+			}
+			if diff := cmp.Diff(c.ExpectErr, fieldErr); len(diff) != 0 {
+				t.Errorf("expect error to match\n%s", diff)
+			}
+			for _, field := range c.ExpectErr.Fields {
+				if !strings.Contains(err.Error(), field) {
+					t.Errorf("expect error to contain %q, got %q", field, err.Error())
+				}
+			}
+		})
+	}
+}
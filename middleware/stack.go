@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"strings"
 )
@@ -70,6 +71,8 @@ type Stack struct {
 	Deserialize *DeserializeStep
 
 	id string
+
+	instrumented bool
 }
 
 // NewStack returns an initialize empty stack.
@@ -98,6 +101,10 @@ func (s *Stack) ID() string { return s.id }
 func (s *Stack) HandleMiddleware(ctx context.Context, input interface{}, next Handler) (
 	output interface{}, metadata Metadata, err error,
 ) {
+	if s.instrumented {
+		return s.handleInstrumented(ctx, input, next)
+	}
+
 	h := DecorateHandler(next,
 		s.Initialize,
 		s.Serialize,
@@ -109,6 +116,64 @@ func (s *Stack) HandleMiddleware(ctx context.Context, input interface{}, next Ha
 	return h.Handle(ctx, input)
 }
 
+// Clone returns a copy of the stack whose steps can be modified, (e.g. Add,
+// Remove), without affecting the original stack. The middleware within the
+// steps are shared with the original stack, not copied.
+func (s *Stack) Clone() *Stack {
+	return &Stack{
+		id:           s.id,
+		Initialize:   s.Initialize.clone(),
+		Serialize:    s.Serialize.clone(),
+		Build:        s.Build.clone(),
+		Finalize:     s.Finalize.clone(),
+		Deserialize:  s.Deserialize.clone(),
+		instrumented: s.instrumented,
+	}
+}
+
+// RemoveByID removes the middleware identified by id from every step of the
+// stack it is present in. Returns an error if the middleware is not present
+// in any step.
+func (s *Stack) RemoveByID(id string) error {
+	var found bool
+
+	if s.Initialize.Has(id) {
+		if _, err := s.Initialize.Remove(id); err != nil {
+			return err
+		}
+		found = true
+	}
+	if s.Serialize.Has(id) {
+		if _, err := s.Serialize.Remove(id); err != nil {
+			return err
+		}
+		found = true
+	}
+	if s.Build.Has(id) {
+		if _, err := s.Build.Remove(id); err != nil {
+			return err
+		}
+		found = true
+	}
+	if s.Finalize.Has(id) {
+		if _, err := s.Finalize.Remove(id); err != nil {
+			return err
+		}
+		found = true
+	}
+	if s.Deserialize.Has(id) {
+		if _, err := s.Deserialize.Remove(id); err != nil {
+			return err
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("not found, %v", id)
+	}
+	return nil
+}
+
 // List returns a list of all middleware in the stack by step.
 func (s *Stack) List() []string {
 	var l []string
@@ -132,6 +197,66 @@ func (s *Stack) List() []string {
 	return l
 }
 
+// StepDescription provides a machine readable description of a stack step,
+// and the middleware within the step in the order they will be invoked.
+type StepDescription struct {
+	// The identifier of the step.
+	Step string `json:"step"`
+
+	// The middleware of the step in invocation order.
+	Middleware []MiddlewareDescription `json:"middleware"`
+}
+
+// MiddlewareDescription provides a machine readable description of a
+// middleware within a stack step.
+type MiddlewareDescription struct {
+	// The identifier of the middleware.
+	ID string `json:"id"`
+
+	// The zero based position of the middleware within its step.
+	Order int `json:"order"`
+}
+
+// Describe returns a serializable description of the stack's steps, and the
+// middleware within each step in the order they will be invoked. Steps are
+// returned in the order the stack invokes them.
+//
+// Unlike String, which is intended to be human readable, the description is
+// intended to be consumed by tools, (e.g. marshaled to JSON).
+func (s *Stack) Describe() []StepDescription {
+	steps := []stackStepper{
+		s.Initialize,
+		s.Serialize,
+		s.Build,
+		s.Finalize,
+		s.Deserialize,
+	}
+
+	descs := make([]StepDescription, 0, len(steps))
+	for _, step := range steps {
+		ids := step.List()
+
+		desc := StepDescription{
+			Step:       step.ID(),
+			Middleware: make([]MiddlewareDescription, 0, len(ids)),
+		}
+		for i, id := range ids {
+			desc.Middleware = append(desc.Middleware, MiddlewareDescription{
+				ID:    id,
+				Order: i,
+			})
+		}
+
+		descs = append(descs, desc)
+	}
+
+	return descs
+}
+
+// String returns a human readable rendering of the stack for debugging. The
+// stack's ID is followed by each step's ID, and the IDs of the middleware
+// within the step in the order they will be invoked, indented by tabs. Steps
+// without middleware are included.
 func (s *Stack) String() string {
 	var b strings.Builder
 
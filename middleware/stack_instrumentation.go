@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+)
+
+// HandlerStepID is the step identifier errors returned by the stack's
+// decorated handler, (e.g. the transport's client), are attributed to.
+const HandlerStepID = "Handler"
+
+// SetInstrumentation sets if the stack is invoked in instrumentation mode.
+// In instrumentation mode an error returned by the stack is attributed to the
+// step that produced it, which is recorded in the operation's metadata, see
+// GetFailingStep.
+//
+// The failing step is the deepest step, or the decorated handler, that
+// returned the error. An error returned by a middleware on the return path,
+// (e.g. a deserializer), is attributed to that middleware's step. An error
+// that is handled by an outer step, (e.g. a retried attempt), is not
+// recorded, and the failing step is only set if the operation fails.
+func (s *Stack) SetInstrumentation(enabled bool) {
+	s.instrumented = enabled
+}
+
+type failingStepKey struct{}
+
+// GetFailingStep returns the identifier of the step that produced the
+// operation's error, (e.g. "Serialize", "Deserialize", or HandlerStepID), and
+// if the value was set. Only set for stacks invoked in instrumentation mode,
+// see Stack.SetInstrumentation.
+func GetFailingStep(metadata MetadataReader) (string, bool) {
+	v, ok := metadata.Get(failingStepKey{}).(string)
+	return v, ok
+}
+
+// stepTracker tracks the step that produced the error of a single
+// invocation of the stack. Steps may be invoked concurrently, (e.g. hedged
+// attempts), so the tracker is safe for concurrent use.
+type stepTracker struct {
+	mu      sync.Mutex
+	failing string
+}
+
+// exit records the step returning. The first step to return an error is the
+// deepest step that produced it. A step returning without an error clears the
+// failing step, as the error was handled, (e.g. retried).
+func (t *stepTracker) exit(step string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err == nil {
+		t.failing = ""
+		return
+	}
+	if len(t.failing) == 0 {
+		t.failing = step
+	}
+}
+
+func (t *stepTracker) failingStep() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failing
+}
+
+// trackedStep decorates a step middleware to track when the step returns.
+type trackedStep struct {
+	Middleware
+	step    string
+	tracker *stepTracker
+}
+
+func (m trackedStep) HandleMiddleware(ctx context.Context, input interface{}, next Handler) (
+	output interface{}, metadata Metadata, err error,
+) {
+	output, metadata, err = m.Middleware.HandleMiddleware(ctx, input, next)
+	m.tracker.exit(m.step, err)
+	return output, metadata, err
+}
+
+// trackedHandler decorates the stack's handler to track when the handler
+// returns.
+type trackedHandler struct {
+	Handler
+	tracker *stepTracker
+}
+
+func (h trackedHandler) Handle(ctx context.Context, input interface{}) (
+	output interface{}, metadata Metadata, err error,
+) {
+	output, metadata, err = h.Handler.Handle(ctx, input)
+	h.tracker.exit(HandlerStepID, err)
+	return output, metadata, err
+}
+
+func (s *Stack) handleInstrumented(ctx context.Context, input interface{}, next Handler) (
+	output interface{}, metadata Metadata, err error,
+) {
+	tracker := &stepTracker{}
+
+	h := DecorateHandler(trackedHandler{Handler: next, tracker: tracker},
+		trackedStep{Middleware: s.Initialize, step: "Initialize", tracker: tracker},
+		trackedStep{Middleware: s.Serialize, step: "Serialize", tracker: tracker},
+		trackedStep{Middleware: s.Build, step: "Build", tracker: tracker},
+		trackedStep{Middleware: s.Finalize, step: "Finalize", tracker: tracker},
+		trackedStep{Middleware: s.Deserialize, step: "Deserialize", tracker: tracker},
+	)
+
+	output, metadata, err = h.Handle(ctx, input)
+	if step := tracker.failingStep(); err != nil && len(step) != 0 {
+		metadata.Set(failingStepKey{}, step)
+	}
+
+	return output, metadata, err
+}
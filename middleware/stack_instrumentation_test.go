@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStackInstrumentation_FailingStep(t *testing.T) {
+	cases := map[string]struct {
+		AddMiddleware func(*Stack)
+		HandlerErr    error
+		FailFirst     bool
+		Instrumented  bool
+		ExpectStep    string
+	}{
+		"serialize error": {
+			AddMiddleware: func(s *Stack) {
+				s.Serialize.Add(SerializeMiddlewareFunc("fail", func(
+					ctx context.Context, in SerializeInput, next SerializeHandler,
+				) (SerializeOutput, Metadata, error) {
+					return SerializeOutput{}, Metadata{}, fmt.Errorf("serialize error")
+				}), After)
+			},
+			Instrumented: true,
+			ExpectStep:   "Serialize",
+		},
+		"handler error": {
+			HandlerErr:   fmt.Errorf("transport error"),
+			Instrumented: true,
+			ExpectStep:   HandlerStepID,
+		},
+		"deserialize error after handler": {
+			AddMiddleware: func(s *Stack) {
+				s.Deserialize.Add(DeserializeMiddlewareFunc("fail", func(
+					ctx context.Context, in DeserializeInput, next DeserializeHandler,
+				) (out DeserializeOutput, metadata Metadata, err error) {
+					out, metadata, err = next.HandleDeserialize(ctx, in)
+					if err != nil {
+						return out, metadata, err
+					}
+					return out, metadata, fmt.Errorf("deserialize error")
+				}), After)
+			},
+			Instrumented: true,
+			ExpectStep:   "Deserialize",
+		},
+		"handler error retried": {
+			AddMiddleware: func(s *Stack) {
+				s.Finalize.Add(FinalizeMiddlewareFunc("retry", func(
+					ctx context.Context, in FinalizeInput, next FinalizeHandler,
+				) (out FinalizeOutput, metadata Metadata, err error) {
+					for i := 0; i < 2; i++ {
+						out, metadata, err = next.HandleFinalize(ctx, in)
+						if err == nil {
+							break
+						}
+					}
+					return out, metadata, err
+				}), Before)
+			},
+			FailFirst:    true,
+			Instrumented: true,
+		},
+		"concurrent attempts": {
+			AddMiddleware: func(s *Stack) {
+				s.Finalize.Add(FinalizeMiddlewareFunc("hedge", func(
+					ctx context.Context, in FinalizeInput, next FinalizeHandler,
+				) (out FinalizeOutput, metadata Metadata, err error) {
+					var wg sync.WaitGroup
+					errs := make([]error, 4)
+					for i := range errs {
+						wg.Add(1)
+						go func(i int) {
+							defer wg.Done()
+							_, _, errs[i] = next.HandleFinalize(ctx, in)
+						}(i)
+					}
+					wg.Wait()
+					return out, metadata, errs[0]
+				}), Before)
+			},
+			HandlerErr:   fmt.Errorf("transport error"),
+			Instrumented: true,
+			ExpectStep:   HandlerStepID,
+		},
+		"no error": {
+			Instrumented: true,
+		},
+		"not instrumented": {
+			HandlerErr: fmt.Errorf("transport error"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			s.SetInstrumentation(c.Instrumented)
+			if c.AddMiddleware != nil {
+				c.AddMiddleware(s)
+			}
+
+			var calls int32
+			handler := HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, Metadata, error,
+			) {
+				if c.FailFirst && atomic.AddInt32(&calls, 1) == 1 {
+					return nil, Metadata{}, fmt.Errorf("transport error")
+				}
+				return nil, Metadata{}, c.HandlerErr
+			})
+
+			_, metadata, err := DecorateHandler(handler, s).Handle(context.Background(), struct{}{})
+			if len(c.ExpectStep) != 0 && err == nil {
+				t.Fatalf("expect error, got none")
+			}
+			if len(c.ExpectStep) == 0 && c.HandlerErr == nil && err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			step, ok := GetFailingStep(metadata)
+			if e, a := len(c.ExpectStep) != 0, ok; e != a {
+				t.Fatalf("expect failing step %v, got %v", e, a)
+			}
+			if e, a := c.ExpectStep, step; e != a {
+				t.Errorf("expect %v failing step, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// package variable that can be override in unit tests.
+var timeNow = time.Now
+
+// AddStackOverheadMiddleware adds the middleware to measure the time spent
+// in the middleware stack separately from the time spent in the stack's
+// terminal handler, (e.g. the HTTP round trip). The middleware are added to
+// the front of the Initialize step, and the end of the Deserialize step, so
+// that all other middleware are included in the stack's overhead.
+//
+// The measured durations are available from the operation's metadata with
+// GetStackOverhead and GetTransportTime.
+func AddStackOverheadMiddleware(stack *Stack) error {
+	if err := stack.Initialize.Add(&stackOverheadTimer{}, Before); err != nil {
+		return fmt.Errorf("failed to add %s initialize middleware, %w",
+			(*stackOverheadTimer)(nil).ID(), err)
+	}
+	if err := stack.Deserialize.Add(&transportTimer{}, After); err != nil {
+		return fmt.Errorf("failed to add %s deserialize middleware, %w",
+			(*transportTimer)(nil).ID(), err)
+	}
+	return nil
+}
+
+type (
+	stackTimingKey   struct{}
+	stackOverheadKey struct{}
+	transportTimeKey struct{}
+)
+
+// GetStackOverhead returns the time spent in the middleware stack, excluding
+// the time spent in the terminal handler, and if the value was set.
+func GetStackOverhead(metadata MetadataReader) (time.Duration, bool) {
+	v, ok := metadata.Get(stackOverheadKey{}).(time.Duration)
+	return v, ok
+}
+
+// GetTransportTime returns the total time spent in the stack's terminal
+// handler for all attempts of the operation, and if the value was set.
+func GetTransportTime(metadata MetadataReader) (time.Duration, bool) {
+	v, ok := metadata.Get(transportTimeKey{}).(time.Duration)
+	return v, ok
+}
+
+// stackTiming accumulates the time spent in the terminal handler across all
+// invocations of the handler for an operation, (e.g. retry attempts).
+type stackTiming struct {
+	mu        sync.Mutex
+	transport time.Duration
+}
+
+func (t *stackTiming) addTransport(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.transport += d
+}
+
+func (t *stackTiming) transportTime() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.transport
+}
+
+type stackOverheadTimer struct{}
+
+// ID returns the middleware identifier.
+func (*stackOverheadTimer) ID() string { return "StackOverheadTimer" }
+
+// HandleInitialize measures the total time of the operation, and records the
+// stack overhead and transport time in the metadata.
+func (*stackOverheadTimer) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	timing := &stackTiming{}
+	ctx = WithStackValue(ctx, stackTimingKey{}, timing)
+
+	start := timeNow()
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	total := timeNow().Sub(start)
+
+	transport := timing.transportTime()
+	metadata.Set(transportTimeKey{}, transport)
+	metadata.Set(stackOverheadKey{}, total-transport)
+
+	return out, metadata, err
+}
+
+type transportTimer struct{}
+
+// ID returns the middleware identifier.
+func (*transportTimer) ID() string { return "TransportTimer" }
+
+// HandleDeserialize measures the time spent in the stack's terminal handler.
+func (*transportTimer) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	timing, ok := GetStackValue(ctx, stackTimingKey{}).(*stackTiming)
+	if !ok {
+		return next.HandleDeserialize(ctx, in)
+	}
+
+	start := timeNow()
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	timing.addTransport(timeNow().Sub(start))
+
+	return out, metadata, err
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStackOverheadMiddleware(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddStackOverheadMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	// Middleware before and after the terminal handler each add stack
+	// overhead.
+	stack.Serialize.Add(SerializeMiddlewareFunc("serialize",
+		func(ctx context.Context, in SerializeInput, next SerializeHandler) (
+			SerializeOutput, Metadata, error,
+		) {
+			now = now.Add(2 * time.Millisecond)
+			return next.HandleSerialize(ctx, in)
+		}), After)
+	stack.Deserialize.Insert(DeserializeMiddlewareFunc("deserialize",
+		func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+			DeserializeOutput, Metadata, error,
+		) {
+			out, metadata, err := next.HandleDeserialize(ctx, in)
+			now = now.Add(3 * time.Millisecond)
+			return out, metadata, err
+		}), "TransportTimer", Before)
+
+	// Mock transport with a known delay.
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		now = now.Add(100 * time.Millisecond)
+		return nil, Metadata{}, nil
+	})
+
+	_, metadata, err := DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	overhead, ok := GetStackOverhead(metadata)
+	if !ok {
+		t.Fatalf("expect stack overhead in metadata")
+	}
+	if e, a := 5*time.Millisecond, overhead; e != a {
+		t.Errorf("expect %v stack overhead, got %v", e, a)
+	}
+
+	transport, ok := GetTransportTime(metadata)
+	if !ok {
+		t.Fatalf("expect transport time in metadata")
+	}
+	if e, a := 100*time.Millisecond, transport; e != a {
+		t.Errorf("expect %v transport time, got %v", e, a)
+	}
+}
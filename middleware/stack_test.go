@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -67,3 +69,492 @@ func TestStackString(t *testing.T) {
 		t.Errorf("expect and actual stack list differ\n%s", diff)
 	}
 }
+
+func TestStackString_EmptySteps(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	s.Serialize.Add(mockSerializeMiddleware("second"), After)
+	s.Serialize.Add(mockSerializeMiddleware("first"), Before)
+	s.Finalize.Add(mockFinalizeMiddleware("third"), After)
+
+	actual := s.String()
+
+	expect := strings.Join([]string{
+		"fooStack",
+		"\t" + (*InitializeStep)(nil).ID(),
+		"\t" + (*SerializeStep)(nil).ID(),
+		"\t\t" + "first",
+		"\t\t" + "second",
+		"\t" + (*BuildStep)(nil).ID(),
+		"\t" + (*FinalizeStep)(nil).ID(),
+		"\t\t" + "third",
+		"\t" + (*DeserializeStep)(nil).ID(),
+		"",
+	}, "\n")
+
+	if diff := cmp.Diff(expect, actual); len(diff) != 0 {
+		t.Errorf("expect and actual stack string differ\n%s", diff)
+	}
+}
+
+func TestStackDescribe(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	s.Initialize.Add(mockInitializeMiddleware("first"), After)
+	s.Serialize.Add(mockSerializeMiddleware("second"), After)
+	s.Serialize.Add(mockSerializeMiddleware("third"), Before)
+	s.Finalize.Add(mockFinalizeMiddleware("fourth"), After)
+	s.Deserialize.Add(mockDeserializeMiddleware("fifth"), After)
+
+	b, err := json.Marshal(s.Describe())
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var actual []map[string]interface{}
+	if err := json.Unmarshal(b, &actual); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	mw := func(id string, order float64) map[string]interface{} {
+		return map[string]interface{}{"id": id, "order": order}
+	}
+
+	expect := []map[string]interface{}{
+		{
+			"step":       (*InitializeStep)(nil).ID(),
+			"middleware": []interface{}{mw("first", 0)},
+		},
+		{
+			"step":       (*SerializeStep)(nil).ID(),
+			"middleware": []interface{}{mw("third", 0), mw("second", 1)},
+		},
+		{
+			"step":       (*BuildStep)(nil).ID(),
+			"middleware": []interface{}{},
+		},
+		{
+			"step":       (*FinalizeStep)(nil).ID(),
+			"middleware": []interface{}{mw("fourth", 0)},
+		},
+		{
+			"step":       (*DeserializeStep)(nil).ID(),
+			"middleware": []interface{}{mw("fifth", 0)},
+		},
+	}
+
+	if diff := cmp.Diff(expect, actual); len(diff) != 0 {
+		t.Errorf("expect and actual stack description differ\n%s", diff)
+	}
+}
+
+func TestStepList_InvocationOrder(t *testing.T) {
+	var invoked []string
+
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	add := func(step string, id string, pos RelativePosition) {
+		t.Helper()
+		var err error
+		switch step {
+		case "initialize":
+			err = s.Initialize.Add(InitializeMiddlewareFunc(id, func(
+				ctx context.Context, in InitializeInput, next InitializeHandler,
+			) (InitializeOutput, Metadata, error) {
+				invoked = append(invoked, id)
+				return next.HandleInitialize(ctx, in)
+			}), pos)
+		case "serialize":
+			err = s.Serialize.Add(SerializeMiddlewareFunc(id, func(
+				ctx context.Context, in SerializeInput, next SerializeHandler,
+			) (SerializeOutput, Metadata, error) {
+				invoked = append(invoked, id)
+				return next.HandleSerialize(ctx, in)
+			}), pos)
+		case "build":
+			err = s.Build.Add(BuildMiddlewareFunc(id, func(
+				ctx context.Context, in BuildInput, next BuildHandler,
+			) (BuildOutput, Metadata, error) {
+				invoked = append(invoked, id)
+				return next.HandleBuild(ctx, in)
+			}), pos)
+		case "finalize":
+			err = s.Finalize.Add(FinalizeMiddlewareFunc(id, func(
+				ctx context.Context, in FinalizeInput, next FinalizeHandler,
+			) (FinalizeOutput, Metadata, error) {
+				invoked = append(invoked, id)
+				return next.HandleFinalize(ctx, in)
+			}), pos)
+		case "deserialize":
+			err = s.Deserialize.Add(DeserializeMiddlewareFunc(id, func(
+				ctx context.Context, in DeserializeInput, next DeserializeHandler,
+			) (DeserializeOutput, Metadata, error) {
+				invoked = append(invoked, id)
+				return next.HandleDeserialize(ctx, in)
+			}), pos)
+		}
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+
+	for _, step := range []string{"initialize", "serialize", "build", "finalize", "deserialize"} {
+		add(step, step+"-a", After)
+		add(step, step+"-b", Before)
+		add(step, step+"-c", After)
+	}
+
+	lists := [][]string{
+		s.Initialize.List(),
+		s.Serialize.List(),
+		s.Build.List(),
+		s.Finalize.List(),
+		s.Deserialize.List(),
+	}
+
+	var listed []string
+	for _, l := range lists {
+		listed = append(listed, l...)
+	}
+
+	if diff := cmp.Diff([]string{"build-b", "build-a", "build-c"}, s.Build.List()); len(diff) != 0 {
+		t.Errorf("expect build step list to match\n%s", diff)
+	}
+
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		return nil, Metadata{}, nil
+	})
+	if _, _, err := DecorateHandler(handler, s).Handle(context.Background(), struct{}{}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if diff := cmp.Diff(listed, invoked); len(diff) != 0 {
+		t.Errorf("expect listed order to match invocation order\n%s", diff)
+	}
+
+	// Listing must not mutate the step.
+	if diff := cmp.Diff(lists[2], s.Build.List()); len(diff) != 0 {
+		t.Errorf("expect build step list to be unchanged\n%s", diff)
+	}
+}
+
+func TestStackList_RelativeOrder(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	noError(t, s.Initialize.Add(mockInitializeMiddleware("init-a"), After))
+	noError(t, s.Initialize.Add(mockInitializeMiddleware("init-b"), Before))
+	noError(t, s.Build.Add(mockBuildMiddleware("build-a"), After))
+	noError(t, s.Build.Insert(mockBuildMiddleware("build-b"), "build-a", Before))
+	noError(t, s.Build.Insert(mockBuildMiddleware("build-c"), "build-a", After))
+	noError(t, s.Deserialize.Add(mockDeserializeMiddleware("deser-a"), Before))
+	noError(t, s.Deserialize.Add(mockDeserializeMiddleware("deser-b"), Before))
+
+	expect := []string{
+		"fooStack",
+		"Initialize stack step",
+		"init-b",
+		"init-a",
+		"Serialize stack step",
+		"Build stack step",
+		"build-b",
+		"build-a",
+		"build-c",
+		"Finalize stack step",
+		"Deserialize stack step",
+		"deser-b",
+		"deser-a",
+	}
+
+	if diff := cmp.Diff(expect, s.List()); len(diff) != 0 {
+		t.Errorf("expect and actual stack list differ\n%s", diff)
+	}
+}
+
+func TestStackClone(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	noError(t, s.Initialize.Add(mockInitializeMiddleware("init-a"), After))
+	noError(t, s.Serialize.Add(mockSerializeMiddleware("serialize-a"), After))
+	noError(t, s.Build.Add(mockBuildMiddleware("build-a"), After))
+	noError(t, s.Build.Add(mockBuildMiddleware("build-b"), After))
+	noError(t, s.Finalize.Add(mockFinalizeMiddleware("finalize-a"), After))
+	noError(t, s.Deserialize.Add(mockDeserializeMiddleware("deser-a"), After))
+
+	expectOriginal := s.List()
+
+	c := s.Clone()
+	if diff := cmp.Diff(expectOriginal, c.List()); len(diff) != 0 {
+		t.Fatalf("expect clone to match original\n%s", diff)
+	}
+
+	noError(t, c.Initialize.Add(mockInitializeMiddleware("init-b"), Before))
+	noError(t, c.Serialize.Add(mockSerializeMiddleware("serialize-b"), After))
+	noError(t, c.Build.Insert(mockBuildMiddleware("build-c"), "build-a", Before))
+	_, err := c.Build.Remove("build-b")
+	noError(t, err)
+	_, err = c.Finalize.Swap("finalize-a", mockFinalizeMiddleware("finalize-b"))
+	noError(t, err)
+	c.Deserialize.Clear()
+
+	if diff := cmp.Diff(expectOriginal, s.List()); len(diff) != 0 {
+		t.Errorf("expect original unchanged by clone\n%s", diff)
+	}
+	if _, ok := s.Finalize.Get("finalize-b"); ok {
+		t.Errorf("expect original to not have clone's swapped middleware")
+	}
+
+	expectClone := []string{
+		"fooStack",
+		"Initialize stack step",
+		"init-b",
+		"init-a",
+		"Serialize stack step",
+		"serialize-a",
+		"serialize-b",
+		"Build stack step",
+		"build-c",
+		"build-a",
+		"Finalize stack step",
+		"finalize-b",
+		"Deserialize stack step",
+	}
+	if diff := cmp.Diff(expectClone, c.List()); len(diff) != 0 {
+		t.Errorf("expect clone list\n%s", diff)
+	}
+
+	noError(t, s.Build.Add(mockBuildMiddleware("build-d"), Before))
+	if _, ok := c.Build.Get("build-d"); ok {
+		t.Errorf("expect clone unchanged by original")
+	}
+
+	_, _, err = c.HandleMiddleware(context.Background(), struct{}{},
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			return nil, Metadata{}, nil
+		}),
+	)
+	noError(t, err)
+}
+
+type configuredBuildMiddleware struct {
+	id      string
+	Setting string
+}
+
+func (m *configuredBuildMiddleware) ID() string { return m.id }
+
+func (m *configuredBuildMiddleware) HandleBuild(ctx context.Context, in BuildInput, next BuildHandler) (
+	out BuildOutput, metadata Metadata, err error,
+) {
+	return next.HandleBuild(ctx, in)
+}
+
+func TestStepGet(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	configured := &configuredBuildMiddleware{id: "configured", Setting: "abc"}
+	noError(t, s.Build.Add(mockBuildMiddleware("other"), After))
+	noError(t, s.Build.Add(configured, After))
+
+	m, ok := s.Build.Get("configured")
+	if !ok {
+		t.Fatalf("expect middleware found")
+	}
+	actual, ok := m.(*configuredBuildMiddleware)
+	if !ok {
+		t.Fatalf("expect %T middleware, got %T", configured, m)
+	}
+	if actual != configured {
+		t.Errorf("expect stored middleware instance, got %p", actual)
+	}
+	if e, a := "abc", actual.Setting; e != a {
+		t.Errorf("expect %q setting, got %q", e, a)
+	}
+
+	if m, ok := s.Build.Get("unknown"); ok || m != nil {
+		t.Errorf("expect unknown middleware not found, got %v, %v", m, ok)
+	}
+
+	replacement := &configuredBuildMiddleware{id: "configured", Setting: "xyz"}
+	if m, _ := s.Build.Get("configured"); m.(*configuredBuildMiddleware).Setting != "xyz" {
+		if _, err := s.Build.Swap("configured", replacement); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+	if m, _ := s.Build.Get("configured"); m != replacement {
+		t.Errorf("expect swapped middleware instance, got %v", m)
+	}
+
+	noError(t, s.Initialize.Add(mockInitializeMiddleware("init"), After))
+	noError(t, s.Serialize.Add(mockSerializeMiddleware("serialize"), After))
+	noError(t, s.Finalize.Add(mockFinalizeMiddleware("finalize"), After))
+	noError(t, s.Deserialize.Add(mockDeserializeMiddleware("deserialize"), After))
+
+	gets := map[string]func(string) (interface{}, bool){
+		"init":        func(id string) (interface{}, bool) { return s.Initialize.Get(id) },
+		"serialize":   func(id string) (interface{}, bool) { return s.Serialize.Get(id) },
+		"finalize":    func(id string) (interface{}, bool) { return s.Finalize.Get(id) },
+		"deserialize": func(id string) (interface{}, bool) { return s.Deserialize.Get(id) },
+	}
+	for id, get := range gets {
+		if _, ok := get(id); !ok {
+			t.Errorf("expect %v middleware found", id)
+		}
+		if _, ok := get("unknown"); ok {
+			t.Errorf("expect unknown middleware not found in %v step", id)
+		}
+	}
+}
+
+func TestStepHas(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	register := func() error {
+		if !s.Build.Has("plugin") {
+			return s.Build.Add(mockBuildMiddleware("plugin"), After)
+		}
+		return nil
+	}
+	noError(t, register())
+	noError(t, register())
+
+	if e, a := []string{"plugin"}, s.Build.List(); !cmp.Equal(e, a) {
+		t.Errorf("expect %v build middleware, got %v", e, a)
+	}
+
+	noError(t, s.Initialize.Add(mockInitializeMiddleware("init"), After))
+	noError(t, s.Serialize.Add(mockSerializeMiddleware("serialize"), After))
+	noError(t, s.Finalize.Add(mockFinalizeMiddleware("finalize"), After))
+	noError(t, s.Deserialize.Add(mockDeserializeMiddleware("deserialize"), After))
+
+	has := map[string]func(string) bool{
+		"init":        s.Initialize.Has,
+		"serialize":   s.Serialize.Has,
+		"plugin":      s.Build.Has,
+		"finalize":    s.Finalize.Has,
+		"deserialize": s.Deserialize.Has,
+	}
+	for id, fn := range has {
+		if !fn(id) {
+			t.Errorf("expect %v middleware present", id)
+		}
+		if fn("unknown") {
+			t.Errorf("expect unknown middleware not present in %v step", id)
+		}
+	}
+}
+
+func TestStackRemoveByID(t *testing.T) {
+	newStack := func() *Stack {
+		s := NewStack("fooStack", func() interface{} { return struct{}{} })
+		noError(t, s.Initialize.Add(mockInitializeMiddleware("init"), After))
+		noError(t, s.Serialize.Add(mockSerializeMiddleware("shared"), After))
+		noError(t, s.Build.Add(mockBuildMiddleware("build"), After))
+		noError(t, s.Finalize.Add(mockFinalizeMiddleware("finalize"), After))
+		noError(t, s.Deserialize.Add(mockDeserializeMiddleware("shared"), After))
+		return s
+	}
+
+	cases := map[string]struct {
+		ID        string
+		Expect    []string
+		ExpectErr bool
+	}{
+		"single step": {
+			ID: "build",
+			Expect: []string{
+				"fooStack",
+				"Initialize stack step",
+				"init",
+				"Serialize stack step",
+				"shared",
+				"Build stack step",
+				"Finalize stack step",
+				"finalize",
+				"Deserialize stack step",
+				"shared",
+			},
+		},
+		"multiple steps": {
+			ID: "shared",
+			Expect: []string{
+				"fooStack",
+				"Initialize stack step",
+				"init",
+				"Serialize stack step",
+				"Build stack step",
+				"build",
+				"Finalize stack step",
+				"finalize",
+				"Deserialize stack step",
+			},
+		},
+		"not found": {
+			ID:        "unknown",
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := newStack()
+			before := s.List()
+
+			err := s.RemoveByID(c.ID)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if diff := cmp.Diff(before, s.List()); len(diff) != 0 {
+					t.Errorf("expect stack unchanged\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.Expect, s.List()); len(diff) != 0 {
+				t.Errorf("expect and actual stack list differ\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStepInsertAt(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	noError(t, s.Initialize.InsertAt(mockInitializeMiddleware("init-a"), 0))
+	noError(t, s.Initialize.InsertAt(mockInitializeMiddleware("init-b"), 0))
+	noError(t, s.Serialize.InsertAt(mockSerializeMiddleware("serialize-a"), -1))
+	noError(t, s.Build.Add(mockBuildMiddleware("build-a"), After))
+	noError(t, s.Build.Add(mockBuildMiddleware("build-c"), After))
+	noError(t, s.Build.InsertAt(mockBuildMiddleware("build-b"), 1))
+	noError(t, s.Finalize.InsertAt(mockFinalizeMiddleware("finalize-a"), 0))
+	noError(t, s.Deserialize.Add(mockDeserializeMiddleware("deser-a"), After))
+	noError(t, s.Deserialize.InsertAt(mockDeserializeMiddleware("deser-b"), -1))
+
+	if err := s.Build.InsertAt(mockBuildMiddleware("build-d"), 10); err == nil {
+		t.Errorf("expect out of range error, got none")
+	}
+
+	expect := []string{
+		"fooStack",
+		"Initialize stack step",
+		"init-b",
+		"init-a",
+		"Serialize stack step",
+		"serialize-a",
+		"Build stack step",
+		"build-a",
+		"build-b",
+		"build-c",
+		"Finalize stack step",
+		"finalize-a",
+		"Deserialize stack step",
+		"deser-a",
+		"deser-b",
+	}
+	if diff := cmp.Diff(expect, s.List()); len(diff) != 0 {
+		t.Errorf("expect and actual stack list differ\n%s", diff)
+	}
+}
@@ -130,6 +130,12 @@ func (s *DeserializeStep) Get(id string) (DeserializeMiddleware, bool) {
 	return get.(DeserializeMiddleware), ok
 }
 
+// Has returns if the middleware identified by id is present in the step. Add
+// returns an error for middleware that is already present.
+func (s *DeserializeStep) Has(id string) bool {
+	return s.ids.Has(id)
+}
+
 // Add injects the middleware to the relative position of the middleware group.
 // Returns an error if the middleware already exists.
 func (s *DeserializeStep) Add(m DeserializeMiddleware, pos RelativePosition) error {
@@ -143,6 +149,14 @@ func (s *DeserializeStep) Insert(m DeserializeMiddleware, relativeTo string, pos
 	return s.ids.Insert(m, relativeTo, pos)
 }
 
+// InsertAt injects the middleware at the index of the step's middleware. A
+// negative index counts from the end, (e.g. -1 places the middleware last).
+// Returns an error if the index is out of range, or the middleware already
+// exists.
+func (s *DeserializeStep) InsertAt(m DeserializeMiddleware, index int) error {
+	return s.ids.InsertAt(m, index)
+}
+
 // Swap removes the middleware by id, replacing it with the new middleware.
 // Returns the middleware removed, or error if the middleware to be removed
 // doesn't exist.
@@ -176,6 +190,14 @@ func (s *DeserializeStep) Clear() {
 	s.ids.Clear()
 }
 
+// clone returns a copy of the step whose middleware can be modified without
+// affecting the original step.
+func (s *DeserializeStep) clone() *DeserializeStep {
+	return &DeserializeStep{
+		ids: s.ids.clone(),
+	}
+}
+
 type deserializeWrapHandler struct {
 	Next Handler
 }
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockDeserializeResponse struct {
+	Body string
+}
+
+type mockDeserializeResult struct {
+	Message string
+}
+
+func TestDeserializeStep(t *testing.T) {
+	var order []string
+
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	noError(t, s.Deserialize.Add(DeserializeMiddlewareFunc("outer",
+		func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (DeserializeOutput, Metadata, error) {
+			out, metadata, err := next.HandleDeserialize(ctx, in)
+			order = append(order, "outer")
+			return out, metadata, err
+		}), After))
+	noError(t, s.Deserialize.Add(DeserializeMiddlewareFunc("decode",
+		func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (DeserializeOutput, Metadata, error) {
+			out, metadata, err := next.HandleDeserialize(ctx, in)
+			order = append(order, "decode")
+			if err != nil {
+				return out, metadata, err
+			}
+			resp := out.RawResponse.(*mockDeserializeResponse)
+			out.Result = &mockDeserializeResult{Message: resp.Body}
+			return out, metadata, nil
+		}), After))
+	noError(t, s.Finalize.Add(FinalizeMiddlewareFunc("finalize",
+		func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (FinalizeOutput, Metadata, error) {
+			out, metadata, err := next.HandleFinalize(ctx, in)
+			order = append(order, "finalize")
+			return out, metadata, err
+		}), After))
+
+	var request interface{}
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		order = append(order, "handler")
+		request = input
+		return &mockDeserializeResponse{Body: "hello"}, Metadata{}, nil
+	})
+
+	result, _, err := DecorateHandler(handler, s).Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if request == nil {
+		t.Errorf("expect request passed to handler")
+	}
+	if diff := cmp.Diff([]string{"handler", "decode", "outer", "finalize"}, order); len(diff) != 0 {
+		t.Errorf("expect deserialize middleware to see response in reverse order\n%s", diff)
+	}
+	if diff := cmp.Diff(&mockDeserializeResult{Message: "hello"}, result); len(diff) != 0 {
+		t.Errorf("expect deserialized result\n%s", diff)
+	}
+}
+
+func TestDeserializeStep_Modify(t *testing.T) {
+	s := NewDeserializeStep()
+
+	noError(t, s.Add(mockDeserializeMiddleware("a"), After))
+	noError(t, s.Add(mockDeserializeMiddleware("c"), After))
+	noError(t, s.Insert(mockDeserializeMiddleware("b"), "c", Before))
+	if _, err := s.Swap("c", mockDeserializeMiddleware("d")); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if _, err := s.Remove("a"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"b", "d"}, s.List()); len(diff) != 0 {
+		t.Errorf("expect deserialize step middleware\n%s", diff)
+	}
+
+	s.Clear()
+	if e, a := 0, len(s.List()); e != a {
+		t.Errorf("expect %v middleware after clear, got %v", e, a)
+	}
+}
@@ -124,6 +124,12 @@ func (s *FinalizeStep) Get(id string) (FinalizeMiddleware, bool) {
 	return get.(FinalizeMiddleware), ok
 }
 
+// Has returns if the middleware identified by id is present in the step. Add
+// returns an error for middleware that is already present.
+func (s *FinalizeStep) Has(id string) bool {
+	return s.ids.Has(id)
+}
+
 // Add injects the middleware to the relative position of the middleware group.
 // Returns an error if the middleware already exists.
 func (s *FinalizeStep) Add(m FinalizeMiddleware, pos RelativePosition) error {
@@ -137,6 +143,14 @@ func (s *FinalizeStep) Insert(m FinalizeMiddleware, relativeTo string, pos Relat
 	return s.ids.Insert(m, relativeTo, pos)
 }
 
+// InsertAt injects the middleware at the index of the step's middleware. A
+// negative index counts from the end, (e.g. -1 places the middleware last).
+// Returns an error if the index is out of range, or the middleware already
+// exists.
+func (s *FinalizeStep) InsertAt(m FinalizeMiddleware, index int) error {
+	return s.ids.InsertAt(m, index)
+}
+
 // Swap removes the middleware by id, replacing it with the new middleware.
 // Returns the middleware removed, or error if the middleware to be removed
 // doesn't exist.
@@ -170,6 +184,14 @@ func (s *FinalizeStep) Clear() {
 	s.ids.Clear()
 }
 
+// clone returns a copy of the step whose middleware can be modified without
+// affecting the original step.
+func (s *FinalizeStep) clone() *FinalizeStep {
+	return &FinalizeStep{
+		ids: s.ids.clone(),
+	}
+}
+
 type finalizeWrapHandler struct {
 	Next Handler
 }
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockFinalizeRequest struct {
+	Headers map[string]string
+}
+
+func TestFinalizeStep(t *testing.T) {
+	var order []string
+
+	s := NewStack("fooStack", func() interface{} {
+		return &mockFinalizeRequest{Headers: map[string]string{}}
+	})
+	noError(t, s.Build.Add(BuildMiddlewareFunc("build",
+		func(ctx context.Context, in BuildInput, next BuildHandler) (BuildOutput, Metadata, error) {
+			order = append(order, "build")
+			in.Request.(*mockFinalizeRequest).Headers["Content-Length"] = "3"
+			return next.HandleBuild(ctx, in)
+		}), After))
+
+	var signedHeaders map[string]string
+	noError(t, s.Finalize.Add(FinalizeMiddlewareFunc("sign",
+		func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (FinalizeOutput, Metadata, error) {
+			order = append(order, "finalize")
+			req := in.Request.(*mockFinalizeRequest)
+			signedHeaders = map[string]string{}
+			for k, v := range req.Headers {
+				signedHeaders[k] = v
+			}
+			req.Headers["Authorization"] = "signature"
+			return next.HandleFinalize(ctx, in)
+		}), After))
+	noError(t, s.Deserialize.Add(DeserializeMiddlewareFunc("deserialize",
+		func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (DeserializeOutput, Metadata, error) {
+			order = append(order, "deserialize")
+			return next.HandleDeserialize(ctx, in)
+		}), After))
+
+	var sent *mockFinalizeRequest
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		order = append(order, "handler")
+		sent = input.(*mockFinalizeRequest)
+		return nil, Metadata{}, nil
+	})
+
+	if _, _, err := DecorateHandler(handler, s).Handle(context.Background(), struct{}{}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"build", "finalize", "deserialize", "handler"}, order); len(diff) != 0 {
+		t.Errorf("expect finalize step invoked after build\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"Content-Length": "3"}, signedHeaders); len(diff) != 0 {
+		t.Errorf("expect finalize step to see built request\n%s", diff)
+	}
+	expectSent := map[string]string{"Content-Length": "3", "Authorization": "signature"}
+	if diff := cmp.Diff(expectSent, sent.Headers); len(diff) != 0 {
+		t.Errorf("expect finalized request sent\n%s", diff)
+	}
+}
@@ -124,6 +124,12 @@ func (s *InitializeStep) Get(id string) (InitializeMiddleware, bool) {
 	return get.(InitializeMiddleware), ok
 }
 
+// Has returns if the middleware identified by id is present in the step. Add
+// returns an error for middleware that is already present.
+func (s *InitializeStep) Has(id string) bool {
+	return s.ids.Has(id)
+}
+
 // Add injects the middleware to the relative position of the middleware group.
 // Returns an error if the middleware already exists.
 func (s *InitializeStep) Add(m InitializeMiddleware, pos RelativePosition) error {
@@ -137,6 +143,14 @@ func (s *InitializeStep) Insert(m InitializeMiddleware, relativeTo string, pos R
 	return s.ids.Insert(m, relativeTo, pos)
 }
 
+// InsertAt injects the middleware at the index of the step's middleware. A
+// negative index counts from the end, (e.g. -1 places the middleware last).
+// Returns an error if the index is out of range, or the middleware already
+// exists.
+func (s *InitializeStep) InsertAt(m InitializeMiddleware, index int) error {
+	return s.ids.InsertAt(m, index)
+}
+
 // Swap removes the middleware by id, replacing it with the new middleware.
 // Returns the middleware removed, or error if the middleware to be removed
 // doesn't exist.
@@ -170,6 +184,14 @@ func (s *InitializeStep) Clear() {
 	s.ids.Clear()
 }
 
+// clone returns a copy of the step whose middleware can be modified without
+// affecting the original step.
+func (s *InitializeStep) clone() *InitializeStep {
+	return &InitializeStep{
+		ids: s.ids.clone(),
+	}
+}
+
 type initializeWrapHandler struct {
 	Next Handler
 }
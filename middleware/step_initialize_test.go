@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockInitializeParams struct {
+	Name    string
+	Retries int
+}
+
+func TestInitializeStep(t *testing.T) {
+	var order []string
+	record := func(step string) {
+		order = append(order, step)
+	}
+
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	noError(t, s.Initialize.Add(InitializeMiddlewareFunc("setDefaults",
+		func(ctx context.Context, in InitializeInput, next InitializeHandler) (InitializeOutput, Metadata, error) {
+			record("initialize")
+			params := in.Parameters.(*mockInitializeParams)
+			if params.Retries == 0 {
+				params.Retries = 3
+			}
+			return next.HandleInitialize(ctx, in)
+		}), After))
+	noError(t, s.Serialize.Add(SerializeMiddlewareFunc("serialize",
+		func(ctx context.Context, in SerializeInput, next SerializeHandler) (SerializeOutput, Metadata, error) {
+			record("serialize")
+			return next.HandleSerialize(ctx, in)
+		}), After))
+	noError(t, s.Build.Add(BuildMiddlewareFunc("build",
+		func(ctx context.Context, in BuildInput, next BuildHandler) (BuildOutput, Metadata, error) {
+			record("build")
+			return next.HandleBuild(ctx, in)
+		}), After))
+
+	var serialized *mockInitializeParams
+	noError(t, s.Serialize.Add(SerializeMiddlewareFunc("captureParams",
+		func(ctx context.Context, in SerializeInput, next SerializeHandler) (SerializeOutput, Metadata, error) {
+			serialized = in.Parameters.(*mockInitializeParams)
+			return next.HandleSerialize(ctx, in)
+		}), After))
+
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		record("handler")
+		return nil, Metadata{}, nil
+	})
+
+	params := &mockInitializeParams{Name: "abc"}
+	if _, _, err := DecorateHandler(handler, s).Handle(context.Background(), params); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"initialize", "serialize", "build", "handler"}, order); len(diff) != 0 {
+		t.Errorf("expect initialize step invoked first\n%s", diff)
+	}
+	if diff := cmp.Diff(&mockInitializeParams{Name: "abc", Retries: 3}, serialized); len(diff) != 0 {
+		t.Errorf("expect defaulted parameters serialized\n%s", diff)
+	}
+}
+
+func TestInitializeStep_Modify(t *testing.T) {
+	s := NewInitializeStep()
+
+	noError(t, s.Add(mockInitializeMiddleware("a"), After))
+	noError(t, s.Add(mockInitializeMiddleware("c"), After))
+	noError(t, s.Insert(mockInitializeMiddleware("b"), "c", Before))
+	if _, err := s.Swap("c", mockInitializeMiddleware("d")); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if _, err := s.Remove("a"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"b", "d"}, s.List()); len(diff) != 0 {
+		t.Errorf("expect initialize step middleware\n%s", diff)
+	}
+
+	s.Clear()
+	if e, a := 0, len(s.List()); e != a {
+		t.Errorf("expect %v middleware after clear, got %v", e, a)
+	}
+}
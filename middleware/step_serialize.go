@@ -132,6 +132,12 @@ func (s *SerializeStep) Get(id string) (SerializeMiddleware, bool) {
 	return get.(SerializeMiddleware), ok
 }
 
+// Has returns if the middleware identified by id is present in the step. Add
+// returns an error for middleware that is already present.
+func (s *SerializeStep) Has(id string) bool {
+	return s.ids.Has(id)
+}
+
 // Add injects the middleware to the relative position of the middleware group.
 // Returns an error if the middleware already exists.
 func (s *SerializeStep) Add(m SerializeMiddleware, pos RelativePosition) error {
@@ -145,6 +151,14 @@ func (s *SerializeStep) Insert(m SerializeMiddleware, relativeTo string, pos Rel
 	return s.ids.Insert(m, relativeTo, pos)
 }
 
+// InsertAt injects the middleware at the index of the step's middleware. A
+// negative index counts from the end, (e.g. -1 places the middleware last).
+// Returns an error if the index is out of range, or the middleware already
+// exists.
+func (s *SerializeStep) InsertAt(m SerializeMiddleware, index int) error {
+	return s.ids.InsertAt(m, index)
+}
+
 // Swap removes the middleware by id, replacing it with the new middleware.
 // Returns the middleware removed, or error if the middleware to be removed
 // doesn't exist.
@@ -178,6 +192,15 @@ func (s *SerializeStep) Clear() {
 	s.ids.Clear()
 }
 
+// clone returns a copy of the step whose middleware can be modified without
+// affecting the original step.
+func (s *SerializeStep) clone() *SerializeStep {
+	return &SerializeStep{
+		ids:        s.ids.clone(),
+		newRequest: s.newRequest,
+	}
+}
+
 type serializeWrapHandler struct {
 	Next Handler
 }
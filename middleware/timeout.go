@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeoutError is the error returned by the Timeout middleware when the next
+// handler does not complete before the timeout.
+type TimeoutError struct {
+	// The timeout the handler did not complete within.
+	Timeout time.Duration
+
+	// The error returned by the handler, if any.
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("handler did not complete within %v", e.Timeout)
+	}
+	return fmt.Sprintf("handler did not complete within %v, %v", e.Timeout, e.Err)
+}
+
+// Is returns true for context.DeadlineExceeded, so that the error can be
+// identified with errors.Is.
+func (e *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// Unwrap returns the error returned by the handler, if any.
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Timeout is a middleware that bounds the duration of the next handler. The
+// handler is invoked with a context derived from the incoming context, that
+// is canceled when the timeout elapses. If the handler has not completed
+// when the timeout elapses, a TimeoutError is returned.
+//
+// Timeout implements Middleware, and the middleware interface of each stack
+// step, so that it can be added to any step, (e.g. the front of the
+// Initialize step to bound the whole operation). The derived context is
+// canceled when the handler returns, unless a middleware deferred the
+// cancellation with DeferTimeoutCancel, (e.g. to read a streaming response
+// body after the operation returns). Use
+// transport/http#AddBoundResponseBodyReadMiddleware to defer the cancellation
+// until the response body is closed.
+//
+// To bound a whole operation, use transport/http#AddOperationTimeoutMiddleware
+// instead, which can also bound reading the response body after the operation
+// returns. Timeout bounds individual steps, or handlers.
+type Timeout struct {
+	timeout time.Duration
+}
+
+// NewTimeout returns an initialized Timeout middleware bounding the next
+// handler by the timeout.
+func NewTimeout(timeout time.Duration) *Timeout {
+	return &Timeout{timeout: timeout}
+}
+
+// ID returns the middleware identifier.
+func (*Timeout) ID() string { return "Timeout" }
+
+// HandleMiddleware invokes the next handler bounded by the timeout.
+func (m *Timeout) HandleMiddleware(ctx context.Context, input interface{}, next Handler) (
+	output interface{}, metadata Metadata, err error,
+) {
+	tctx, c := m.start(ctx)
+	output, metadata, err = next.Handle(tctx, input)
+	return output, metadata, m.finish(ctx, c, err)
+}
+
+// HandleInitialize invokes the next handler bounded by the timeout.
+func (m *Timeout) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	tctx, c := m.start(ctx)
+	out, metadata, err = next.HandleInitialize(tctx, in)
+	return out, metadata, m.finish(ctx, c, err)
+}
+
+// HandleSerialize invokes the next handler bounded by the timeout.
+func (m *Timeout) HandleSerialize(ctx context.Context, in SerializeInput, next SerializeHandler) (
+	out SerializeOutput, metadata Metadata, err error,
+) {
+	tctx, c := m.start(ctx)
+	out, metadata, err = next.HandleSerialize(tctx, in)
+	return out, metadata, m.finish(ctx, c, err)
+}
+
+// HandleBuild invokes the next handler bounded by the timeout.
+func (m *Timeout) HandleBuild(ctx context.Context, in BuildInput, next BuildHandler) (
+	out BuildOutput, metadata Metadata, err error,
+) {
+	tctx, c := m.start(ctx)
+	out, metadata, err = next.HandleBuild(tctx, in)
+	return out, metadata, m.finish(ctx, c, err)
+}
+
+// HandleFinalize invokes the next handler bounded by the timeout.
+func (m *Timeout) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	tctx, c := m.start(ctx)
+	out, metadata, err = next.HandleFinalize(tctx, in)
+	return out, metadata, m.finish(ctx, c, err)
+}
+
+// HandleDeserialize invokes the next handler bounded by the timeout.
+func (m *Timeout) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	tctx, c := m.start(ctx)
+	out, metadata, err = next.HandleDeserialize(tctx, in)
+	return out, metadata, m.finish(ctx, c, err)
+}
+
+// start returns the context bounded by the timeout for the next handler.
+func (m *Timeout) start(ctx context.Context) (context.Context, *timeoutCancel) {
+	tctx, cancel := context.WithTimeout(ctx, m.timeout)
+
+	c := &timeoutCancel{ctx: tctx, cancel: cancel}
+	c.parent, _ = GetStackValue(ctx, timeoutCancelKey{}).(*timeoutCancel)
+
+	return WithStackValue(tctx, timeoutCancelKey{}, c), c
+}
+
+// finish returns the handler's error, wrapped in a TimeoutError if the
+// timeout elapsed, and cancels the derived context unless its cancellation
+// was deferred.
+func (m *Timeout) finish(ctx context.Context, c *timeoutCancel, err error) error {
+	err = m.timeoutErr(ctx, c.ctx, err)
+	c.handlerReturned(err)
+	return err
+}
+
+// timeoutErr returns a TimeoutError if the timeout of the derived context
+// elapsed, wrapping the handler's error. If the handler completed without
+// error, or the incoming context is done, (e.g. canceled by the caller, or its
+// own deadline was exceeded), the handler's error is returned as is.
+func (m *Timeout) timeoutErr(ctx, tctx context.Context, err error) error {
+	if err == nil || tctx.Err() == nil || ctx.Err() != nil {
+		return err
+	}
+	return &TimeoutError{Timeout: m.timeout, Err: err}
+}
+
+type timeoutCancelKey struct{}
+
+// timeoutCancel is the cancellation of a Timeout middleware's context, that
+// is deferred while any middleware holds it.
+type timeoutCancel struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// The cancellation of the enclosing Timeout middleware, if any.
+	parent *timeoutCancel
+
+	mu       sync.Mutex
+	holds    int
+	returned bool
+}
+
+func (c *timeoutCancel) hold() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.holds++
+}
+
+func (c *timeoutCancel) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.holds--
+	if c.holds == 0 && c.returned {
+		c.cancel()
+	}
+}
+
+func (c *timeoutCancel) handlerReturned(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.returned = true
+	if err != nil || c.holds == 0 {
+		c.cancel()
+	}
+}
+
+// DeferTimeoutCancel defers the cancellation of the contexts of the Timeout
+// middleware handling ctx, until the returned CancelFunc is called. Returns
+// the context of the innermost Timeout middleware, that is still canceled
+// when the timeout elapses, (e.g. to bound reading a streaming response body
+// after the operation returns). Returns false if ctx is not handled by a
+// Timeout middleware.
+//
+// The cancellation is only deferred if the handler completes without error.
+// The returned CancelFunc must be called to release the contexts.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func DeferTimeoutCancel(ctx context.Context) (context.Context, context.CancelFunc, bool) {
+	c, ok := GetStackValue(ctx, timeoutCancelKey{}).(*timeoutCancel)
+	if !ok {
+		return nil, nil, false
+	}
+
+	for p := c; p != nil; p = p.parent {
+		p.hold()
+	}
+
+	var once sync.Once
+	return c.ctx, func() {
+		once.Do(func() {
+			for p := c; p != nil; p = p.parent {
+				p.release()
+			}
+		})
+	}, true
+}
+
+var (
+	_ Middleware            = (*Timeout)(nil)
+	_ InitializeMiddleware  = (*Timeout)(nil)
+	_ SerializeMiddleware   = (*Timeout)(nil)
+	_ BuildMiddleware       = (*Timeout)(nil)
+	_ FinalizeMiddleware    = (*Timeout)(nil)
+	_ DeserializeMiddleware = (*Timeout)(nil)
+)
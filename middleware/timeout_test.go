@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	cases := map[string]struct {
+		Timeout       time.Duration
+		Handler       func(ctx context.Context) error
+		Cancel        bool
+		ExpectErr     bool
+		ExpectTimeout bool
+	}{
+		"completes in time": {
+			Timeout: time.Second,
+			Handler: func(ctx context.Context) error { return nil },
+		},
+		"times out": {
+			Timeout: 10 * time.Millisecond,
+			Handler: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			ExpectErr:     true,
+			ExpectTimeout: true,
+		},
+		"completes after timeout": {
+			Timeout: 10 * time.Millisecond,
+			Handler: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		},
+		"handler error": {
+			Timeout: time.Second,
+			Handler: func(ctx context.Context) error {
+				return errors.New("handler error")
+			},
+			ExpectErr: true,
+		},
+		"caller canceled": {
+			Timeout: time.Second,
+			Handler: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			Cancel:    true,
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			if err := s.Initialize.Add(NewTimeout(c.Timeout), Before); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var hasDeadline bool
+			handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+				_, hasDeadline = ctx.Deadline()
+				return nil, Metadata{}, c.Handler(ctx)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if c.Cancel {
+				cancel()
+			}
+
+			_, _, err := DecorateHandler(handler, s).Handle(ctx, struct{}{})
+			if !hasDeadline {
+				t.Errorf("expect handler context to have deadline")
+			}
+
+			if !c.ExpectErr {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expect error, got none")
+			}
+
+			var timeoutErr *TimeoutError
+			if e, a := c.ExpectTimeout, errors.As(err, &timeoutErr); e != a {
+				t.Errorf("expect timeout error %v, got %v", e, err)
+			}
+			if c.ExpectTimeout {
+				if e, a := c.Timeout, timeoutErr.Timeout; e != a {
+					t.Errorf("expect %v timeout, got %v", e, a)
+				}
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expect error to be deadline exceeded, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeout_Middleware(t *testing.T) {
+	handler := DecorateHandler(
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			<-ctx.Done()
+			return nil, Metadata{}, ctx.Err()
+		}),
+		NewTimeout(10*time.Millisecond),
+	)
+
+	_, _, err := handler.Handle(context.Background(), struct{}{})
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expect %T error, got %v", timeoutErr, err)
+	}
+}
+
+type mockTimeoutCauseError struct{}
+
+func (*mockTimeoutCauseError) Error() string { return "connection reset" }
+
+func TestTimeoutError_Unwrap(t *testing.T) {
+	handler := DecorateHandler(
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			<-ctx.Done()
+			return nil, Metadata{}, fmt.Errorf("read failed, %w", &mockTimeoutCauseError{})
+		}),
+		NewTimeout(10*time.Millisecond),
+	)
+
+	_, _, err := handler.Handle(context.Background(), struct{}{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect error to be deadline exceeded, got %v", err)
+	}
+	var causeErr *mockTimeoutCauseError
+	if !errors.As(err, &causeErr) {
+		t.Errorf("expect handler's error to be unwrapped, got %v", err)
+	}
+}
+
+func TestDeferTimeoutCancel(t *testing.T) {
+	cases := map[string]struct {
+		Defer     bool
+		HandleErr error
+		ExpectErr bool
+		// If the handler's context is expected to be canceled when the
+		// handler returns.
+		ExpectCanceled bool
+	}{
+		"not deferred": {
+			ExpectCanceled: true,
+		},
+		"deferred": {
+			Defer: true,
+		},
+		"deferred handler error": {
+			Defer:          true,
+			HandleErr:      errors.New("handler error"),
+			ExpectErr:      true,
+			ExpectCanceled: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var handlerCtx context.Context
+			var release context.CancelFunc
+			handler := DecorateHandler(
+				HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+					handlerCtx = ctx
+					if c.Defer {
+						var ok bool
+						if _, release, ok = DeferTimeoutCancel(ctx); !ok {
+							t.Fatalf("expect timeout cancel to be deferred")
+						}
+					}
+					return nil, Metadata{}, c.HandleErr
+				}),
+				NewTimeout(time.Minute),
+				NewTimeout(time.Minute),
+			)
+
+			_, _, err := handler.Handle(context.Background(), struct{}{})
+			if e, a := c.ExpectErr, err != nil; e != a {
+				t.Fatalf("expect error %v, got %v", e, err)
+			}
+			if e, a := c.ExpectCanceled, handlerCtx.Err() != nil; e != a {
+				t.Errorf("expect context canceled %v, got %v", e, handlerCtx.Err())
+			}
+
+			if release == nil {
+				return
+			}
+			release()
+			if handlerCtx.Err() == nil {
+				t.Errorf("expect context canceled after release")
+			}
+		})
+	}
+}
+
+func TestDeferTimeoutCancel_NoTimeout(t *testing.T) {
+	if _, _, ok := DeferTimeoutCancel(context.Background()); ok {
+		t.Errorf("expect no timeout cancel to defer")
+	}
+}
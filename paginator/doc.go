@@ -0,0 +1,6 @@
+// Package paginator provides a generic paginator for operations whose output
+// is split across pages linked by a continuation token.
+//
+// Generated operation paginators wrap the Paginator with the operation's
+// input and output types.
+package paginator
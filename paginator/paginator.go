@@ -0,0 +1,147 @@
+package paginator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+)
+
+// DefaultMaxPages is the default maximum number of pages All will collect.
+const DefaultMaxPages = 1000
+
+// FetchPageFunc retrieves the page of the operation's output for the
+// continuation token, returning the page and the token of the next page. The
+// token is nil for the first page. A nil or empty next token indicates there
+// are no more pages.
+type FetchPageFunc func(ctx context.Context, token *string) (page interface{}, nextToken *string, err error)
+
+// Options provides the options for a Paginator.
+type Options struct {
+	// The maximum number of pages All will collect. Defaults to
+	// DefaultMaxPages. If zero or less, the number of pages is not limited.
+	MaxPages int
+
+	// The maximum number of items All will collect across all pages. If zero
+	// or less, the number of items is not limited. Requires ItemCount.
+	//
+	// Pages are not split, so the last page collected may take the number of
+	// items over MaxItems, see Paginator.All.
+	MaxItems int
+
+	// Returns the number of items in a page. Required if MaxItems is set.
+	ItemCount func(page interface{}) int
+
+	// Sets if the paginator should stop, instead of returning an error, if
+	// the service returns the same continuation token it was sent.
+	StopOnDuplicateToken bool
+}
+
+// Paginator iterates over the pages of an operation's output.
+type Paginator struct {
+	options Options
+	fetch   FetchPageFunc
+
+	nextToken *string
+	firstPage bool
+}
+
+// New returns an initialized Paginator that retrieves pages with fetch.
+func New(fetch FetchPageFunc, optFns ...func(*Options)) *Paginator {
+	options := Options{
+		MaxPages: DefaultMaxPages,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return &Paginator{
+		options:   options,
+		fetch:     fetch,
+		firstPage: true,
+	}
+}
+
+// HasMorePages returns if there are more pages to be retrieved.
+func (p *Paginator) HasMorePages() bool {
+	return p.firstPage || (p.nextToken != nil && len(*p.nextToken) != 0)
+}
+
+// NextPage retrieves the next page of the operation's output.
+func (p *Paginator) NextPage(ctx context.Context) (interface{}, error) {
+	if !p.HasMorePages() {
+		return nil, fmt.Errorf("no more pages available")
+	}
+
+	prevToken := p.nextToken
+	page, nextToken, err := p.fetch(ctx, prevToken)
+	if err != nil {
+		return nil, err
+	}
+
+	p.firstPage = false
+	p.nextToken = nextToken
+
+	if prevToken != nil && nextToken != nil && *prevToken == *nextToken {
+		if !p.options.StopOnDuplicateToken {
+			return page, fmt.Errorf("duplicate pagination token %q returned", *nextToken)
+		}
+		p.nextToken = nil
+	}
+
+	return page, nil
+}
+
+// All retrieves all remaining pages of the operation's output. Context
+// cancellation is checked before each page is retrieved, returning a
+// smithy.CanceledError if the context is done.
+//
+// If more pages remain once the paginator's MaxPages or MaxItems limit is
+// reached, the pages collected so far are returned with a
+// LimitExceededError. Pages are not split, so if a page takes the number of
+// items over MaxItems, the page is included in the pages returned. If the
+// page is the last page, no error is returned.
+func (p *Paginator) All(ctx context.Context) ([]interface{}, error) {
+	var pages []interface{}
+	var items int
+	limitItems := p.options.MaxItems > 0 && p.options.ItemCount != nil
+
+	for p.HasMorePages() {
+		if err := ctx.Err(); err != nil {
+			return pages, &smithy.CanceledError{Err: err}
+		}
+
+		if p.options.MaxPages > 0 && len(pages) >= p.options.MaxPages {
+			return pages, &LimitExceededError{Limit: "pages", Max: p.options.MaxPages}
+		}
+		if limitItems && items >= p.options.MaxItems {
+			return pages, &LimitExceededError{Limit: "items", Max: p.options.MaxItems}
+		}
+
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return pages, err
+		}
+		pages = append(pages, page)
+
+		if limitItems {
+			items += p.options.ItemCount(page)
+		}
+	}
+
+	return pages, nil
+}
+
+// LimitExceededError is returned by All if collecting the pages would exceed
+// the paginator's limit on the number of pages or items.
+type LimitExceededError struct {
+	// The limit exceeded, either "pages" or "items".
+	Limit string
+
+	// The maximum value of the limit.
+	Max int
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("pagination exceeded maximum %d %s", e.Max, e.Limit)
+}
@@ -0,0 +1,193 @@
+package paginator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockPage struct {
+	Items []string
+}
+
+func mockFetch(pages []mockPage, tokens []string) FetchPageFunc {
+	return func(ctx context.Context, token *string) (interface{}, *string, error) {
+		i := 0
+		if token != nil {
+			for j, t := range tokens {
+				if t == *token {
+					i = j + 1
+				}
+			}
+		}
+		if i >= len(pages) {
+			return nil, nil, fmt.Errorf("unexpected token %v", *token)
+		}
+
+		var next *string
+		if i < len(tokens) {
+			next = ptr.String(tokens[i])
+		}
+		return pages[i], next, nil
+	}
+}
+
+func TestPaginatorAll(t *testing.T) {
+	pages := []mockPage{
+		{Items: []string{"a", "b"}},
+		{Items: []string{"c"}},
+		{Items: []string{"d", "e"}},
+	}
+
+	cases := map[string]struct {
+		Options     func(*Options)
+		Expect      []interface{}
+		ExpectLimit *LimitExceededError
+	}{
+		"all pages": {
+			Options: func(*Options) {},
+			Expect:  []interface{}{pages[0], pages[1], pages[2]},
+		},
+		"page cap": {
+			Options: func(o *Options) {
+				o.MaxPages = 2
+			},
+			Expect:      []interface{}{pages[0], pages[1]},
+			ExpectLimit: &LimitExceededError{Limit: "pages", Max: 2},
+		},
+		"no page cap": {
+			Options: func(o *Options) {
+				o.MaxPages = 0
+			},
+			Expect: []interface{}{pages[0], pages[1], pages[2]},
+		},
+		"item cap reached": {
+			Options: func(o *Options) {
+				o.MaxItems = 2
+				o.ItemCount = func(page interface{}) int {
+					return len(page.(mockPage).Items)
+				}
+			},
+			Expect:      []interface{}{pages[0]},
+			ExpectLimit: &LimitExceededError{Limit: "items", Max: 2},
+		},
+		"item cap exceeded with more pages": {
+			Options: func(o *Options) {
+				o.MaxItems = 1
+				o.ItemCount = func(page interface{}) int {
+					return len(page.(mockPage).Items)
+				}
+			},
+			Expect:      []interface{}{pages[0]},
+			ExpectLimit: &LimitExceededError{Limit: "items", Max: 1},
+		},
+		"item cap exceeded by last page": {
+			Options: func(o *Options) {
+				o.MaxItems = 4
+				o.ItemCount = func(page interface{}) int {
+					return len(page.(mockPage).Items)
+				}
+			},
+			Expect: []interface{}{pages[0], pages[1], pages[2]},
+		},
+		"item cap reached on last page": {
+			Options: func(o *Options) {
+				o.MaxItems = 5
+				o.ItemCount = func(page interface{}) int {
+					return len(page.(mockPage).Items)
+				}
+			},
+			Expect: []interface{}{pages[0], pages[1], pages[2]},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New(mockFetch(pages, []string{"token1", "token2"}), c.Options)
+
+			actual, err := p.All(context.Background())
+			if c.ExpectLimit != nil {
+				var limitErr *LimitExceededError
+				if !errors.As(err, &limitErr) {
+					t.Fatalf("expect %T error, got %v", limitErr, err)
+				}
+				if diff := cmp.Diff(c.ExpectLimit, limitErr); len(diff) != 0 {
+					t.Errorf("expect limit error to match\n%s", diff)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.Expect, actual); len(diff) != 0 {
+				t.Errorf("expect pages to match\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPaginatorAll_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var fetched int
+	p := New(func(ctx context.Context, token *string) (interface{}, *string, error) {
+		fetched++
+		cancel()
+		return mockPage{}, ptr.String(fmt.Sprintf("token%d", fetched)), nil
+	})
+
+	pages, err := p.All(ctx)
+	var canceledErr *smithy.CanceledError
+	if !errors.As(err, &canceledErr) {
+		t.Fatalf("expect %T error, got %v", canceledErr, err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect %v error, got %v", context.Canceled, err)
+	}
+	if e, a := 1, len(pages); e != a {
+		t.Errorf("expect %v pages, got %v", e, a)
+	}
+}
+
+func TestPaginatorNextPage_duplicateToken(t *testing.T) {
+	cases := map[string]struct {
+		StopOnDuplicate bool
+		ExpectErr       bool
+	}{
+		"error": {
+			ExpectErr: true,
+		},
+		"stop": {
+			StopOnDuplicate: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New(func(ctx context.Context, token *string) (interface{}, *string, error) {
+				return mockPage{}, ptr.String("same"), nil
+			}, func(o *Options) {
+				o.StopOnDuplicateToken = c.StopOnDuplicate
+			})
+
+			pages, err := p.All(context.Background())
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := 2, len(pages); e != a {
+				t.Errorf("expect %v pages, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Default values used by the AdaptiveRetryer when not otherwise configured.
+const (
+	DefaultAdaptiveMinFillRate   = 0.5
+	DefaultAdaptiveRateBeta      = 0.7
+	DefaultAdaptiveRateIncrement = 0.5
+)
+
+// AdaptiveOptions provides the configuration options for the AdaptiveRetryer.
+type AdaptiveOptions struct {
+	// The retryer that classifies errors, and computes the delay between
+	// attempts. Defaults to a Standard retryer.
+	Retryer Retryer
+
+	// The minimum rate, in attempts per second, the client rate limiter may
+	// be reduced to. Defaults to DefaultAdaptiveMinFillRate.
+	MinFillRate float64
+
+	// The factor the send rate is multiplied by when an attempt is
+	// throttled. Defaults to DefaultAdaptiveRateBeta.
+	Beta float64
+
+	// The amount, in attempts per second, the send rate is increased by
+	// when an attempt is not throttled. Defaults to
+	// DefaultAdaptiveRateIncrement.
+	RateIncrement float64
+}
+
+// AdaptiveRetryer is a Retryer that, in addition to retrying failed attempts,
+// limits the rate the client sends attempts at when the service throttles
+// the client. Each attempt must acquire a token from a client side token
+// bucket, see AttemptRateLimiter.
+//
+// The token bucket is not enabled until the first throttled attempt. When
+// an attempt is throttled, the bucket's fill rate is decreased
+// multiplicatively from the client's measured send rate. When an attempt is
+// not throttled the fill rate is increased additively, up to twice the
+// measured send rate.
+//
+// An AdaptiveRetryer is safe for concurrent use, and should be shared by all
+// operations sent to the same service.
+type AdaptiveRetryer struct {
+	Retryer
+
+	options AdaptiveOptions
+	sleep   func(context.Context, time.Duration) error
+
+	mu              sync.Mutex
+	enabled         bool
+	fillRate        float64
+	capacity        float64
+	maxCapacity     float64
+	lastRefill      time.Time
+	measuredRate    float64
+	lastRateBucket  float64
+	rateBucketCount int
+}
+
+// NewAdaptive returns an initialized AdaptiveRetryer, with the optional
+// functional options applied.
+func NewAdaptive(optFns ...func(*AdaptiveOptions)) *AdaptiveRetryer {
+	options := AdaptiveOptions{
+		MinFillRate:   DefaultAdaptiveMinFillRate,
+		Beta:          DefaultAdaptiveRateBeta,
+		RateIncrement: DefaultAdaptiveRateIncrement,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	if options.Retryer == nil {
+		options.Retryer = NewStandard()
+	}
+
+	return &AdaptiveRetryer{
+		Retryer:        options.Retryer,
+		options:        options,
+		sleep:          sleepWithContext,
+		lastRateBucket: math.Floor(secondsOf(timeNow())*2) / 2,
+	}
+}
+
+// NewOperationRetryer returns the Retryer for the attempts of a single
+// operation from the wrapped retryer, if it is an OperationRetryer.
+// Otherwise, the wrapped retryer is returned.
+func (r *AdaptiveRetryer) NewOperationRetryer() Retryer {
+	if o, ok := r.Retryer.(OperationRetryer); ok {
+		return o.NewOperationRetryer()
+	}
+	return r.Retryer
+}
+
+// FillRate returns the rate, in attempts per second, the client is limited
+// to, and if the rate limiter is enabled.
+func (r *AdaptiveRetryer) FillRate() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.fillRate, r.enabled
+}
+
+// GetAttemptToken blocks until a token is available from the token bucket,
+// or the context is done. Returns immediately if the rate limiter is not
+// enabled.
+//
+// A token is taken from the bucket once the wait for the token completes,
+// even if other waiters have taken the refilled tokens first. The bucket's
+// capacity may become negative, delaying subsequent attempts further.
+func (r *AdaptiveRetryer) GetAttemptToken(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.enabled {
+		r.mu.Unlock()
+		return nil
+	}
+
+	r.refill(timeNow())
+	if r.capacity >= 1 {
+		r.capacity--
+		r.mu.Unlock()
+		return nil
+	}
+
+	wait := time.Duration((1 - r.capacity) / r.fillRate * float64(time.Second))
+	r.mu.Unlock()
+
+	if err := r.sleep(ctx, wait); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refill(timeNow())
+	r.capacity--
+	return nil
+}
+
+// RecordAttempt adjusts the fill rate of the token bucket by whether the
+// attempt was throttled.
+func (r *AdaptiveRetryer) RecordAttempt(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := timeNow()
+	r.updateMeasuredRate(now)
+
+	var rate float64
+	if err != nil && IsErrorThrottle(err) {
+		rate = r.measuredRate
+		if r.enabled {
+			rate = math.Min(rate, r.fillRate)
+		}
+		rate *= r.options.Beta
+		r.enabled = true
+	} else {
+		if !r.enabled {
+			return
+		}
+		rate = math.Min(r.fillRate+r.options.RateIncrement, 2*r.measuredRate)
+	}
+
+	r.refill(now)
+	r.fillRate = math.Max(rate, r.options.MinFillRate)
+	r.maxCapacity = math.Max(r.fillRate, 1)
+	r.capacity = math.Min(r.capacity, r.maxCapacity)
+}
+
+// refill adds the tokens accrued since the last refill to the bucket.
+func (r *AdaptiveRetryer) refill(now time.Time) {
+	if !r.lastRefill.IsZero() {
+		elapsed := now.Sub(r.lastRefill).Seconds()
+		r.capacity = math.Min(r.maxCapacity, r.capacity+elapsed*r.fillRate)
+	}
+	r.lastRefill = now
+}
+
+// updateMeasuredRate updates the smoothed rate attempts are sent at, measured
+// in half second buckets.
+func (r *AdaptiveRetryer) updateMeasuredRate(now time.Time) {
+	r.rateBucketCount++
+
+	bucket := math.Floor(secondsOf(now)*2) / 2
+	if bucket > r.lastRateBucket {
+		rate := float64(r.rateBucketCount) / (bucket - r.lastRateBucket)
+		r.measuredRate = rate*0.8 + r.measuredRate*0.2
+		r.rateBucketCount = 0
+		r.lastRateBucket = bucket
+	}
+}
+
+func secondsOf(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
@@ -0,0 +1,119 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestAdaptiveRetryer_ThrottlingReducesRate(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	retryer := NewAdaptive(func(o *AdaptiveOptions) {
+		o.Retryer = NewStandard(func(o *StandardOptions) {
+			o.MaxAttempts = 1
+		})
+	})
+	retryer.sleep = func(ctx context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return nil
+	}
+
+	m := NewAttemptMiddleware(retryer, func(v interface{}) interface{} { return v })
+
+	// Each attempt takes 50ms, for a send rate of 20 attempts per second
+	// without rate limiting. The first attempts succeed, and all following
+	// attempts are throttled.
+	const unthrottled, throttled = 20, 60
+	var sent []time.Time
+	next := middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+		middleware.FinalizeOutput, middleware.Metadata, error,
+	) {
+		sent = append(sent, now)
+		now = now.Add(50 * time.Millisecond)
+		if len(sent) <= unthrottled {
+			return middleware.FinalizeOutput{}, middleware.Metadata{}, nil
+		}
+		return middleware.FinalizeOutput{}, middleware.Metadata{}, mockResponseError(429, "Throttling")
+	})
+
+	for i := 0; i < unthrottled; i++ {
+		if _, _, err := m.HandleFinalize(context.Background(), middleware.FinalizeInput{}, next); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+	if _, enabled := retryer.FillRate(); enabled {
+		t.Fatalf("expect rate limiter to not be enabled before throttling")
+	}
+
+	var fillRates []float64
+	for i := 0; i < throttled; i++ {
+		if _, _, err := m.HandleFinalize(context.Background(), middleware.FinalizeInput{}, next); err == nil {
+			t.Fatalf("expect error, got none")
+		}
+		rate, enabled := retryer.FillRate()
+		if !enabled {
+			t.Fatalf("expect rate limiter enabled after throttle")
+		}
+		fillRates = append(fillRates, rate)
+	}
+
+	for i := 1; i < len(fillRates); i++ {
+		if fillRates[i] > fillRates[i-1] {
+			t.Errorf("expect fill rate to not increase while throttled, %v then %v",
+				fillRates[i-1], fillRates[i])
+		}
+	}
+	if e, a := DefaultAdaptiveMinFillRate, fillRates[len(fillRates)-1]; e != a {
+		t.Errorf("expect sustained throttling to reduce fill rate to %v, got %v", e, a)
+	}
+
+	// Compare the effective send rate of the unthrottled attempts, and the
+	// last throttled attempts.
+	effectiveRate := func(times []time.Time) float64 {
+		return float64(len(times)-1) / times[len(times)-1].Sub(times[0]).Seconds()
+	}
+	first := effectiveRate(sent[:unthrottled])
+	last := effectiveRate(sent[len(sent)-10:])
+	if last >= first/2 {
+		t.Errorf("expect effective rate to be reduced, first %v, last %v", first, last)
+	}
+	if last > DefaultAdaptiveMinFillRate*1.1 {
+		t.Errorf("expect effective rate near %v, got %v", DefaultAdaptiveMinFillRate, last)
+	}
+}
+
+func TestAdaptiveRetryer_SuccessIncreasesRate(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	retryer := NewAdaptive()
+	if _, enabled := retryer.FillRate(); enabled {
+		t.Fatalf("expect rate limiter to not be enabled before throttling")
+	}
+
+	for i := 0; i < 20; i++ {
+		now = now.Add(100 * time.Millisecond)
+		retryer.RecordAttempt(mockResponseError(429, "Throttling"))
+	}
+	throttled, _ := retryer.FillRate()
+
+	for i := 0; i < 5; i++ {
+		now = now.Add(100 * time.Millisecond)
+		retryer.RecordAttempt(nil)
+	}
+	recovered, _ := retryer.FillRate()
+
+	if recovered <= throttled {
+		t.Errorf("expect fill rate to increase after success, %v then %v", throttled, recovered)
+	}
+}
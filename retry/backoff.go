@@ -0,0 +1,60 @@
+package retry
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go/rand"
+)
+
+// ExponentialJitterBackoff provides backoff delays with full jitter. The delay
+// ceiling grows exponentially from the base delay with each attempt, up to the
+// max backoff. The delay returned is a random value between zero and the
+// ceiling.
+type ExponentialJitterBackoff struct {
+	baseDelay  time.Duration
+	maxBackoff time.Duration
+}
+
+// NewExponentialJitterBackoff returns an ExponentialJitterBackoff configured
+// with the base delay and max backoff.
+func NewExponentialJitterBackoff(baseDelay, maxBackoff time.Duration) *ExponentialJitterBackoff {
+	return &ExponentialJitterBackoff{
+		baseDelay:  baseDelay,
+		maxBackoff: maxBackoff,
+	}
+}
+
+// BackoffDelay returns the delay to wait before the next attempt.
+func (j *ExponentialJitterBackoff) BackoffDelay(attempt int, err error) (time.Duration, error) {
+	ceiling := j.ceiling(attempt)
+	if ceiling <= 0 {
+		return 0, nil
+	}
+
+	d, err := rand.CryptoRandInt63n(int64(ceiling) + 1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute backoff jitter, %w", err)
+	}
+
+	return time.Duration(d), nil
+}
+
+func (j *ExponentialJitterBackoff) ceiling(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	ceiling := j.baseDelay
+	for i := 1; i < attempt; i++ {
+		ceiling *= 2
+		if ceiling >= j.maxBackoff || ceiling <= 0 {
+			return j.maxBackoff
+		}
+	}
+	if ceiling > j.maxBackoff {
+		return j.maxBackoff
+	}
+
+	return ceiling
+}
@@ -0,0 +1,55 @@
+package retry
+
+import (
+	"errors"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// DefaultClockSkewErrorCodes is the default set of API error codes which
+// indicate the request's signature was rejected because the client's clock is
+// skewed from the service's clock.
+var DefaultClockSkewErrorCodes = map[string]struct{}{
+	"RequestTimeTooSkewed":      {},
+	"RequestExpired":            {},
+	"RequestInTheFuture":        {},
+	"InvalidSignatureException": {},
+	"SignatureDoesNotMatch":     {},
+	"AuthFailure":               {},
+}
+
+// IsErrorClockSkew returns if the error is an API error with an error code in
+// the set of clock skew error codes.
+func IsErrorClockSkew(err error) bool {
+	_, ok := DefaultClockSkewErrorCodes[errorCode(err)]
+	return ok
+}
+
+// getClockSkew returns the offset between the service's clock and the
+// client's clock, from the Date header of the error's HTTP response. Returns
+// false if the error has no HTTP response, or the header is not set or
+// invalid.
+func getClockSkew(err error) (time.Duration, bool) {
+	var respErr interface{ HTTPResponse() *smithyhttp.Response }
+	if !errors.As(err, &respErr) {
+		return 0, false
+	}
+
+	resp := respErr.HTTPResponse()
+	if resp == nil || resp.Response == nil {
+		return 0, false
+	}
+
+	v := resp.Header.Get("Date")
+	if len(v) == 0 {
+		return 0, false
+	}
+
+	serverTime, err := smithyhttp.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	return serverTime.Sub(timeNow()), true
+}
@@ -0,0 +1,49 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ContinuationTokenExtractor returns the continuation token of a failed
+// attempt that partially completed the operation, and if the attempt
+// returned a token. The token identifies where the next attempt should resume
+// the operation from.
+type ContinuationTokenExtractor func(err error) (token string, ok bool)
+
+// ContinuationTokenSetter returns a copy of the transport request with the
+// continuation token set, (e.g. as a request header or query parameter), so
+// that the attempt resumes the operation from the token instead of
+// restarting it. Provided by the operation's generated code.
+type ContinuationTokenSetter func(request interface{}, token string) (interface{}, error)
+
+// ErrorContinuationToken is a ContinuationTokenExtractor returning the
+// continuation token of errors that implement the ContinuationToken method,
+// (e.g. modeled partial completion errors).
+func ErrorContinuationToken(err error) (string, bool) {
+	var v interface{ ContinuationToken() string }
+	if !errors.As(err, &v) {
+		return "", false
+	}
+	token := v.ContinuationToken()
+	return token, len(token) != 0
+}
+
+// getContinuationToken returns the continuation token of the failed attempt
+// if both the extractor, and setter are configured.
+func (m *Attempt) getContinuationToken(err error) (string, bool) {
+	if m.ContinuationTokenExtractor == nil || m.ContinuationTokenSetter == nil {
+		return "", false
+	}
+	return m.ContinuationTokenExtractor(err)
+}
+
+// resumeRequest returns the request the following attempts are cloned from,
+// resuming the operation from the continuation token.
+func (m *Attempt) resumeRequest(request interface{}, token string) (interface{}, error) {
+	resumed, err := m.ContinuationTokenSetter(m.requestCloner(request), token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set continuation token, %w", err)
+	}
+	return resumed, nil
+}
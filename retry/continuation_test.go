@@ -0,0 +1,116 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockPartialCompletionError struct {
+	token string
+}
+
+func (e *mockPartialCompletionError) Error() string {
+	return "operation partially completed"
+}
+func (e *mockPartialCompletionError) ContinuationToken() string { return e.token }
+func (e *mockPartialCompletionError) RetryableError() bool      { return true }
+
+func setContinuationTokenHeader(request interface{}, token string) (interface{}, error) {
+	req, ok := request.(*smithyhttp.Request)
+	if !ok {
+		return nil, fmt.Errorf("unknown transport type %T", request)
+	}
+	req.Header.Set("X-Continuation-Token", token)
+	return req, nil
+}
+
+func TestAttemptMiddleware_ContinuationToken(t *testing.T) {
+	cases := map[string]struct {
+		Extractor    ContinuationTokenExtractor
+		Setter       ContinuationTokenSetter
+		AttemptErrs  []error
+		ExpectTokens []string
+		ExpectErr    string
+	}{
+		"resume from token": {
+			Extractor: ErrorContinuationToken,
+			Setter:    setContinuationTokenHeader,
+			AttemptErrs: []error{
+				&mockPartialCompletionError{token: "page-2"},
+				&mockPartialCompletionError{token: "page-3"},
+				nil,
+			},
+			ExpectTokens: []string{"", "page-2", "page-3"},
+		},
+		"no token": {
+			Extractor: ErrorContinuationToken,
+			Setter:    setContinuationTokenHeader,
+			AttemptErrs: []error{
+				&mockPartialCompletionError{},
+				nil,
+			},
+			ExpectTokens: []string{"", ""},
+		},
+		"no setter": {
+			Extractor: ErrorContinuationToken,
+			AttemptErrs: []error{
+				&mockPartialCompletionError{token: "page-2"},
+				nil,
+			},
+			ExpectTokens: []string{"", ""},
+		},
+		"setter error": {
+			Extractor: ErrorContinuationToken,
+			Setter: func(interface{}, string) (interface{}, error) {
+				return nil, fmt.Errorf("invalid token")
+			},
+			AttemptErrs: []error{
+				&mockPartialCompletionError{token: "page-2"},
+			},
+			ExpectTokens: []string{""},
+			ExpectErr:    "failed to set continuation token, invalid token",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := NewAttemptMiddleware(mockRetryer{maxAttempts: 3}, smithyhttp.RequestCloner,
+				func(m *Attempt) {
+					m.ContinuationTokenExtractor = c.Extractor
+					m.ContinuationTokenSetter = c.Setter
+				})
+
+			var tokens []string
+			_, _, err := m.HandleFinalize(context.Background(),
+				middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					req := in.Request.(*smithyhttp.Request)
+					tokens = append(tokens, req.Header.Get("X-Continuation-Token"))
+					return out, metadata, c.AttemptErrs[len(tokens)-1]
+				}))
+
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.ExpectTokens, tokens); len(diff) != 0 {
+				t.Errorf("expect attempt continuation tokens to match\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package retry provides the interfaces and implementations for retrying
+// failed operation attempts, and a Finalize step middleware that drives the
+// retry loop for an operation.
+//
+// The Standard retryer classifies errors as retryable based on connection
+// errors, throttling errors, and transient HTTP status codes. Service
+// unavailable errors, (e.g. HTTP 503), are backed off with a separate, longer
+// backoff policy than other transient errors, and can have their own cap on
+// the number of attempts made.
+//
+// The AdaptiveRetryer additionally limits the rate attempts are sent at with
+// a client side token bucket, whose rate is reduced when the service throttles
+// the client, and recovers as attempts succeed.
+//
+// The Hedge middleware reduces the tail latency of idempotent operations by
+// sending a second attempt if the first has not completed after a delay.
+package retry
@@ -0,0 +1,233 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+type operationIdempotentKey struct{}
+
+// SetOperationIdempotent returns a context with the value of whether the
+// operation is idempotent, and is safe to be sent more than once.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetOperationIdempotent(ctx context.Context, v bool) context.Context {
+	return middleware.WithStackValue(ctx, operationIdempotentKey{}, v)
+}
+
+// IsOperationIdempotent returns if the operation was marked as idempotent.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func IsOperationIdempotent(ctx context.Context) bool {
+	v, _ := middleware.GetStackValue(ctx, operationIdempotentKey{}).(bool)
+	return v
+}
+
+// Hedge is a Finalize step middleware that reduces tail latency by sending a
+// second, hedged, attempt of the operation if the first attempt has not
+// completed after a delay. The result of whichever attempt succeeds first is
+// returned, and the other attempt is canceled.
+//
+// Only idempotent operations, see SetOperationIdempotent, without a request
+// stream are hedged. Other operations are invoked once.
+//
+// The Hedge middleware should be added after the Attempt retry middleware, so
+// that each retry attempt may be hedged.
+type Hedge struct {
+	// The duration to wait for the first attempt to complete before sending
+	// the hedged attempt. If zero, operations are not hedged.
+	Delay time.Duration
+
+	requestCloner func(interface{}) interface{}
+}
+
+// NewHedgeMiddleware returns an initialized Hedge middleware. The request
+// cloner is used to create a copy of the transport request for the hedged
+// attempt, (e.g. smithyhttp.RequestCloner).
+func NewHedgeMiddleware(delay time.Duration, requestCloner func(interface{}) interface{}, optFns ...func(*Hedge)) *Hedge {
+	m := &Hedge{
+		Delay:         delay,
+		requestCloner: requestCloner,
+	}
+	for _, fn := range optFns {
+		fn(m)
+	}
+	return m
+}
+
+// AddHedgeMiddleware adds the Hedge middleware to the end of the stack's
+// Finalize step.
+func AddHedgeMiddleware(stack *middleware.Stack, m *Hedge) error {
+	return stack.Finalize.Add(m, middleware.After)
+}
+
+// ID returns the middleware identifier.
+func (m *Hedge) ID() string { return "Hedge" }
+
+type hedgeResult struct {
+	attempt  int
+	out      middleware.FinalizeOutput
+	metadata middleware.Metadata
+	err      error
+}
+
+// HandleFinalize invokes the next handler, and sends a hedged attempt if the
+// first attempt has not completed after the hedge delay. If an attempt fails,
+// the result of the other attempt is used if it is still pending. Otherwise
+// the error of the first failed attempt is returned.
+//
+// The context of the winning attempt is not canceled when the middleware
+// returns if the result has a response body, (e.g. a *smithyhttp.Response, or
+// an output with a Body io.ReadCloser field), so that a streaming response
+// body may still be read. The context is released when the body is closed, or
+// the operation's context is done. The response bodies of losing attempts
+// that also succeed are closed.
+func (m *Hedge) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	if m.Delay <= 0 || !IsOperationIdempotent(ctx) || hasRequestStream(in.Request) {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	start := func(in middleware.FinalizeInput) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			out, metadata, err := next.HandleFinalize(attemptCtx, in)
+			results <- hedgeResult{attempt: attempt, out: out, metadata: metadata, err: err}
+		}()
+	}
+
+	// Each attempt is sent with its own clone of the request, cloned before
+	// any attempt is started, as attempts may modify their request.
+	primaryInput := in
+	primaryInput.Request = m.requestCloner(in.Request)
+	hedgeInput := in
+	hedgeInput.Request = m.requestCloner(in.Request)
+
+	start(primaryInput)
+
+	timer := time.NewTimer(m.Delay)
+	defer timer.Stop()
+	hedgeC := timer.C
+
+	var failed *hedgeResult
+	var completed int
+	for {
+		select {
+		case <-hedgeC:
+			hedgeC = nil
+			start(hedgeInput)
+
+		case res := <-results:
+			completed++
+			if res.err == nil {
+				// Cancel the losing attempt, if one was started, and close
+				// its response body if it also succeeds.
+				for i, cancel := range cancels {
+					if i != res.attempt {
+						cancel()
+					}
+				}
+				go drainHedgeResults(results, len(cancels)-completed)
+
+				releaseOnClose(res.out.Result, cancels[res.attempt])
+				return res.out, res.metadata, res.err
+			}
+
+			cancels[res.attempt]()
+			if failed == nil {
+				failed = &res
+			}
+			if completed == len(cancels) {
+				return failed.out, failed.metadata, failed.err
+			}
+		}
+	}
+}
+
+func hasRequestStream(req interface{}) bool {
+	v, ok := req.(interface{ GetStream() io.Reader })
+	return ok && v.GetStream() != nil
+}
+
+// drainHedgeResults receives the results of the pending attempts, closing the
+// response bodies of attempts that succeeded.
+func drainHedgeResults(results <-chan hedgeResult, pending int) {
+	for i := 0; i < pending; i++ {
+		res := <-results
+		if res.err != nil {
+			continue
+		}
+		if body, _, ok := resultBody(res.out.Result); ok {
+			body.Close()
+		} else if c, ok := res.out.Result.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// releaseOnClose calls cancel once the result's response body is closed. If
+// the result does not have a response body, cancel is called immediately.
+// Results that are an io.Closer, but whose body cannot be replaced, are
+// released when the operation's context is done.
+func releaseOnClose(result interface{}, cancel context.CancelFunc) {
+	if body, setBody, ok := resultBody(result); ok {
+		setBody(&cancelOnCloseBody{ReadCloser: body, cancel: cancel})
+		return
+	}
+	if _, ok := result.(io.Closer); ok {
+		return
+	}
+	cancel()
+}
+
+var readCloserType = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+
+// resultBody returns the response body of the attempt's result, and a
+// function to replace the body, if the result is a *smithyhttp.Response, or a
+// pointer to a struct with a non-nil Body io.ReadCloser field.
+func resultBody(result interface{}) (body io.ReadCloser, setBody func(io.ReadCloser), ok bool) {
+	if resp, ok := result.(*smithyhttp.Response); ok {
+		if resp == nil || resp.Response == nil || resp.Body == nil {
+			return nil, nil, false
+		}
+		return resp.Body, func(b io.ReadCloser) { resp.Body = b }, true
+	}
+
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, nil, false
+	}
+	field := v.Elem().FieldByName("Body")
+	if !field.IsValid() || !field.CanSet() || field.Type() != readCloserType || field.IsNil() {
+		return nil, nil, false
+	}
+	return field.Interface().(io.ReadCloser), func(b io.ReadCloser) {
+		field.Set(reflect.ValueOf(b))
+	}, true
+}
+
+// cancelOnCloseBody is a response body that calls cancel when closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,221 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func TestHedgeMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Idempotent       bool
+		ExpectResult     string
+		ExpectAttempts   int32
+		ExpectPrimaryErr bool
+	}{
+		"hedge wins slow primary": {
+			Idempotent:       true,
+			ExpectResult:     "attempt 2",
+			ExpectAttempts:   2,
+			ExpectPrimaryErr: true,
+		},
+		"not idempotent": {
+			ExpectResult:   "attempt 1",
+			ExpectAttempts: 1,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.Idempotent {
+				ctx = SetOperationIdempotent(ctx, true)
+			}
+
+			var attempts int32
+			primaryDone := make(chan error, 1)
+			m := NewHedgeMiddleware(10*time.Millisecond, smithyhttp.RequestCloner)
+			out, _, err := m.HandleFinalize(ctx,
+				middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					switch atomic.AddInt32(&attempts, 1) {
+					case 1:
+						// The primary attempt is slow, and only completes
+						// if canceled or not hedged.
+						select {
+						case <-ctx.Done():
+							primaryDone <- ctx.Err()
+							return out, metadata, ctx.Err()
+						case <-time.After(100 * time.Millisecond):
+							primaryDone <- nil
+							out.Result = "attempt 1"
+							return out, metadata, nil
+						}
+					default:
+						out.Result = "attempt 2"
+						return out, metadata, nil
+					}
+				}))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectResult, out.Result; e != a {
+				t.Errorf("expect %v result, got %v", e, a)
+			}
+			if e, a := c.ExpectAttempts, atomic.LoadInt32(&attempts); e != a {
+				t.Errorf("expect %v attempts, got %v", e, a)
+			}
+
+			select {
+			case primaryErr := <-primaryDone:
+				if e, a := c.ExpectPrimaryErr, primaryErr != nil; e != a {
+					t.Errorf("expect %v primary canceled, got %v", e, primaryErr)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expect primary attempt to complete")
+			}
+		})
+	}
+}
+
+type hedgeOutput struct {
+	Body io.ReadCloser
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (c *closeRecorder) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestHedgeMiddleware_ReleaseAttempts(t *testing.T) {
+	cases := map[string]struct {
+		WithBody bool
+	}{
+		"with body": {
+			WithBody: true,
+		},
+		"without body": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := SetOperationIdempotent(context.Background(), true)
+
+			var attempts int32
+			attemptCtxs := make([]context.Context, 2)
+			loserBody := &closeRecorder{Reader: strings.NewReader("loser"), closed: make(chan struct{})}
+			releaseLoser := make(chan struct{})
+
+			m := NewHedgeMiddleware(10*time.Millisecond, smithyhttp.RequestCloner)
+			out, _, err := m.HandleFinalize(ctx,
+				middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					attempt := atomic.AddInt32(&attempts, 1)
+					attemptCtxs[attempt-1] = ctx
+					if attempt == 1 {
+						// The primary attempt loses, but succeeds, ignoring
+						// cancellation.
+						<-releaseLoser
+						out.Result = &hedgeOutput{Body: loserBody}
+						return out, metadata, nil
+					}
+
+					result := &hedgeOutput{}
+					if c.WithBody {
+						result.Body = ioutil.NopCloser(strings.NewReader("winner"))
+					}
+					out.Result = result
+					return out, metadata, nil
+				}))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			close(releaseLoser)
+
+			select {
+			case <-loserBody.closed:
+			case <-time.After(time.Second):
+				t.Fatalf("expect losing attempt's body to be closed")
+			}
+
+			winnerCtx := attemptCtxs[1]
+			result := out.Result.(*hedgeOutput)
+			if c.WithBody {
+				if err := winnerCtx.Err(); err != nil {
+					t.Fatalf("expect winning attempt's context not canceled before body closed, got %v", err)
+				}
+				body, err := ioutil.ReadAll(result.Body)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if e, a := "winner", string(body); e != a {
+					t.Errorf("expect %q body, got %q", e, a)
+				}
+				result.Body.Close()
+			}
+
+			if winnerCtx.Err() == nil {
+				t.Errorf("expect winning attempt's context to be released")
+			}
+		})
+	}
+}
+
+func TestHedgeMiddleware_AttemptsModifyRequest(t *testing.T) {
+	stack := middleware.NewStack("stack", smithyhttp.NewStackRequest)
+	if err := AddHedgeMiddleware(stack, NewHedgeMiddleware(5*time.Millisecond, smithyhttp.RequestCloner)); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	// Modifies the attempt's request headers while the hedged attempt is
+	// started.
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("modifyHeaders",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+			middleware.DeserializeOutput, middleware.Metadata, error,
+		) {
+			req := in.Request.(*smithyhttp.Request)
+			deadline := time.Now().Add(20 * time.Millisecond)
+			for i := 0; time.Now().Before(deadline); i++ {
+				req.Header.Set("X-Attempt-Modified", fmt.Sprint(i))
+			}
+			return next.HandleDeserialize(ctx, in)
+		}), middleware.After)
+
+	var attempts int32
+	handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-ctx.Done()
+			return nil, middleware.Metadata{}, ctx.Err()
+		}
+		return &hedgeOutput{}, middleware.Metadata{}, nil
+	})
+
+	ctx := SetOperationIdempotent(context.Background(), true)
+	_, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := int32(2), atomic.LoadInt32(&attempts); e != a {
+		t.Errorf("expect %v attempts, got %v", e, a)
+	}
+}
@@ -0,0 +1,257 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// minClockSkewCorrection is the minimum difference between the clock skew
+// correction already applied and the service's clock for the attempt to be
+// retried with a corrected signing time.
+const minClockSkewCorrection = time.Second
+
+// Attempt is a Finalize step middleware that invokes the rest of the stack
+// for each attempt of the operation, retrying failed attempts as directed by
+// the Retryer.
+//
+// The Attempt middleware should be added before any middleware that need to
+// be invoked for each attempt, (e.g. request signing).
+//
+// If the Retryer implements AttemptRateLimiter, (e.g. AdaptiveRetryer), each
+// attempt must acquire a token from the Retryer before it is sent.
+//
+// Attempts failing with a clock skew error, (e.g. RequestTimeTooSkewed), are
+// retried with the offset of the service's clock from the response's Date
+// header stored in the context, see smithyhttp.SigningTime.
+type Attempt struct {
+	// OnThrottle is invoked for each attempt that fails with a throttling
+	// error, (e.g. HTTP 429), with the duration the service requested the
+	// client wait before retrying via the Retry-After header, or zero if not
+	// specified. Allows integration with an external adaptive rate limiter.
+	OnThrottle func(ctx context.Context, retryAfter time.Duration, attempt int)
+
+	// Extracts the service's retry hint from the response of a failed
+	// attempt. If the service provides a hint, the hint overrides the
+	// Retryer's classification of the error, see RetryHint.
+	RetryHintExtractor RetryHintExtractor
+
+	// Logs each failed attempt that is retried, with the delay before the
+	// next attempt. Overridden for an operation invocation by the log modes
+	// set on the context, see smithyhttp.SetLogMode.
+	LogRetries bool
+
+	// Extracts the continuation token from a failed attempt that partially
+	// completed the operation. If both the extractor, and
+	// ContinuationTokenSetter are set, retried attempts resume the operation
+	// from the token instead of restarting it.
+	ContinuationTokenExtractor ContinuationTokenExtractor
+
+	// Sets the continuation token extracted from a failed attempt on the
+	// request of the following attempts.
+	ContinuationTokenSetter ContinuationTokenSetter
+
+	retryer       Retryer
+	requestCloner func(interface{}) interface{}
+}
+
+// NewAttemptMiddleware returns an initialized Attempt retry middleware. The
+// request cloner is used to create a copy of the transport request for each
+// attempt, (e.g. smithyhttp.RequestCloner).
+func NewAttemptMiddleware(retryer Retryer, requestCloner func(interface{}) interface{}, optFns ...func(*Attempt)) *Attempt {
+	m := &Attempt{
+		retryer:       retryer,
+		requestCloner: requestCloner,
+	}
+	for _, fn := range optFns {
+		fn(m)
+	}
+	return m
+}
+
+// AddRetryMiddleware adds the Attempt retry middleware to the end of the
+// stack's Finalize step.
+func AddRetryMiddleware(stack *middleware.Stack, m *Attempt) error {
+	return stack.Finalize.Add(m, middleware.After)
+}
+
+// ID returns the middleware identifier.
+func (m *Attempt) ID() string { return "Retry" }
+
+// HandleFinalize invokes the next handler for each attempt of the operation,
+// until the attempt succeeds, the error is not retryable, or the maximum
+// number of attempts has been made.
+func (m *Attempt) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	retryer := m.retryer
+	if o, ok := retryer.(OperationRetryer); ok {
+		retryer = o.NewOperationRetryer()
+	}
+	maxAttempts := retryer.MaxAttempts()
+
+	var attemptNum int
+	for {
+		attemptNum++
+
+		attemptInput := in
+		attemptInput.Request = m.requestCloner(in.Request)
+
+		if attemptNum > 1 {
+			if rewindable, ok := attemptInput.Request.(interface{ RewindStream() error }); ok {
+				if rewindErr := rewindable.RewindStream(); rewindErr != nil {
+					err = fmt.Errorf("failed to rewind transport stream for retry, %w", rewindErr)
+					break
+				}
+			}
+		}
+
+		if limiter, ok := m.retryer.(AttemptRateLimiter); ok {
+			if tokenErr := limiter.GetAttemptToken(ctx); tokenErr != nil {
+				err = fmt.Errorf("failed to get attempt token, %w", tokenErr)
+				break
+			}
+		}
+
+		out, metadata, err = next.HandleFinalize(ctx, attemptInput)
+		if limiter, ok := m.retryer.(AttemptRateLimiter); ok {
+			limiter.RecordAttempt(err)
+		}
+		if err == nil {
+			break
+		}
+
+		if m.OnThrottle != nil && IsErrorThrottle(err) {
+			retryAfter, _ := getRetryAfter(err)
+			m.OnThrottle(ctx, retryAfter, attemptNum)
+		}
+
+		hint := getRetryHint(err, m.RetryHintExtractor)
+
+		retryable := retryer.IsErrorRetryable(err)
+		switch hint {
+		case RetryHintNoRetry:
+			retryable = false
+		case RetryHintBackoff, RetryHintImmediate:
+			retryable = true
+		}
+		if !retryable && hint == RetryHintNone && IsErrorClockSkew(err) {
+			// Correct the signing time of the following attempts by the
+			// offset of the service's clock, if the correction would change
+			// the signing time by more than the Date header's resolution.
+			skew, ok := getClockSkew(err)
+			if ok && absDuration(skew-smithyhttp.GetClockSkew(ctx)) > minClockSkewCorrection {
+				ctx = smithyhttp.SetClockSkew(ctx, skew)
+				retryable = true
+			}
+		}
+		if !retryable {
+			break
+		}
+
+		if attemptNum >= maxAttempts {
+			err = &MaxAttemptsError{Attempt: attemptNum, Err: err}
+			break
+		}
+
+		if token, ok := m.getContinuationToken(err); ok {
+			resumed, resumeErr := m.resumeRequest(in.Request, token)
+			if resumeErr != nil {
+				err = fmt.Errorf("retry not attempted, %v, %w", resumeErr, err)
+				break
+			}
+			in.Request = resumed
+		}
+
+		var delay time.Duration
+		if hint != RetryHintImmediate {
+			var delayErr error
+			delay, delayErr = retryer.RetryDelay(attemptNum, err)
+			if delayErr != nil {
+				err = fmt.Errorf("retry not attempted, %v, %w", delayErr, err)
+				break
+			}
+		}
+
+		if m.logRetries(ctx) {
+			middleware.GetLogger(ctx).Logf(logging.Debug,
+				"retrying request, attempt %d failed, waiting %v, %v", attemptNum, delay,
+				middleware.RedactError(ctx, err))
+		}
+
+		if sleepErr := sleepWithContext(ctx, delay); sleepErr != nil {
+			err = sleepErr
+			break
+		}
+	}
+
+	setAttemptCount(&metadata, attemptNum)
+
+	return out, metadata, err
+}
+
+func (m *Attempt) logRetries(ctx context.Context) bool {
+	if mode, ok := smithyhttp.GetLogMode(ctx); ok {
+		return mode.IsRetries()
+	}
+	return m.LogRetries
+}
+
+// MaxAttemptsError provides the error when the maximum number of attempts
+// have been exceeded.
+type MaxAttemptsError struct {
+	Attempt int
+	Err     error
+}
+
+func (e *MaxAttemptsError) Error() string {
+	return fmt.Sprintf("exceeded maximum number of attempts, %d, %v", e.Attempt, e.Err)
+}
+
+// Unwrap returns the nested error causing the max attempts error.
+func (e *MaxAttemptsError) Unwrap() error {
+	return e.Err
+}
+
+type attemptCountKey struct{}
+
+// GetAttemptCount returns the number of attempts made for the operation, and
+// if the value was set in the metadata.
+func GetAttemptCount(metadata middleware.MetadataReader) (int, bool) {
+	v, ok := metadata.Get(attemptCountKey{}).(int)
+	return v, ok
+}
+
+func setAttemptCount(metadata *middleware.Metadata, v int) {
+	metadata.Set(attemptCountKey{}, v)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func sleepWithContext(ctx context.Context, dur time.Duration) error {
+	if dur <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(dur)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return &smithy.CanceledError{Err: ctx.Err()}
+	}
+}
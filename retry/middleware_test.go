@@ -0,0 +1,618 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockRetryer struct {
+	maxAttempts int
+}
+
+func (r mockRetryer) IsErrorRetryable(err error) bool { return IsErrorRetryable(err) }
+func (r mockRetryer) MaxAttempts() int                { return r.maxAttempts }
+func (r mockRetryer) RetryDelay(int, error) (time.Duration, error) {
+	return 0, nil
+}
+
+func TestAttemptMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Errs          []error
+		ExpectAttempt int
+		ExpectErr     func(error) error
+	}{
+		"success": {
+			Errs:          []error{nil},
+			ExpectAttempt: 1,
+		},
+		"retry then success": {
+			Errs: []error{
+				mockResponseError(500, ""),
+				nil,
+			},
+			ExpectAttempt: 2,
+		},
+		"not retryable": {
+			Errs: []error{
+				mockResponseError(400, "ValidationException"),
+			},
+			ExpectAttempt: 1,
+			ExpectErr: func(err error) error {
+				var maxErr *MaxAttemptsError
+				if errors.As(err, &maxErr) {
+					return fmt.Errorf("expect error to not be %T, %v", maxErr, err)
+				}
+				return nil
+			},
+		},
+		"max attempts": {
+			Errs: []error{
+				mockResponseError(500, ""),
+				mockResponseError(500, ""),
+				mockResponseError(500, ""),
+			},
+			ExpectAttempt: 3,
+			ExpectErr: func(err error) error {
+				var maxErr *MaxAttemptsError
+				if !errors.As(err, &maxErr) {
+					return fmt.Errorf("expect error to be %T, %v", maxErr, err)
+				}
+				return nil
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := smithyhttp.NewStackRequest().(*smithyhttp.Request)
+			req, err := req.SetStream(bytes.NewReader([]byte("abc123")))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var attempt int
+			m := NewAttemptMiddleware(mockRetryer{maxAttempts: 3}, smithyhttp.RequestCloner)
+			_, metadata, err := m.HandleFinalize(context.Background(), middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					attempt++
+					body, err := ioutil.ReadAll(in.Request.(*smithyhttp.Request).GetStream())
+					if err != nil {
+						return out, metadata, err
+					}
+					if e, a := "abc123", string(body); e != a {
+						t.Errorf("expect %v body for attempt %d, got %v", e, attempt, a)
+					}
+					return out, metadata, c.Errs[attempt-1]
+				}))
+
+			if c.ExpectErr != nil {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if err = c.ExpectErr(err); err != nil {
+					t.Fatalf("expect error match failed, %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectAttempt, attempt; e != a {
+				t.Errorf("expect %v attempts, got %v", e, a)
+			}
+
+			count, ok := GetAttemptCount(metadata)
+			if !ok {
+				t.Fatalf("expect attempt count in metadata")
+			}
+			if e, a := c.ExpectAttempt, count; e != a {
+				t.Errorf("expect %v attempt count, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestAttemptMiddleware_OnThrottle(t *testing.T) {
+	throttleErr := &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{
+			Response: &http.Response{
+				StatusCode: 429,
+				Header:     http.Header{"Retry-After": []string{"3"}},
+			},
+		},
+		Err: fmt.Errorf("too many requests"),
+	}
+
+	type throttle struct {
+		RetryAfter time.Duration
+		Attempt    int
+	}
+	var throttles []throttle
+
+	m := NewAttemptMiddleware(mockRetryer{maxAttempts: 3}, smithyhttp.RequestCloner,
+		func(m *Attempt) {
+			m.OnThrottle = func(ctx context.Context, retryAfter time.Duration, attempt int) {
+				throttles = append(throttles, throttle{RetryAfter: retryAfter, Attempt: attempt})
+			}
+		})
+
+	var attempt int
+	_, _, err := m.HandleFinalize(context.Background(),
+		middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			attempt++
+			if attempt == 1 {
+				return out, metadata, throttleErr
+			}
+			return out, metadata, nil
+		}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expect := []throttle{{RetryAfter: 3 * time.Second, Attempt: 1}}
+	if diff := cmp.Diff(expect, throttles); len(diff) != 0 {
+		t.Errorf("expect throttle callbacks to match\n%s", diff)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+	timeNow = func() time.Time {
+		return time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	}
+
+	cases := map[string]struct {
+		Value    string
+		Expect   time.Duration
+		ExpectOK bool
+	}{
+		"empty": {},
+		"seconds": {
+			Value:    "120",
+			Expect:   2 * time.Minute,
+			ExpectOK: true,
+		},
+		"http date": {
+			Value:    "Wed, 21 Oct 2015 07:28:30 GMT",
+			Expect:   30 * time.Second,
+			ExpectOK: true,
+		},
+		"http date in past": {
+			Value:    "Wed, 21 Oct 2015 07:27:00 GMT",
+			ExpectOK: true,
+		},
+		"invalid": {
+			Value: "soon",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, ok := parseRetryAfter(c.Value)
+			if e, a := c.ExpectOK, ok; e != a {
+				t.Fatalf("expect %v ok, got %v", e, a)
+			}
+			if e, a := c.Expect, d; e != a {
+				t.Errorf("expect %v duration, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestAttemptMiddleware_ClockSkew(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	serverTime := now.Add(10 * time.Minute)
+	skewErr := &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{
+			Response: &http.Response{
+				StatusCode: 403,
+				Header:     http.Header{"Date": []string{serverTime.Format(http.TimeFormat)}},
+			},
+		},
+		Err: &smithy.GenericAPIError{Code: "RequestTimeTooSkewed"},
+	}
+
+	cases := map[string]struct {
+		Errs          []error
+		ExpectAttempt int
+		ExpectErr     bool
+		ExpectSkews   []time.Duration
+	}{
+		"corrected": {
+			Errs:          []error{skewErr, nil},
+			ExpectAttempt: 2,
+			ExpectSkews:   []time.Duration{0, 10 * time.Minute},
+		},
+		"not corrected twice": {
+			Errs:          []error{skewErr, skewErr},
+			ExpectAttempt: 2,
+			ExpectErr:     true,
+			ExpectSkews:   []time.Duration{0, 10 * time.Minute},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var skews []time.Duration
+			m := NewAttemptMiddleware(mockRetryer{maxAttempts: 3}, smithyhttp.RequestCloner)
+			_, _, err := m.HandleFinalize(context.Background(),
+				middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					skews = append(skews, smithyhttp.GetClockSkew(ctx))
+					return out, metadata, c.Errs[len(skews)-1]
+				}))
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectAttempt, len(skews); e != a {
+				t.Errorf("expect %v attempts, got %v", e, a)
+			}
+			if diff := cmp.Diff(c.ExpectSkews, skews); len(diff) != 0 {
+				t.Errorf("expect clock skews to match\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAttemptMiddleware_StreamFactory(t *testing.T) {
+	var streams int
+	req, err := smithyhttp.NewStackRequest().(*smithyhttp.Request).SetStreamFactory(
+		func() (io.Reader, error) {
+			streams++
+			return strings.NewReader(fmt.Sprintf("stream %d", streams)), nil
+		})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var bodies []string
+	m := NewAttemptMiddleware(mockRetryer{maxAttempts: 3}, smithyhttp.RequestCloner)
+	_, _, err = m.HandleFinalize(context.Background(), middleware.FinalizeInput{Request: req},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			body, err := ioutil.ReadAll(in.Request.(*smithyhttp.Request).GetStream())
+			if err != nil {
+				return out, metadata, err
+			}
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				return out, metadata, mockResponseError(500, "")
+			}
+			return out, metadata, nil
+		}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"stream 1", "stream 2"}, bodies); len(diff) != 0 {
+		t.Errorf("expect each attempt to get a new stream\n%s", diff)
+	}
+}
+
+type recordDelayRetryer struct {
+	mockRetryer
+	delays int
+}
+
+func (r *recordDelayRetryer) RetryDelay(int, error) (time.Duration, error) {
+	r.delays++
+	return 0, nil
+}
+
+func TestAttemptMiddleware_RetryHint(t *testing.T) {
+	hintErr := func(status int, hint string) error {
+		return &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{
+				Response: &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"X-Retry-Hint": []string{hint}},
+				},
+			},
+			Err: fmt.Errorf("failed"),
+		}
+	}
+
+	cases := map[string]struct {
+		Errs          []error
+		ExpectAttempt int
+		ExpectDelays  int
+	}{
+		"no hint": {
+			Errs:          []error{hintErr(500, ""), nil},
+			ExpectAttempt: 2,
+			ExpectDelays:  1,
+		},
+		"no-retry": {
+			Errs:          []error{hintErr(500, "no-retry")},
+			ExpectAttempt: 1,
+		},
+		"backoff": {
+			Errs:          []error{hintErr(400, "backoff"), nil},
+			ExpectAttempt: 2,
+			ExpectDelays:  1,
+		},
+		"immediate": {
+			Errs:          []error{hintErr(500, "immediate"), nil},
+			ExpectAttempt: 2,
+		},
+		"unknown hint": {
+			Errs:          []error{hintErr(400, "later")},
+			ExpectAttempt: 1,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			retryer := &recordDelayRetryer{mockRetryer: mockRetryer{maxAttempts: 3}}
+			m := NewAttemptMiddleware(retryer, smithyhttp.RequestCloner, func(m *Attempt) {
+				m.RetryHintExtractor = HeaderRetryHintExtractor("X-Retry-Hint")
+			})
+
+			var attempt int
+			m.HandleFinalize(context.Background(),
+				middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					attempt++
+					return out, metadata, c.Errs[attempt-1]
+				}))
+
+			if e, a := c.ExpectAttempt, attempt; e != a {
+				t.Errorf("expect %v attempts, got %v", e, a)
+			}
+			if e, a := c.ExpectDelays, retryer.delays; e != a {
+				t.Errorf("expect %v retry delays, got %v", e, a)
+			}
+		})
+	}
+}
+
+type recordLogger struct {
+	messages []string
+}
+
+func (l *recordLogger) Logf(_ logging.Classification, format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestAttemptMiddleware_LogRetries(t *testing.T) {
+	cases := map[string]struct {
+		LogRetries bool
+		SetMode    bool
+		Mode       smithyhttp.LogMode
+		ExpectLogs int
+	}{
+		"disabled": {},
+		"client default": {
+			LogRetries: true,
+			ExpectLogs: 1,
+		},
+		"enabled by log mode": {
+			SetMode:    true,
+			Mode:       smithyhttp.LogRetries,
+			ExpectLogs: 1,
+		},
+		"disabled by log mode": {
+			LogRetries: true,
+			SetMode:    true,
+			Mode:       smithyhttp.LogRequest,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger := &recordLogger{}
+			ctx := middleware.SetLogger(context.Background(), logger)
+			if c.SetMode {
+				ctx = smithyhttp.SetLogMode(ctx, c.Mode)
+			}
+
+			m := NewAttemptMiddleware(mockRetryer{maxAttempts: 3}, smithyhttp.RequestCloner,
+				func(m *Attempt) {
+					m.LogRetries = c.LogRetries
+				})
+
+			var attempt int
+			_, _, err := m.HandleFinalize(ctx,
+				middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					attempt++
+					if attempt == 1 {
+						return out, metadata, &smithyhttp.ResponseError{
+							Response: &smithyhttp.Response{Response: &http.Response{StatusCode: 500}},
+							Err:      fmt.Errorf("internal error"),
+						}
+					}
+					return out, metadata, nil
+				}))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectLogs, len(logger.messages); e != a {
+				t.Fatalf("expect %v log messages, got %v, %v", e, a, logger.messages)
+			}
+			if c.ExpectLogs != 0 && !strings.Contains(logger.messages[0], "attempt 1 failed") {
+				t.Errorf("expect retried attempt logged, got %q", logger.messages[0])
+			}
+		})
+	}
+}
+
+func TestAttemptMiddleware_RewindBuffer(t *testing.T) {
+	const body = "abcdefghijklmnopqrstuvwxyz"
+
+	cases := map[string]struct {
+		FailAfter    int
+		ExpectBodies []string
+		ExpectErr    string
+	}{
+		"retry within buffer": {
+			FailAfter:    4,
+			ExpectBodies: []string{"abcd", body},
+		},
+		"retry past buffer": {
+			FailAfter:    12,
+			ExpectBodies: []string{"abcdefghijkl"},
+			ExpectErr:    "rewind buffer size 8",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Not seekable, so the stream cannot be rewound without the buffer.
+			stream := ioutil.NopCloser(strings.NewReader(body))
+			req, err := smithyhttp.NewStackRequest().(*smithyhttp.Request).SetStreamWithRewindBuffer(stream, 8)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var bodies []string
+			m := NewAttemptMiddleware(mockRetryer{maxAttempts: 3}, smithyhttp.RequestCloner)
+			_, _, err = m.HandleFinalize(context.Background(), middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					stream := in.Request.(*smithyhttp.Request).GetStream()
+					if len(bodies) == 0 {
+						// The first attempt fails after sending part of the body.
+						b, err := ioutil.ReadAll(io.LimitReader(stream, int64(c.FailAfter)))
+						if err != nil {
+							return out, metadata, err
+						}
+						bodies = append(bodies, string(b))
+						return out, metadata, mockResponseError(500, "")
+					}
+					b, err := ioutil.ReadAll(stream)
+					if err != nil {
+						return out, metadata, err
+					}
+					bodies = append(bodies, string(b))
+					return out, metadata, nil
+				}))
+
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.ExpectBodies, bodies); len(diff) != 0 {
+				t.Errorf("expect attempt bodies to match\n%s", diff)
+			}
+		})
+	}
+}
+
+type fixedDelayRetryer struct {
+	mockRetryer
+	delay time.Duration
+}
+
+func (r fixedDelayRetryer) RetryDelay(int, error) (time.Duration, error) {
+	return r.delay, nil
+}
+
+func TestAttemptMiddleware_CanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var attempt int
+	m := NewAttemptMiddleware(fixedDelayRetryer{mockRetryer: mockRetryer{maxAttempts: 3}, delay: time.Minute},
+		smithyhttp.RequestCloner)
+
+	start := time.Now()
+	_, metadata, err := m.HandleFinalize(ctx,
+		middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			attempt++
+			return out, metadata, mockResponseError(500, "")
+		}))
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expect backoff to stop when canceled, took %v", elapsed)
+	}
+
+	var cancelErr *smithy.CanceledError
+	if !errors.As(err, &cancelErr) {
+		t.Errorf("expect %T error, got %v", cancelErr, err)
+	}
+	if e, a := 1, attempt; e != a {
+		t.Errorf("expect %v attempts, got %v", e, a)
+	}
+	if count, _ := GetAttemptCount(metadata); count != 1 {
+		t.Errorf("expect 1 attempt count, got %v", count)
+	}
+}
+
+func TestAttemptMiddleware_StandardRetryer(t *testing.T) {
+	retryer := NewStandard(func(o *StandardOptions) {
+		o.MaxAttempts = 4
+		o.Backoff = NewExponentialJitterBackoff(time.Millisecond, 5*time.Millisecond)
+	})
+
+	var attempt int
+	m := NewAttemptMiddleware(retryer, smithyhttp.RequestCloner)
+	_, metadata, err := m.HandleFinalize(context.Background(),
+		middleware.FinalizeInput{Request: smithyhttp.NewStackRequest()},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			attempt++
+			return out, metadata, mockResponseError(500, "")
+		}))
+
+	var maxErr *MaxAttemptsError
+	if !errors.As(err, &maxErr) {
+		t.Fatalf("expect %T error, got %v", maxErr, err)
+	}
+	if e, a := 4, attempt; e != a {
+		t.Errorf("expect handler called %v times, got %v", e, a)
+	}
+	if count, _ := GetAttemptCount(metadata); count != 4 {
+		t.Errorf("expect 4 attempt count, got %v", count)
+	}
+}
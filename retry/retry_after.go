@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// package variable that can be override in unit tests.
+var timeNow = time.Now
+
+// getRetryAfter returns the duration the service requested the client wait
+// before retrying, from the Retry-After header of the error's HTTP response.
+// The header may be either a number of seconds, or an HTTP date. Returns false
+// if the error has no HTTP response, or the header is not set or invalid.
+func getRetryAfter(err error) (time.Duration, bool) {
+	var respErr interface{ HTTPResponse() *smithyhttp.Response }
+	if !errors.As(err, &respErr) {
+		return 0, false
+	}
+
+	resp := respErr.HTTPResponse()
+	if resp == nil || resp.Response == nil {
+		return 0, false
+	}
+
+	return parseRetryAfter(resp.Header.Get("Retry-After"))
+}
+
+func parseRetryAfter(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if len(v) == 0 {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := smithyhttp.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	d := t.Sub(timeNow())
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"errors"
+	"strings"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// RetryHint is a service provided directive on how a failed attempt should
+// be retried, overriding the retryer's classification of the error.
+type RetryHint int
+
+// Enumeration of the retry hints.
+const (
+	// RetryHintNone indicates the service did not provide a hint, and the
+	// retryer's classification of the error is used.
+	RetryHintNone RetryHint = iota
+
+	// RetryHintBackoff indicates the attempt should be retried after the
+	// retryer's backoff delay.
+	RetryHintBackoff
+
+	// RetryHintNoRetry indicates the attempt should not be retried.
+	RetryHintNoRetry
+
+	// RetryHintImmediate indicates the attempt should be retried without
+	// delay.
+	RetryHintImmediate
+)
+
+func (h RetryHint) String() string {
+	switch h {
+	case RetryHintBackoff:
+		return "backoff"
+	case RetryHintNoRetry:
+		return "no-retry"
+	case RetryHintImmediate:
+		return "immediate"
+	default:
+		return "none"
+	}
+}
+
+// RetryHintExtractor returns the retry hint of the response of a failed
+// attempt.
+type RetryHintExtractor func(resp *smithyhttp.Response) RetryHint
+
+// HeaderRetryHintExtractor returns a RetryHintExtractor that reads the retry
+// hint from the response header, (e.g. X-Retry-Hint). The header's value is
+// one of backoff, no-retry, or immediate. Other values are ignored.
+func HeaderRetryHintExtractor(header string) RetryHintExtractor {
+	return func(resp *smithyhttp.Response) RetryHint {
+		switch strings.ToLower(strings.TrimSpace(resp.Header.Get(header))) {
+		case "backoff":
+			return RetryHintBackoff
+		case "no-retry":
+			return RetryHintNoRetry
+		case "immediate":
+			return RetryHintImmediate
+		default:
+			return RetryHintNone
+		}
+	}
+}
+
+// getRetryHint returns the retry hint of the error's HTTP response, or
+// RetryHintNone if the error has no HTTP response.
+func getRetryHint(err error, extractor RetryHintExtractor) RetryHint {
+	if extractor == nil {
+		return RetryHintNone
+	}
+
+	var respErr interface{ HTTPResponse() *smithyhttp.Response }
+	if !errors.As(err, &respErr) {
+		return RetryHintNone
+	}
+
+	resp := respErr.HTTPResponse()
+	if resp == nil || resp.Response == nil {
+		return RetryHintNone
+	}
+
+	return extractor(resp)
+}
@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/smithy-go"
+)
+
+// DefaultRetryableHTTPStatusCodes is the default set of HTTP status codes
+// which are considered transient, and retryable.
+var DefaultRetryableHTTPStatusCodes = map[int]struct{}{
+	http.StatusInternalServerError: {},
+	http.StatusBadGateway:          {},
+	http.StatusServiceUnavailable:  {},
+	http.StatusGatewayTimeout:      {},
+}
+
+// DefaultThrottleErrorCodes is the default set of API error codes which are
+// considered throttling errors.
+var DefaultThrottleErrorCodes = map[string]struct{}{
+	"Throttling":                             {},
+	"ThrottlingException":                    {},
+	"ThrottledException":                     {},
+	"RequestThrottledException":              {},
+	"TooManyRequestsException":               {},
+	"ProvisionedThroughputExceededException": {},
+	"TransactionInProgressException":         {},
+	"RequestLimitExceeded":                   {},
+	"BandwidthLimitExceeded":                 {},
+	"LimitExceededException":                 {},
+	"RequestThrottled":                       {},
+	"SlowDown":                               {},
+	"PriorRequestNotComplete":                {},
+	"EC2ThrottledException":                  {},
+}
+
+// DefaultServiceUnavailableErrorCodes is the default set of API error codes
+// returned with an HTTP 500 status which indicate the service is temporarily
+// unavailable, and should be treated the same as an HTTP 503 response.
+var DefaultServiceUnavailableErrorCodes = map[string]struct{}{
+	"ServiceUnavailable":          {},
+	"ServiceUnavailableException": {},
+	"InternalFailure":             {},
+}
+
+// IsErrorRetryable returns if the error is retryable based on the default
+// classification of connection errors, throttling errors, and transient HTTP
+// status codes. Errors implementing the RetryableError method take precedence
+// over the default classification. Canceled errors are never retryable.
+func IsErrorRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var canceled interface{ CanceledError() bool }
+	if errors.As(err, &canceled) && canceled.CanceledError() {
+		return false
+	}
+
+	var retryable interface{ RetryableError() bool }
+	if errors.As(err, &retryable) {
+		return retryable.RetryableError()
+	}
+
+	var conn interface{ ConnectionError() bool }
+	if errors.As(err, &conn) && conn.ConnectionError() {
+		return true
+	}
+
+	if IsErrorThrottle(err) {
+		return true
+	}
+
+	if code, ok := httpStatusCode(err); ok {
+		_, ok = DefaultRetryableHTTPStatusCodes[code]
+		return ok
+	}
+
+	return false
+}
+
+// IsErrorThrottle returns if the error is a throttling error, either an HTTP
+// 429 status code, or an API error with a throttling error code.
+func IsErrorThrottle(err error) bool {
+	if code, ok := httpStatusCode(err); ok && code == http.StatusTooManyRequests {
+		return true
+	}
+
+	_, ok := DefaultThrottleErrorCodes[errorCode(err)]
+	return ok
+}
+
+// IsErrorServiceUnavailable returns if the error indicates the service is
+// temporarily unavailable. Either an HTTP 503 status code, or an HTTP 500
+// status code with an API error code in the set of codes provided.
+func IsErrorServiceUnavailable(err error, codes map[string]struct{}) bool {
+	status, ok := httpStatusCode(err)
+	if !ok {
+		return false
+	}
+
+	switch status {
+	case http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError:
+		_, ok := codes[errorCode(err)]
+		return ok
+	default:
+		return false
+	}
+}
+
+func httpStatusCode(err error) (int, bool) {
+	var v interface{ HTTPStatusCode() int }
+	if !errors.As(err, &v) {
+		return 0, false
+	}
+	return v.HTTPStatusCode(), true
+}
+
+func errorCode(err error) string {
+	var v smithy.APIError
+	if !errors.As(err, &v) {
+		return ""
+	}
+	return v.ErrorCode()
+}
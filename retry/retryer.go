@@ -0,0 +1,59 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Retryer provides the interface the retry middleware uses to determine if a
+// failed attempt should be retried, and how long to wait before doing so.
+type Retryer interface {
+	// IsErrorRetryable returns if the failed attempt's error is retryable.
+	IsErrorRetryable(err error) bool
+
+	// MaxAttempts returns the maximum number of attempts that can be made for
+	// an operation, including the initial attempt.
+	MaxAttempts() int
+
+	// RetryDelay returns the delay that should be used before retrying the
+	// attempt. Returns an error if the attempt should not be retried.
+	RetryDelay(attempt int, err error) (time.Duration, error)
+}
+
+// AttemptRateLimiter provides the optional interface a Retryer may implement
+// to limit the rate attempts are sent at, (e.g. AdaptiveRetryer). The retry
+// middleware acquires a token before each attempt, and records the result of
+// each attempt.
+type AttemptRateLimiter interface {
+	// GetAttemptToken blocks until the attempt may be sent, or the context
+	// is done.
+	GetAttemptToken(ctx context.Context) error
+
+	// RecordAttempt records the result of an attempt, with the attempt's
+	// error, or nil if the attempt succeeded.
+	RecordAttempt(err error)
+}
+
+// OperationRetryer provides the optional interface a Retryer may implement
+// to keep state across the attempts of a single operation, (e.g. counting the
+// attempts that failed with a particular error). The retry middleware uses
+// the Retryer returned by NewOperationRetryer for the attempts of each
+// operation.
+type OperationRetryer interface {
+	NewOperationRetryer() Retryer
+}
+
+// BackoffDelayer provides the interface for computing the delay before the
+// next attempt is made.
+type BackoffDelayer interface {
+	BackoffDelay(attempt int, err error) (time.Duration, error)
+}
+
+// BackoffDelayerFunc provides a wrapper around a function to be used as a
+// BackoffDelayer.
+type BackoffDelayerFunc func(int, error) (time.Duration, error)
+
+// BackoffDelay invokes the wrapped function.
+func (fn BackoffDelayerFunc) BackoffDelay(attempt int, err error) (time.Duration, error) {
+	return fn(attempt, err)
+}
@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default values used by the Standard retryer when not otherwise configured.
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 100 * time.Millisecond
+	DefaultMaxBackoff  = 20 * time.Second
+
+	DefaultMaxServiceUnavailableAttempts = 3
+	DefaultServiceUnavailableBaseDelay   = 1 * time.Second
+	DefaultServiceUnavailableMaxBackoff  = 60 * time.Second
+)
+
+// StandardOptions provides the configuration options for the Standard
+// retryer.
+type StandardOptions struct {
+	// The maximum number of attempts that will be made for an operation,
+	// including the initial attempt. Defaults to DefaultMaxAttempts.
+	MaxAttempts int
+
+	// The backoff used for retryable errors that are not service unavailable
+	// errors. Defaults to an ExponentialJitterBackoff with DefaultBaseDelay,
+	// and DefaultMaxBackoff.
+	Backoff BackoffDelayer
+
+	// The backoff used when the service reports it is unavailable, (e.g. HTTP
+	// 503). A service that is unavailable is often scaling, and should be
+	// given more time to recover than other transient errors. Defaults to an
+	// ExponentialJitterBackoff with DefaultServiceUnavailableBaseDelay, and
+	// DefaultServiceUnavailableMaxBackoff.
+	ServiceUnavailableBackoff BackoffDelayer
+
+	// The maximum number of attempts of an operation that may fail with a
+	// service unavailable error, after which a service unavailable error will
+	// no longer be retried. Counted independently of attempts that failed
+	// with other errors, and capped by MaxAttempts. Defaults to
+	// DefaultMaxServiceUnavailableAttempts.
+	MaxServiceUnavailableAttempts int
+
+	// The set of API error codes that when returned with an HTTP 500 status
+	// code are treated as the service being unavailable. Defaults to
+	// DefaultServiceUnavailableErrorCodes.
+	ServiceUnavailableErrorCodes map[string]struct{}
+}
+
+// Standard is the standard retryer implementation, retrying connection,
+// throttling, and transient HTTP status code errors with exponential backoff
+// and full jitter.
+type Standard struct {
+	options StandardOptions
+}
+
+// NewStandard returns an initialized Standard retryer, with the optional
+// functional options applied.
+func NewStandard(optFns ...func(*StandardOptions)) *Standard {
+	options := StandardOptions{
+		MaxAttempts:                   DefaultMaxAttempts,
+		MaxServiceUnavailableAttempts: DefaultMaxServiceUnavailableAttempts,
+		ServiceUnavailableErrorCodes:  DefaultServiceUnavailableErrorCodes,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	if options.Backoff == nil {
+		options.Backoff = NewExponentialJitterBackoff(DefaultBaseDelay, DefaultMaxBackoff)
+	}
+	if options.ServiceUnavailableBackoff == nil {
+		options.ServiceUnavailableBackoff = NewExponentialJitterBackoff(
+			DefaultServiceUnavailableBaseDelay, DefaultServiceUnavailableMaxBackoff)
+	}
+
+	return &Standard{
+		options: options,
+	}
+}
+
+// IsErrorRetryable returns if the error can be retried.
+func (s *Standard) IsErrorRetryable(err error) bool {
+	return IsErrorRetryable(err)
+}
+
+// MaxAttempts returns the maximum number of attempts that can be made for an
+// operation.
+func (s *Standard) MaxAttempts() int {
+	return s.options.MaxAttempts
+}
+
+// RetryDelay returns the delay to wait before retrying the attempt. Service
+// unavailable errors use the ServiceUnavailableBackoff, all other errors use
+// the Backoff.
+//
+// The Standard retryer does not keep the state of an operation's attempts,
+// and does not limit the number of service unavailable attempts. Use the
+// Retryer returned by NewOperationRetryer to limit the service unavailable
+// attempts of an operation. The retry middleware does so for each operation.
+func (s *Standard) RetryDelay(attempt int, err error) (time.Duration, error) {
+	if IsErrorServiceUnavailable(err, s.options.ServiceUnavailableErrorCodes) {
+		return s.options.ServiceUnavailableBackoff.BackoffDelay(attempt, err)
+	}
+
+	return s.options.Backoff.BackoffDelay(attempt, err)
+}
+
+// NewOperationRetryer returns a Retryer for the attempts of a single
+// operation, that counts the operation's attempts that failed with a service
+// unavailable error, independently of other errors. Returns an error from
+// RetryDelay once MaxServiceUnavailableAttempts attempts have failed with a
+// service unavailable error.
+func (s *Standard) NewOperationRetryer() Retryer {
+	return &standardOperation{Standard: s}
+}
+
+// standardOperation is the Standard retryer for the attempts of a single
+// operation.
+type standardOperation struct {
+	*Standard
+	serviceUnavailableAttempts int
+}
+
+func (s *standardOperation) RetryDelay(attempt int, err error) (time.Duration, error) {
+	if !IsErrorServiceUnavailable(err, s.options.ServiceUnavailableErrorCodes) {
+		return s.options.Backoff.BackoffDelay(attempt, err)
+	}
+
+	s.serviceUnavailableAttempts++
+	if s.serviceUnavailableAttempts >= s.options.MaxServiceUnavailableAttempts {
+		return 0, fmt.Errorf("exceeded maximum number of service unavailable attempts, %d",
+			s.options.MaxServiceUnavailableAttempts)
+	}
+	return s.options.ServiceUnavailableBackoff.BackoffDelay(s.serviceUnavailableAttempts, err)
+}
@@ -0,0 +1,207 @@
+package retry
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func mockResponseError(statusCode int, code string) error {
+	var err error = fmt.Errorf("some error")
+	if len(code) != 0 {
+		err = &smithy.GenericAPIError{Code: code}
+	}
+
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{
+			Response: &http.Response{StatusCode: statusCode},
+		},
+		Err: err,
+	}
+}
+
+func fixedBackoff(d time.Duration) BackoffDelayer {
+	return BackoffDelayerFunc(func(int, error) (time.Duration, error) {
+		return d, nil
+	})
+}
+
+func TestStandard_RetryDelay(t *testing.T) {
+	cases := map[string]struct {
+		PriorErrs   []error
+		Err         error
+		ExpectDelay time.Duration
+		ExpectErr   bool
+	}{
+		"network error": {
+			Err:         &smithyhttp.RequestSendError{Err: fmt.Errorf("connection reset")},
+			ExpectDelay: time.Second,
+		},
+		"500 status": {
+			Err:         mockResponseError(500, ""),
+			ExpectDelay: time.Second,
+		},
+		"503 status": {
+			Err:         mockResponseError(503, ""),
+			ExpectDelay: 10 * time.Second,
+		},
+		"500 status service unavailable code": {
+			Err:         mockResponseError(500, "ServiceUnavailable"),
+			ExpectDelay: 10 * time.Second,
+		},
+		"503 status attempts exhausted": {
+			PriorErrs: []error{mockResponseError(503, "")},
+			Err:       mockResponseError(503, ""),
+			ExpectErr: true,
+		},
+		"503 status after throttled attempts": {
+			PriorErrs: []error{
+				mockResponseError(429, ""),
+				mockResponseError(429, ""),
+				mockResponseError(429, ""),
+			},
+			Err:         mockResponseError(503, ""),
+			ExpectDelay: 10 * time.Second,
+		},
+		"503 status attempts exhausted between other errors": {
+			PriorErrs: []error{
+				mockResponseError(503, ""),
+				mockResponseError(429, ""),
+			},
+			Err:       mockResponseError(503, ""),
+			ExpectErr: true,
+		},
+		"network error not capped by service unavailable attempts": {
+			PriorErrs: []error{
+				mockResponseError(503, ""),
+				&smithyhttp.RequestSendError{Err: fmt.Errorf("connection reset")},
+				&smithyhttp.RequestSendError{Err: fmt.Errorf("connection reset")},
+			},
+			Err:         &smithyhttp.RequestSendError{Err: fmt.Errorf("connection reset")},
+			ExpectDelay: time.Second,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			retryer := NewStandard(func(o *StandardOptions) {
+				o.MaxAttempts = 5
+				o.Backoff = fixedBackoff(time.Second)
+				o.ServiceUnavailableBackoff = fixedBackoff(10 * time.Second)
+				o.MaxServiceUnavailableAttempts = 2
+			}).NewOperationRetryer()
+
+			for i, err := range c.PriorErrs {
+				if _, err := retryer.RetryDelay(i+1, err); err != nil {
+					t.Fatalf("expect no error for prior attempt %d, got %v", i+1, err)
+				}
+			}
+
+			if !retryer.IsErrorRetryable(c.Err) {
+				t.Fatalf("expect error to be retryable")
+			}
+
+			delay, err := retryer.RetryDelay(len(c.PriorErrs)+1, c.Err)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectDelay, delay; e != a {
+				t.Errorf("expect %v delay, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestStandard_OperationRetryerIndependent(t *testing.T) {
+	standard := NewStandard(func(o *StandardOptions) {
+		o.ServiceUnavailableBackoff = fixedBackoff(0)
+		o.MaxServiceUnavailableAttempts = 2
+	})
+
+	first := standard.NewOperationRetryer()
+	if _, err := first.RetryDelay(1, mockResponseError(503, "")); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	second := standard.NewOperationRetryer()
+	if _, err := second.RetryDelay(1, mockResponseError(503, "")); err != nil {
+		t.Errorf("expect operation's service unavailable attempts not shared, got %v", err)
+	}
+	if _, err := first.RetryDelay(2, mockResponseError(503, "")); err == nil {
+		t.Errorf("expect error, got none")
+	}
+}
+
+func TestIsErrorRetryable(t *testing.T) {
+	cases := map[string]struct {
+		Err    error
+		Expect bool
+	}{
+		"nil error": {},
+		"canceled": {
+			Err: &smithy.CanceledError{Err: fmt.Errorf("canceled")},
+		},
+		"connection error": {
+			Err:    &smithyhttp.RequestSendError{Err: fmt.Errorf("some error")},
+			Expect: true,
+		},
+		"throttle status": {
+			Err:    mockResponseError(429, ""),
+			Expect: true,
+		},
+		"throttle code": {
+			Err:    mockResponseError(400, "ThrottlingException"),
+			Expect: true,
+		},
+		"client error": {
+			Err: mockResponseError(400, "ValidationException"),
+		},
+		"gateway timeout": {
+			Err:    mockResponseError(504, ""),
+			Expect: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.Expect, IsErrorRetryable(c.Err); e != a {
+				t.Errorf("expect %v retryable, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestExponentialJitterBackoff(t *testing.T) {
+	backoff := NewExponentialJitterBackoff(time.Second, 5*time.Second)
+
+	for attempt, expectCeiling := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  5 * time.Second,
+		64: 5 * time.Second,
+	} {
+		if e, a := expectCeiling, backoff.ceiling(attempt); e != a {
+			t.Errorf("expect attempt %d ceiling %v, got %v", attempt, e, a)
+		}
+
+		delay, err := backoff.BackoffDelay(attempt, nil)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if delay < 0 || delay > expectCeiling {
+			t.Errorf("expect attempt %d delay within [0, %v], got %v", attempt, expectCeiling, delay)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package testing
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultCurlRedactedHeaders is the default set of request headers whose
+// values are redacted by ToCurl, as they contain credentials.
+var DefaultCurlRedactedHeaders = []string{
+	"Authorization",
+	"X-Amz-Security-Token",
+}
+
+// CurlOptions provides the options for ToCurl.
+type CurlOptions struct {
+	// The headers whose values are replaced with "<redacted>". Defaults to
+	// DefaultCurlRedactedHeaders. Set to nil to include all header values.
+	RedactHeaders []string
+}
+
+// ToCurl returns a curl command reproducing the request's method, URL,
+// headers, and body, for debugging, and reporting issues.
+//
+// The body is only included if it can be read without consuming the
+// request's body, (e.g. GetBody is set). Otherwise, the body is a stream,
+// and curl is instructed to read the body from stdin instead.
+func ToCurl(req *http.Request, optFns ...func(*CurlOptions)) string {
+	options := CurlOptions{
+		RedactHeaders: DefaultCurlRedactedHeaders,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	redacted := map[string]struct{}{}
+	for _, h := range options.RedactHeaders {
+		redacted[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+
+	var url string
+	if req.URL != nil {
+		url = req.URL.String()
+	}
+	args := []string{
+		"curl -X " + shellQuote(req.Method) + " " + shellQuote(url),
+	}
+
+	header := req.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if len(req.Host) != 0 && (req.URL == nil || req.Host != req.URL.Host) {
+		header.Set("Host", req.Host)
+	}
+
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		_, redact := redacted[http.CanonicalHeaderKey(k)]
+		for _, v := range header[k] {
+			if redact {
+				v = "<redacted>"
+			}
+			args = append(args, "-H "+shellQuote(k+": "+v))
+		}
+	}
+
+	if body, ok := curlBody(req); ok {
+		args = append(args, "--data-binary "+body)
+	}
+
+	return strings.Join(args, " \\\n  ")
+}
+
+// curlBody returns the curl data argument for the request's body, and if the
+// request has a body.
+func curlBody(req *http.Request) (string, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", false
+	}
+	if req.GetBody == nil {
+		return "@-", true
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "@-", true
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "@-", true
+	}
+	return shellQuote(string(b)), true
+}
+
+// shellQuote returns the value single quoted for a POSIX shell.
+func shellQuote(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
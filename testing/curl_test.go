@@ -0,0 +1,92 @@
+package testing
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestToCurl(t *testing.T) {
+	newRequest := func(method, url, body string, header http.Header) *http.Request {
+		var r *http.Request
+		if len(body) != 0 {
+			r, _ = http.NewRequest(method, url, strings.NewReader(body))
+		} else {
+			r, _ = http.NewRequest(method, url, nil)
+		}
+		for k, vs := range header {
+			r.Header[k] = vs
+		}
+		return r
+	}
+
+	cases := map[string]struct {
+		Request *http.Request
+		Options func(*CurlOptions)
+		Expect  string
+	}{
+		"redacted auth": {
+			Request: newRequest("PUT", "https://example.com/bucket/key?versionId=1", "hello", http.Header{
+				"Authorization":        []string{"AWS4-HMAC-SHA256 Credential=AKID/20220101/us-west-2/s3/aws4_request"},
+				"X-Amz-Security-Token": []string{"token"},
+				"Content-Type":         []string{"text/plain"},
+				"X-Amz-Meta-Foo":       []string{"a", "b"},
+			}),
+			Expect: `curl -X 'PUT' 'https://example.com/bucket/key?versionId=1' \
+  -H 'Authorization: <redacted>' \
+  -H 'Content-Type: text/plain' \
+  -H 'X-Amz-Meta-Foo: a' \
+  -H 'X-Amz-Meta-Foo: b' \
+  -H 'X-Amz-Security-Token: <redacted>' \
+  --data-binary 'hello'`,
+		},
+		"no redaction": {
+			Request: newRequest("GET", "https://example.com/", "", http.Header{
+				"Authorization": []string{"secret"},
+			}),
+			Options: func(o *CurlOptions) {
+				o.RedactHeaders = nil
+			},
+			Expect: `curl -X 'GET' 'https://example.com/' \
+  -H 'Authorization: secret'`,
+		},
+		"quoted body": {
+			Request: newRequest("POST", "https://example.com/", `{"name":"it's"}`, nil),
+			Expect: `curl -X 'POST' 'https://example.com/' \
+  --data-binary '{"name":"it'\''s"}'`,
+		},
+		"stream body": {
+			Request: func() *http.Request {
+				r := newRequest("POST", "https://example.com/", "", nil)
+				r.Body = ioutil.NopCloser(strings.NewReader("stream"))
+				return r
+			}(),
+			Expect: `curl -X 'POST' 'https://example.com/' \
+  --data-binary @-`,
+		},
+		"host override": {
+			Request: func() *http.Request {
+				r := newRequest("GET", "https://127.0.0.1/", "", nil)
+				r.Host = "example.com"
+				return r
+			}(),
+			Expect: `curl -X 'GET' 'https://127.0.0.1/' \
+  -H 'Host: example.com'`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var optFns []func(*CurlOptions)
+			if c.Options != nil {
+				optFns = append(optFns, c.Options)
+			}
+
+			actual := ToCurl(c.Request, optFns...)
+			if e, a := c.Expect, actual; e != a {
+				t.Errorf("expect curl command\n%s\ngot\n%s", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package testing
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultVolatileHeaders is the default set of request headers ignored by
+// DiffRequests, as their values differ between invocations, (e.g. the
+// signing time, and signature).
+var DefaultVolatileHeaders = []string{
+	"Amz-Sdk-Invocation-Id",
+	"Amz-Sdk-Request",
+	"Authorization",
+	"User-Agent",
+	"X-Amz-Date",
+}
+
+// DiffRequestsOptions provides the options for DiffRequests.
+type DiffRequestsOptions struct {
+	// The headers ignored when comparing the requests. Defaults to
+	// DefaultVolatileHeaders.
+	IgnoreHeaders []string
+
+	// Sets if the request bodies are not compared.
+	IgnoreBody bool
+}
+
+// DiffRequests compares the method, URL, headers, and body of the requests,
+// returning a human readable description of each difference, one per line.
+// Returns an empty string if the requests are equal.
+//
+// The URL's query is compared independent of the order of its parameters.
+// Header names are compared case-insensitively, and multiple values of a
+// header are compared joined with commas. The bodies of the requests are
+// read, and replaced with an unread copy.
+func DiffRequests(expect, actual *http.Request, optFns ...func(*DiffRequestsOptions)) string {
+	options := DiffRequestsOptions{
+		IgnoreHeaders: DefaultVolatileHeaders,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	var diffs []string
+	diff := func(field string, e, a interface{}) {
+		diffs = append(diffs, fmt.Sprintf("%s: expect %q, got %q", field, e, a))
+	}
+
+	if e, a := expect.Method, actual.Method; e != a {
+		diff("method", e, a)
+	}
+
+	eURL, aURL := expect.URL, actual.URL
+	if eURL == nil {
+		eURL = &url.URL{}
+	}
+	if aURL == nil {
+		aURL = &url.URL{}
+	}
+	if e, a := eURL.Scheme, aURL.Scheme; e != a {
+		diff("URL scheme", e, a)
+	}
+	if e, a := requestHost(expect), requestHost(actual); e != a {
+		diff("URL host", e, a)
+	}
+	if e, a := eURL.EscapedPath(), aURL.EscapedPath(); e != a {
+		diff("URL path", e, a)
+	}
+	if e, a := eURL.Query().Encode(), aURL.Query().Encode(); e != a {
+		diff("URL query", e, a)
+	}
+
+	diffs = append(diffs, diffHeaders(expect.Header, actual.Header, options.IgnoreHeaders)...)
+
+	if !options.IgnoreBody {
+		eBody, eErr := readRequestBody(expect)
+		aBody, aErr := readRequestBody(actual)
+		switch {
+		case eErr != nil:
+			diffs = append(diffs, fmt.Sprintf("body: failed to read expect body, %v", eErr))
+		case aErr != nil:
+			diffs = append(diffs, fmt.Sprintf("body: failed to read actual body, %v", aErr))
+		case !bytes.Equal(eBody, aBody):
+			diff("body", string(eBody), string(aBody))
+		}
+	}
+
+	return strings.Join(diffs, "\n")
+}
+
+func requestHost(r *http.Request) string {
+	if len(r.Host) != 0 {
+		return r.Host
+	}
+	if r.URL == nil {
+		return ""
+	}
+	return r.URL.Host
+}
+
+func diffHeaders(expect, actual http.Header, ignore []string) []string {
+	ignored := map[string]struct{}{}
+	for _, h := range ignore {
+		ignored[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+
+	normalize := func(h http.Header) map[string]string {
+		m := map[string]string{}
+		for k, vs := range h {
+			k = http.CanonicalHeaderKey(k)
+			if _, ok := ignored[k]; ok {
+				continue
+			}
+			if v, ok := m[k]; ok {
+				vs = append([]string{v}, vs...)
+			}
+			m[k] = strings.Join(vs, ", ")
+		}
+		return m
+	}
+	e, a := normalize(expect), normalize(actual)
+
+	keys := make([]string, 0, len(e)+len(a))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	for k := range a {
+		if _, ok := e[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []string
+	for _, k := range keys {
+		ev, eok := e[k]
+		av, aok := a[k]
+		switch {
+		case !aok:
+			diffs = append(diffs, fmt.Sprintf("header %s: expect %q, got none", k, ev))
+		case !eok:
+			diffs = append(diffs, fmt.Sprintf("header %s: expect none, got %q", k, av))
+		case ev != av:
+			diffs = append(diffs, fmt.Sprintf("header %s: expect %q, got %q", k, ev, av))
+		}
+	}
+	return diffs
+}
+
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return b, err
+}
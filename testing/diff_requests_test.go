@@ -0,0 +1,87 @@
+package testing
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDiffRequests(t *testing.T) {
+	newRequest := func(method, url, body string, header http.Header) *http.Request {
+		var r *http.Request
+		if len(body) != 0 {
+			r, _ = http.NewRequest(method, url, strings.NewReader(body))
+		} else {
+			r, _ = http.NewRequest(method, url, nil)
+		}
+		for k, vs := range header {
+			r.Header[k] = vs
+		}
+		return r
+	}
+
+	cases := map[string]struct {
+		Expect, Actual *http.Request
+		Options        func(*DiffRequestsOptions)
+		ExpectDiff     string
+	}{
+		"equal": {
+			Expect: newRequest("PUT", "https://example.com/path?b=2&a=1", "body",
+				http.Header{"X-Foo": []string{"a", "b"}}),
+			Actual: newRequest("PUT", "https://example.com/path?a=1&b=2", "body",
+				http.Header{"X-Foo": []string{"a, b"}}),
+		},
+		"header mismatch": {
+			Expect: newRequest("GET", "https://example.com/", "",
+				http.Header{"X-Foo": []string{"bar"}, "X-Expected": []string{"1"}}),
+			Actual: newRequest("GET", "https://example.com/", "",
+				http.Header{"X-Foo": []string{"baz"}, "X-Extra": []string{"2"}}),
+			ExpectDiff: strings.Join([]string{
+				`header X-Expected: expect "1", got none`,
+				`header X-Extra: expect none, got "2"`,
+				`header X-Foo: expect "bar", got "baz"`,
+			}, "\n"),
+		},
+		"volatile headers ignored": {
+			Expect: newRequest("GET", "https://example.com/", "",
+				http.Header{"Authorization": []string{"sig1"}, "X-Amz-Date": []string{"20220101T000000Z"}}),
+			Actual: newRequest("GET", "https://example.com/", "",
+				http.Header{"Authorization": []string{"sig2"}}),
+		},
+		"custom ignored headers": {
+			Expect: newRequest("GET", "https://example.com/", "",
+				http.Header{"Authorization": []string{"sig1"}, "X-Request-Id": []string{"1"}}),
+			Actual: newRequest("GET", "https://example.com/", "",
+				http.Header{"Authorization": []string{"sig2"}, "X-Request-Id": []string{"2"}}),
+			Options: func(o *DiffRequestsOptions) {
+				o.IgnoreHeaders = []string{"x-request-id"}
+			},
+			ExpectDiff: `header Authorization: expect "sig1", got "sig2"`,
+		},
+		"method url and body": {
+			Expect: newRequest("PUT", "https://example.com/a?x=1", `{"a":1}`, nil),
+			Actual: newRequest("POST", "http://example.org/b?x=2", `{"a":2}`, nil),
+			ExpectDiff: strings.Join([]string{
+				`method: expect "PUT", got "POST"`,
+				`URL scheme: expect "https", got "http"`,
+				`URL host: expect "example.com", got "example.org"`,
+				`URL path: expect "/a", got "/b"`,
+				`URL query: expect "x=1", got "x=2"`,
+				`body: expect "{\"a\":1}", got "{\"a\":2}"`,
+			}, "\n"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var optFns []func(*DiffRequestsOptions)
+			if c.Options != nil {
+				optFns = append(optFns, c.Options)
+			}
+
+			if e, a := c.ExpectDiff, DiffRequests(c.Expect, c.Actual, optFns...); e != a {
+				t.Errorf("expect diff\n%s\ngot\n%s", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// SerializeTest invokes the serialize middleware with the input in a stack
+// containing only that middleware, and returns the HTTP request the
+// middleware serialized. The request is captured by the stack's handler
+// without being sent.
+//
+// Returns an error if the middleware failed, or did not invoke the next
+// handler.
+func SerializeTest(m middleware.SerializeMiddleware, input interface{}) (*http.Request, error) {
+	stack := middleware.NewStack("SerializeTest", smithyhttp.NewStackRequest)
+	if err := stack.Serialize.Add(m, middleware.After); err != nil {
+		return nil, fmt.Errorf("failed to add serialize middleware, %w", err)
+	}
+
+	var captured *http.Request
+	handler := middleware.HandlerFunc(func(ctx context.Context, in interface{}) (
+		out interface{}, metadata middleware.Metadata, err error,
+	) {
+		req, ok := in.(*smithyhttp.Request)
+		if !ok {
+			return nil, metadata, fmt.Errorf("expect smithy-go HTTP Request, got %T", in)
+		}
+		captured = req.Build(ctx)
+
+		return &smithyhttp.Response{
+			Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+			},
+		}, metadata, nil
+	})
+
+	_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), input)
+	if err != nil {
+		return captured, err
+	}
+	if captured == nil {
+		return nil, fmt.Errorf("serialize middleware did not invoke handler with request")
+	}
+
+	return captured, nil
+}
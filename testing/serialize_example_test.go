@@ -0,0 +1,44 @@
+package testing_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/smithy-go/middleware"
+	smithytesting "github.com/aws/smithy-go/testing"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func ExampleSerializeTest() {
+	type Input struct {
+		FooName  string
+		BarCount int
+	}
+
+	serialize := middleware.SerializeMiddlewareFunc("OperationSerializer",
+		func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+			middleware.SerializeOutput, middleware.Metadata, error,
+		) {
+			req := in.Request.(*smithyhttp.Request)
+			input := in.Parameters.(*Input)
+
+			req.Header.Set("foo-name", input.FooName)
+			req.Header.Set("bar-count", strconv.Itoa(input.BarCount))
+
+			return next.HandleSerialize(ctx, in)
+		})
+
+	req, err := smithytesting.SerializeTest(serialize, &Input{FooName: "abc", BarCount: 123})
+	if err != nil {
+		fmt.Println("serialize failed,", err)
+		return
+	}
+
+	fmt.Println("foo-name", req.Header.Get("foo-name"))
+	fmt.Println("bar-count", req.Header.Get("bar-count"))
+
+	// Output:
+	// foo-name abc
+	// bar-count 123
+}
@@ -0,0 +1,117 @@
+package testing
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+var errSmokeTestRequestCaptured = stderrors.New("smoke test request captured, not sent")
+
+// SmokeTest invokes the stack with the input, capturing the HTTP request the
+// stack would send without sending it. The captured request is validated to
+// be structurally sound: an absolute URL with a valid host, a valid method,
+// and headers without forbidden characters.
+//
+// The stack's handler is short-circuited with an error in place of sending
+// the request, so deserialize middleware will not attempt to decode a
+// response. Returns the captured request, and any validation errors found. A
+// non-nil error is returned if the stack failed before the request was
+// captured.
+func SmokeTest(stack *middleware.Stack, input interface{}) (*http.Request, []error, error) {
+	var captured *http.Request
+
+	handler := middleware.HandlerFunc(func(ctx context.Context, in interface{}) (
+		out interface{}, metadata middleware.Metadata, err error,
+	) {
+		req, ok := in.(*smithyhttp.Request)
+		if !ok {
+			return nil, metadata, fmt.Errorf("expect smithy-go HTTP Request, got %T", in)
+		}
+		captured = req.Build(ctx)
+		return nil, metadata, errSmokeTestRequestCaptured
+	})
+
+	_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), input)
+	if captured == nil {
+		if err == nil {
+			err = fmt.Errorf("stack did not invoke handler with request")
+		}
+		return nil, nil, err
+	}
+	if err != nil && !stderrors.Is(err, errSmokeTestRequestCaptured) {
+		return captured, nil, err
+	}
+
+	return captured, validateSmokeTestRequest(captured), nil
+}
+
+func validateSmokeTestRequest(req *http.Request) []error {
+	var errs []error
+
+	if !isHTTPToken(req.Method) {
+		errs = append(errs, fmt.Errorf("invalid method %q", req.Method))
+	}
+
+	if req.URL == nil {
+		return append(errs, fmt.Errorf("missing URL"))
+	}
+	if scheme := strings.ToLower(req.URL.Scheme); scheme != "http" && scheme != "https" {
+		errs = append(errs, fmt.Errorf("expect absolute http or https URL, got scheme %q", req.URL.Scheme))
+	}
+	if len(req.URL.Host) == 0 {
+		errs = append(errs, fmt.Errorf("expect absolute URL, got no host"))
+	} else if err := smithyhttp.ValidateEndpointHost(req.URL.Host); err != nil {
+		errs = append(errs, err)
+	}
+	if hasControlChar(req.URL.Path) || hasControlChar(req.URL.RawPath) {
+		errs = append(errs, fmt.Errorf("invalid character in path %q", req.URL.Path))
+	}
+	if hasControlChar(req.URL.RawQuery) || strings.ContainsRune(req.URL.RawQuery, ' ') {
+		errs = append(errs, fmt.Errorf("invalid character in query %q", req.URL.RawQuery))
+	}
+
+	for key, values := range req.Header {
+		if !isHTTPToken(key) {
+			errs = append(errs, fmt.Errorf("invalid header name %q", key))
+		}
+		for _, v := range values {
+			if hasControlChar(strings.ReplaceAll(v, "\t", "")) {
+				errs = append(errs, fmt.Errorf("invalid character in %v header value %q", key, v))
+			}
+		}
+	}
+
+	return errs
+}
+
+// isHTTPToken returns if the value is a non-empty RFC 7230 token.
+func isHTTPToken(v string) bool {
+	if len(v) == 0 {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func hasControlChar(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if v[i] < 0x20 || v[i] == 0x7f {
+			return true
+		}
+	}
+	return false
+}
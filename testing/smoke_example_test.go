@@ -0,0 +1,50 @@
+package testing_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+	smithytesting "github.com/aws/smithy-go/testing"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func ExampleSmokeTest() {
+	type Input struct {
+		Bucket string
+	}
+
+	stack := middleware.NewStack("smoke test example", smithyhttp.NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("OperationSerializer",
+		func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+			middleware.SerializeOutput, middleware.Metadata, error,
+		) {
+			req := in.Request.(*smithyhttp.Request)
+			input := in.Parameters.(*Input)
+
+			req.Method = "GET"
+			req.URL.Scheme = "https"
+			req.URL.Host = "example.amazonaws.com"
+			req.URL.Path = "/" + input.Bucket
+			req.Header.Set("X-Bucket", input.Bucket+"\n")
+
+			return next.HandleSerialize(ctx, in)
+		}),
+		middleware.After,
+	)
+
+	req, validationErrs, err := smithytesting.SmokeTest(stack, &Input{})
+	if err != nil {
+		fmt.Println("stack failed,", err)
+		return
+	}
+
+	fmt.Println(req.Method, req.URL.String())
+	for _, err := range validationErrs {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// GET https://example.amazonaws.com/
+	// invalid character in X-Bucket header value "\n"
+}
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// CharsetDecoder returns a reader decoding the text read from r, encoded in a
+// charset, to UTF-8.
+type CharsetDecoder func(r io.Reader) io.Reader
+
+var charsetDecoders = struct {
+	mu       sync.RWMutex
+	decoders map[string]CharsetDecoder
+}{
+	decoders: map[string]CharsetDecoder{
+		"utf-8":        nopCharsetDecoder,
+		"us-ascii":     nopCharsetDecoder,
+		"iso-8859-1":   newLatin1Decoder,
+		"latin1":       newLatin1Decoder,
+		"windows-1252": newWindows1252Decoder,
+	},
+}
+
+// RegisterCharsetDecoder registers the decoder for the charset name. Charset
+// names are case-insensitive. Replaces any decoder already registered for the
+// charset.
+//
+// UTF-8, US-ASCII, ISO-8859-1, and Windows-1252 decoders are registered by
+// default.
+func RegisterCharsetDecoder(charset string, decoder CharsetDecoder) {
+	charsetDecoders.mu.Lock()
+	defer charsetDecoders.mu.Unlock()
+
+	charsetDecoders.decoders[strings.ToLower(charset)] = decoder
+}
+
+// GetCharsetDecoder returns the decoder registered for the charset name, and
+// if a decoder was found.
+func GetCharsetDecoder(charset string) (CharsetDecoder, bool) {
+	charsetDecoders.mu.RLock()
+	defer charsetDecoders.mu.RUnlock()
+
+	decoder, ok := charsetDecoders.decoders[strings.ToLower(charset)]
+	return decoder, ok
+}
+
+// DecodeResponseCharset wraps the response's body with a reader decoding the
+// body to UTF-8, from the charset parameter of the response's Content-Type
+// header. The body is not modified if the charset is not specified, or is
+// UTF-8.
+//
+// Returns an error if there is no decoder registered for the charset.
+func DecodeResponseCharset(resp *Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	if len(contentType) == 0 || resp.Body == nil {
+		return nil
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("failed to parse response content type, %w", err)
+	}
+
+	charset, ok := params["charset"]
+	if !ok || strings.EqualFold(charset, "utf-8") {
+		return nil
+	}
+
+	decoder, ok := GetCharsetDecoder(charset)
+	if !ok {
+		return fmt.Errorf("unsupported response charset %q", charset)
+	}
+
+	resp.Body = &decodedBody{
+		Reader: decoder(resp.Body),
+		Closer: resp.Body,
+	}
+	return nil
+}
+
+// AddCharsetDecoderMiddleware adds the middleware to decode the response
+// body to UTF-8, see DecodeResponseCharset, to the end of the stack's
+// Deserialize step, so that the body is decoded before being deserialized.
+func AddCharsetDecoderMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(&charsetDecoder{}, middleware.After)
+}
+
+type charsetDecoder struct{}
+
+// ID returns the middleware identifier.
+func (*charsetDecoder) ID() string { return "CharsetDecoder" }
+
+// HandleDeserialize decodes the raw response's body to UTF-8.
+func (*charsetDecoder) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+
+	if err := DecodeResponseCharset(resp); err != nil {
+		return out, metadata, &ResponseError{Response: resp, Err: err}
+	}
+
+	return out, metadata, nil
+}
+
+type decodedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func nopCharsetDecoder(r io.Reader) io.Reader { return r }
+
+func newLatin1Decoder(r io.Reader) io.Reader {
+	return &singleByteDecoder{r: r, table: latin1Table()}
+}
+
+func newWindows1252Decoder(r io.Reader) io.Reader {
+	table := latin1Table()
+	for i, c := range windows1252High {
+		table[0x80+i] = c
+	}
+	return &singleByteDecoder{r: r, table: table}
+}
+
+func latin1Table() *[256]rune {
+	var table [256]rune
+	for i := range table {
+		table[i] = rune(i)
+	}
+	return &table
+}
+
+// windows1252High is the Windows-1252 mapping of bytes 0x80 through 0x9F,
+// which differ from ISO-8859-1.
+var windows1252High = [32]rune{
+	'€', utf8.RuneError, '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', utf8.RuneError, 'Ž', utf8.RuneError,
+	utf8.RuneError, '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', utf8.RuneError, 'ž', 'Ÿ',
+}
+
+// singleByteDecoder decodes a single byte charset to UTF-8 using a table
+// mapping each byte to its rune.
+type singleByteDecoder struct {
+	r     io.Reader
+	table *[256]rune
+
+	in      []byte
+	pending []byte
+}
+
+func (d *singleByteDecoder) Read(p []byte) (n int, err error) {
+	if len(d.pending) == 0 {
+		if cap(d.in) == 0 {
+			d.in = make([]byte, 4096)
+		}
+
+		var m int
+		m, err = d.r.Read(d.in[:cap(d.in)])
+		for _, b := range d.in[:m] {
+			d.pending = appendRune(d.pending, d.table[b])
+		}
+	}
+
+	n = copy(p, d.pending)
+	d.pending = d.pending[n:]
+	if len(d.pending) != 0 && err == io.EOF {
+		// Defer EOF until the pending decoded bytes are read.
+		err = nil
+	}
+	return n, err
+}
+
+func appendRune(b []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeResponseCharset(t *testing.T) {
+	cases := map[string]struct {
+		ContentType string
+		Body        []byte
+		Expect      string
+		ExpectErr   bool
+	}{
+		"no content type": {
+			Body:   []byte("caf\xc3\xa9"),
+			Expect: "café",
+		},
+		"no charset": {
+			ContentType: "text/plain",
+			Body:        []byte("caf\xc3\xa9"),
+			Expect:      "café",
+		},
+		"utf-8": {
+			ContentType: "text/plain; charset=UTF-8",
+			Body:        []byte("caf\xc3\xa9"),
+			Expect:      "café",
+		},
+		"latin-1": {
+			ContentType: "text/xml; charset=ISO-8859-1",
+			Body:        []byte("<a>caf\xe9 \xbd \xff</a>"),
+			Expect:      "<a>café ½ ÿ</a>",
+		},
+		"windows-1252": {
+			ContentType: `text/plain; charset="windows-1252"`,
+			Body:        []byte("\x80 \x93quoted\x94"),
+			Expect:      "€ “quoted”",
+		},
+		"unsupported charset": {
+			ContentType: "text/plain; charset=shift_jis",
+			Body:        []byte("abc"),
+			ExpectErr:   true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := &Response{
+				Response: &http.Response{
+					Header: http.Header{},
+					Body:   ioutil.NopCloser(bytes.NewReader(c.Body)),
+				},
+			}
+			if len(c.ContentType) != 0 {
+				resp.Header.Set("Content-Type", c.ContentType)
+			}
+
+			err := DecodeResponseCharset(resp)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Expect, string(body); e != a {
+				t.Errorf("expect %q body, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestLatin1Decoder_smallReads(t *testing.T) {
+	r := newLatin1Decoder(iotest.DataErrReader(strings.NewReader("\xe9\xe9\xe9")))
+
+	var out bytes.Buffer
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		out.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+
+	if e, a := "ééé", out.String(); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
+
+func TestRegisterCharsetDecoder(t *testing.T) {
+	RegisterCharsetDecoder("X-Upper", func(r io.Reader) io.Reader {
+		b, _ := ioutil.ReadAll(r)
+		return strings.NewReader(strings.ToUpper(string(b)))
+	})
+
+	resp := &Response{
+		Response: &http.Response{
+			Header: http.Header{"Content-Type": []string{"text/plain; charset=x-upper"}},
+			Body:   ioutil.NopCloser(strings.NewReader("abc")),
+		},
+	}
+	if err := DecodeResponseCharset(resp); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "ABC", string(body); e != a {
+		t.Errorf("expect %q body, got %q", e, a)
+	}
+}
@@ -34,7 +34,7 @@ func TestChecksumMiddleware(t *testing.T) {
 		},
 		"nil body": {},
 		"unseekable payload": {
-			payload:     bytes.NewBuffer([]byte(`xyz`)),
+			payload:     unseekableBuffer{bytes.NewBuffer([]byte(`xyz`))},
 			expectError: "unseekable stream is not supported",
 		},
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/middleware"
@@ -27,6 +28,11 @@ func (fn ClientDoFunc) Do(r *http.Request) (*http.Response, error) {
 // implementation is http.Client.
 type ClientHandler struct {
 	client ClientDo
+
+	shutdown *shutdownTracker
+
+	bodyReadTimeout  time.Duration
+	uploadBufferSize int
 }
 
 // NewClientHandler returns an initialized middleware handler for the client.
@@ -47,11 +53,25 @@ func (c ClientHandler) Handle(ctx context.Context, input interface{}) (
 		return nil, metadata, fmt.Errorf("expect Smithy http.Request value as input, got unsupported type %T", input)
 	}
 
+	if c.shutdown != nil {
+		if !c.shutdown.begin() {
+			return nil, metadata, &ShuttingDownError{}
+		}
+		defer c.shutdown.done()
+	}
+
 	builtRequest := req.Build(ctx)
 	if err := ValidateEndpointHost(builtRequest.Host); err != nil {
 		return nil, metadata, err
 	}
 
+	if c.uploadBufferSize > 0 && builtRequest.Body != nil {
+		builtRequest.Body = &uploadBufferBody{
+			ReadCloser: builtRequest.Body,
+			size:       c.uploadBufferSize,
+		}
+	}
+
 	resp, err := c.client.Do(builtRequest)
 	if resp == nil {
 		// Ensure a http response value is always present to prevent unexpected
@@ -70,6 +90,8 @@ func (c ClientHandler) Handle(ctx context.Context, input interface{}) (
 			err = &smithy.CanceledError{Err: ctx.Err()}
 		default:
 		}
+	} else if c.bodyReadTimeout > 0 && resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = newTimeoutReadCloser(resp.Body, c.bodyReadTimeout)
 	}
 
 	// HTTP RoundTripper *should* close the request body. But this may not happen in a timely manner.
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// WithBodyReadTimeout returns a copy of the client handler that bounds each
+// read of a response body by the timeout. If a read makes no progress before
+// the timeout, the body is closed, and the read fails with a
+// BodyReadTimeoutError.
+//
+// Unlike a timeout on the whole request, the body read timeout does not fail
+// large responses that are slow but still progressing, only responses whose
+// stream has stalled. The time between reads, (e.g. while the caller is
+// processing the data already read), does not count towards the timeout. A
+// zero timeout disables the body read timeout.
+func (c ClientHandler) WithBodyReadTimeout(timeout time.Duration) ClientHandler {
+	c.bodyReadTimeout = timeout
+	return c
+}
+
+// BodyReadTimeoutError is the error returned when a read of a response body
+// made no progress before the client handler's body read timeout.
+type BodyReadTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *BodyReadTimeoutError) Error() string {
+	return fmt.Sprintf("response body read timed out, no progress after %v", e.Timeout)
+}
+
+// timeoutReadCloser closes the body if a read does not complete before the
+// timeout, unblocking the read.
+type timeoutReadCloser struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+
+	mu       sync.Mutex
+	reading  bool
+	deadline time.Time
+	timedOut bool
+}
+
+func newTimeoutReadCloser(body io.ReadCloser, timeout time.Duration) *timeoutReadCloser {
+	r := &timeoutReadCloser{
+		body:    body,
+		timeout: timeout,
+	}
+	r.timer = time.AfterFunc(timeout, r.expire)
+	r.timer.Stop()
+	return r
+}
+
+func (r *timeoutReadCloser) expire() {
+	r.mu.Lock()
+	// The timer may fire as a read completes, or fire for a previous read
+	// after the timer was reset for the next read. Neither times out the
+	// body, as the read made progress.
+	if !r.reading || time.Now().Before(r.deadline) {
+		r.mu.Unlock()
+		return
+	}
+	r.timedOut = true
+	r.mu.Unlock()
+
+	r.body.Close()
+}
+
+// startRead starts the timeout of a read, returning false if the body has
+// already timed out.
+func (r *timeoutReadCloser) startRead() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timedOut {
+		return false
+	}
+	r.reading = true
+	r.deadline = time.Now().Add(r.timeout)
+	r.timer.Reset(r.timeout)
+	return true
+}
+
+// endRead stops the timeout of a read, returning if the read timed out.
+func (r *timeoutReadCloser) endRead() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reading = false
+	r.timer.Stop()
+	return r.timedOut
+}
+
+func (r *timeoutReadCloser) isTimedOut() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.timedOut
+}
+
+func (r *timeoutReadCloser) Read(p []byte) (int, error) {
+	if !r.startRead() {
+		return 0, &BodyReadTimeoutError{Timeout: r.timeout}
+	}
+
+	n, err := r.body.Read(p)
+	timedOut := r.endRead()
+
+	if err != nil && err != io.EOF && timedOut {
+		return n, &BodyReadTimeoutError{Timeout: r.timeout}
+	}
+	return n, err
+}
+
+func (r *timeoutReadCloser) Close() error {
+	r.timer.Stop()
+	if r.isTimedOut() {
+		return nil
+	}
+	return r.body.Close()
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientHandler_BodyReadTimeout(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+
+	cases := map[string]struct {
+		Handler    func(w http.ResponseWriter, stop <-chan struct{})
+		ExpectBody string
+		ExpectErr  bool
+	}{
+		"slow but progressing": {
+			Handler: func(w http.ResponseWriter, stop <-chan struct{}) {
+				for i := 0; i < 10; i++ {
+					w.Write([]byte("a"))
+					w.(http.Flusher).Flush()
+					time.Sleep(timeout / 5)
+				}
+			},
+			ExpectBody: strings.Repeat("a", 10),
+		},
+		"stalled": {
+			Handler: func(w http.ResponseWriter, stop <-chan struct{}) {
+				w.Write([]byte("abc"))
+				w.(http.Flusher).Flush()
+				<-stop
+			},
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stop := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.Handler(w, stop)
+			}))
+			defer server.Close()
+			defer close(stop)
+
+			handler, err := NewClientHandlerWithOptions(func(o *ClientHandlerOptions) {
+				o.BodyReadTimeout = timeout
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			req := NewStackRequest().(*Request)
+			req.URL.Scheme = "http"
+			req.URL.Host = server.Listener.Addr().String()
+			req.Method = http.MethodGet
+
+			result, _, err := handler.Handle(context.Background(), req)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			resp := result.(*Response)
+			defer resp.Body.Close()
+
+			start := time.Now()
+			body, err := ioutil.ReadAll(resp.Body)
+			if c.ExpectErr {
+				var timeoutErr *BodyReadTimeoutError
+				if !errors.As(err, &timeoutErr) {
+					t.Fatalf("expect %T error, got %v", timeoutErr, err)
+				}
+				if elapsed := time.Since(start); elapsed > 10*timeout {
+					t.Errorf("expect stalled read to time out, took %v", elapsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ExpectBody, string(body); e != a {
+				t.Errorf("expect %q body, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestTimeoutReadCloser_ExpireAfterRead(t *testing.T) {
+	const timeout = 10 * time.Millisecond
+
+	body := &closeTrackingReader{Reader: strings.NewReader("abc")}
+	r := newTimeoutReadCloser(body, timeout)
+	defer r.Close()
+
+	p := make([]byte, 1)
+	if _, err := r.Read(p); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	// The timer fires as the read completes, after the read's deadline.
+	time.Sleep(timeout)
+	r.expire()
+
+	if body.closed {
+		t.Fatalf("expect body not closed after successful read")
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "bc", string(b); e != a {
+		t.Errorf("expect %q body, got %q", e, a)
+	}
+}
+
+type closeTrackingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeTrackingReader) Close() error {
+	r.closed = true
+	return nil
+}
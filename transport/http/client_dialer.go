@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DialContextFunc provides the signature of the function a transport dials
+// connections with.
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// newDialer returns a dialer with the same timeouts as http.DefaultTransport,
+// using the resolver.
+func newDialer(resolver *net.Resolver) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Resolver:  resolver,
+	}
+}
+
+// PinnedDialContext returns a DialContextFunc that dials the pinned address
+// of a host instead of resolving the host's name. Addresses are keyed by
+// "host:port", and may include a port, (e.g. "10.0.0.1" or "10.0.0.1:8443").
+// Hosts without a pinned address are dialed with the dialer as is. If the
+// dialer is nil, a dialer with the same timeouts as http.DefaultTransport is
+// used.
+//
+// Only the dialed address is changed. The request's URL is unmodified, so the
+// Host header, and the server name used for TLS SNI and certificate
+// verification, remain the endpoint's host name.
+func PinnedDialContext(addresses map[string]string, dialer *net.Dialer) DialContextFunc {
+	if dialer == nil {
+		dialer = newDialer(nil)
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		pinned, ok := addresses[address]
+		if !ok {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		if _, _, err := net.SplitHostPort(pinned); err != nil {
+			_, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, fmt.Errorf("invalid dial address %s, %w", address, err)
+			}
+			pinned = net.JoinHostPort(pinned, port)
+		}
+
+		return dialer.DialContext(ctx, network, pinned)
+	}
+}
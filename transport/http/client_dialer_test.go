@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPinnedDialContext(t *testing.T) {
+	var gotHost, gotServerName string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		gotServerName = r.TLS.ServerName
+	}))
+	defer server.Close()
+
+	serverAddr := server.Listener.Addr().(*net.TCPAddr)
+	_, port, _ := net.SplitHostPort(serverAddr.String())
+
+	// The test server's certificate is valid for example.com.
+	endpoint := "example.com:" + port
+
+	cases := map[string]struct {
+		Addresses map[string]string
+	}{
+		"pinned ip": {
+			Addresses: map[string]string{endpoint: serverAddr.IP.String()},
+		},
+		"pinned ip and port": {
+			Addresses: map[string]string{endpoint: serverAddr.String()},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			gotHost, gotServerName = "", ""
+
+			transport := server.Client().Transport.(*http.Transport).Clone()
+			handler, err := NewClientHandlerWithOptions(func(o *ClientHandlerOptions) {
+				o.Transport = transport
+				o.DialContext = PinnedDialContext(c.Addresses, nil)
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			req := NewStackRequest().(*Request)
+			req.URL.Scheme = "https"
+			req.URL.Host = endpoint
+			req.Method = http.MethodGet
+
+			result, _, err := handler.Handle(context.Background(), req)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			resp := result.(*Response)
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if e, a := endpoint, gotHost; e != a {
+				t.Errorf("expect %v host, got %v", e, a)
+			}
+			if e, a := "example.com", gotServerName; e != a {
+				t.Errorf("expect %v server name, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestPinnedDialContext_NotPinned(t *testing.T) {
+	dial := PinnedDialContext(map[string]string{
+		"example.com:443": "127.0.0.1",
+	}, nil)
+
+	_, err := dial(context.Background(), "tcp", "invalid address")
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := "invalid address", err.Error(); !strings.Contains(a, e) {
+		t.Errorf("expect error to contain %q, got %q", e, a)
+	}
+}
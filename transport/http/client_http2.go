@@ -0,0 +1,192 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTP2Options provides the HTTP/2 connection settings of a client's
+// transport. Zero values use the HTTP/2 implementation's defaults.
+type HTTP2Options struct {
+	// The maximum number of concurrent streams the client will open per
+	// connection, in addition to the limit advertised by the server.
+	// Requires an HTTP2Configurer.
+	MaxConcurrentStreams uint32
+
+	// The initial flow control window size of each stream, in bytes. Larger
+	// windows increase the throughput of streaming responses over high
+	// latency connections.
+	InitialWindowSize int32
+
+	// The duration without receiving any frames after which a health check
+	// ping frame is sent on the connection.
+	ReadIdleTimeout time.Duration
+
+	// The duration without a response to the health check ping after which
+	// the connection is closed.
+	PingTimeout time.Duration
+}
+
+// HTTP2Configurer provides the interface for applying HTTP/2 settings to a
+// transport with an external HTTP/2 implementation, (e.g.
+// golang.org/x/net/http2 ConfigureTransports). Without a configurer, the
+// settings are applied to the standard library's bundled HTTP/2
+// implementation, which is only configurable with Go 1.24 or later, and does
+// not support MaxConcurrentStreams.
+type HTTP2Configurer interface {
+	ConfigureHTTP2(*http.Transport, HTTP2Options) error
+}
+
+// HTTP2ConfigurerFunc provides a wrapper around a function to be used as an
+// HTTP2Configurer.
+type HTTP2ConfigurerFunc func(*http.Transport, HTTP2Options) error
+
+// ConfigureHTTP2 invokes the wrapped function.
+func (fn HTTP2ConfigurerFunc) ConfigureHTTP2(t *http.Transport, o HTTP2Options) error {
+	return fn(t, o)
+}
+
+// ClientHandlerOptions provides the options for building the HTTP client of a
+// ClientHandler.
+type ClientHandlerOptions struct {
+	// The transport of the client. If nil, a clone of http.DefaultTransport
+	// is used. The transport is modified when the client is built.
+	Transport *http.Transport
+
+	// The HTTP/2 settings of the transport. Applied to the transport's
+	// bundled HTTP/2 implementation if HTTP2Configurer is not set, which
+	// requires Go 1.24 or later, and does not support MaxConcurrentStreams.
+	HTTP2 *HTTP2Options
+
+	// Applies the HTTP/2 settings to the transport, (e.g. with
+	// golang.org/x/net/http2). If nil, the settings are applied to the
+	// transport's bundled HTTP/2 implementation.
+	HTTP2Configurer HTTP2Configurer
+
+	// The maximum number of idle connections kept across all hosts. Idle
+	// connections are reused by subsequent requests, avoiding the cost of
+	// dialing, and the TLS handshake. If zero, the transport's value is used,
+	// which is 100 for the default transport.
+	MaxIdleConns int
+
+	// The maximum number of idle connections kept per host. Should be
+	// increased for workloads sending many concurrent requests to the same
+	// host, otherwise connections beyond the limit are closed when idle, and
+	// redialed by the next burst of requests. If zero, the transport's value
+	// is used. The default transport's limit is http.DefaultMaxIdleConnsPerHost,
+	// 2.
+	MaxIdleConnsPerHost int
+
+	// The maximum number of connections per host, including connections that
+	// are dialing, active, and idle. Requests wait for a connection once the
+	// limit is reached. If zero, the transport's value is used, which is no
+	// limit for the default transport.
+	MaxConnsPerHost int
+
+	// The duration an idle connection is kept before it is closed. Should be
+	// less than the service's idle timeout, so the client does not reuse
+	// connections the service is closing. If zero, the transport's value is
+	// used, which is 90 seconds for the default transport.
+	IdleConnTimeout time.Duration
+
+	// The size, in bytes, of the buffer request bodies are read into when
+	// sent, see ClientHandler.WithUploadBufferSize, and of the transport's
+	// write buffer, (http.Transport.WriteBufferSize). If zero, the HTTP
+	// client's defaults are used.
+	UploadBufferSize int
+
+	// The maximum duration a read of a response body may make no progress
+	// before the read fails, see ClientHandler.WithBodyReadTimeout. Unlike
+	// the transport's dial, and TLS handshake timeouts, which bound
+	// establishing a connection, the body read timeout bounds the response
+	// stream. If zero, body reads are not bounded.
+	BodyReadTimeout time.Duration
+
+	// The function the transport dials connections with, (e.g.
+	// PinnedDialContext). If set, Resolver is ignored.
+	DialContext DialContextFunc
+
+	// The resolver used to look up the addresses of endpoint hosts, (e.g. a
+	// service mesh resolver). Ignored if DialContext is set.
+	Resolver *net.Resolver
+
+	// The cookie jar of the client. Cookies from the jar are sent with
+	// requests, and cookies set by responses are stored in the jar, keyed by
+	// the request's URL. If nil, cookies are not sent or stored.
+	Jar http.CookieJar
+
+	// Sets if the client returns redirect responses instead of following
+	// them. Required for redirects to be followed by the stack, see
+	// AddRedirectMiddleware, so that each redirected request is signed for
+	// its target.
+	DisableRedirects bool
+}
+
+// NewClientHandlerWithOptions returns an initialized ClientHandler with an
+// HTTP client built from the options.
+//
+// The HTTP/2 settings only apply to connections that negotiate HTTP/2, (e.g.
+// HTTPS endpoints supporting HTTP/2 via ALPN). Requests over connections that
+// fall back to HTTP/1.1, or over plain-text HTTP, are sent with the
+// transport's HTTP/1.1 settings, and the HTTP/2 settings are ignored.
+func NewClientHandlerWithOptions(optFns ...func(*ClientHandlerOptions)) (ClientHandler, error) {
+	var options ClientHandlerOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	transport := options.Transport
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	if options.MaxIdleConns != 0 {
+		transport.MaxIdleConns = options.MaxIdleConns
+	}
+	if options.MaxIdleConnsPerHost != 0 {
+		transport.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+	}
+	if options.MaxConnsPerHost != 0 {
+		transport.MaxConnsPerHost = options.MaxConnsPerHost
+	}
+	if options.IdleConnTimeout != 0 {
+		transport.IdleConnTimeout = options.IdleConnTimeout
+	}
+	if options.UploadBufferSize != 0 {
+		transport.WriteBufferSize = options.UploadBufferSize
+	}
+
+	if options.DialContext != nil {
+		transport.DialContext = options.DialContext
+	} else if options.Resolver != nil {
+		transport.DialContext = newDialer(options.Resolver).DialContext
+	}
+
+	if options.HTTP2 != nil {
+		configurer := options.HTTP2Configurer
+		if configurer == nil {
+			configurer = HTTP2ConfigurerFunc(configureBundledHTTP2)
+		}
+
+		transport.ForceAttemptHTTP2 = true
+		if err := configurer.ConfigureHTTP2(transport, *options.HTTP2); err != nil {
+			return ClientHandler{}, fmt.Errorf("failed to configure HTTP/2 transport, %w", err)
+		}
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Jar:       options.Jar,
+	}
+	if options.DisableRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return NewClientHandler(client).
+		WithBodyReadTimeout(options.BodyReadTimeout).
+		WithUploadBufferSize(options.UploadBufferSize), nil
+}
@@ -0,0 +1,37 @@
+//go:build go1.24
+// +build go1.24
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// configureBundledHTTP2 applies the HTTP/2 settings to the transport's
+// bundled HTTP/2 implementation, via the transport's HTTP2 config. The
+// bundled implementation does not limit the number of concurrent streams the
+// client opens beyond the server's limit, so MaxConcurrentStreams requires an
+// HTTP2Configurer.
+func configureBundledHTTP2(t *http.Transport, o HTTP2Options) error {
+	if o.MaxConcurrentStreams != 0 {
+		return fmt.Errorf("HTTP/2 configurer is required to apply HTTP/2 MaxConcurrentStreams")
+	}
+
+	config := &http.HTTP2Config{}
+	if t.HTTP2 != nil {
+		*config = *t.HTTP2
+	}
+	if o.InitialWindowSize != 0 {
+		config.MaxReceiveBufferPerStream = int(o.InitialWindowSize)
+	}
+	if o.ReadIdleTimeout != 0 {
+		config.SendPingTimeout = o.ReadIdleTimeout
+	}
+	if o.PingTimeout != 0 {
+		config.PingTimeout = o.PingTimeout
+	}
+	t.HTTP2 = config
+
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !go1.24
+// +build !go1.24
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// configureBundledHTTP2 returns an error, as the settings of the transport's
+// bundled HTTP/2 implementation cannot be configured before Go 1.24.
+func configureBundledHTTP2(*http.Transport, HTTP2Options) error {
+	return fmt.Errorf("HTTP/2 configurer is required to apply HTTP/2 options")
+}
@@ -0,0 +1,42 @@
+//go:build go1.24
+// +build go1.24
+
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewClientHandlerWithOptions_BundledHTTP2(t *testing.T) {
+	handler, err := NewClientHandlerWithOptions(func(o *ClientHandlerOptions) {
+		o.HTTP2 = &HTTP2Options{
+			InitialWindowSize: 4 << 20,
+			ReadIdleTimeout:   30 * time.Second,
+			PingTimeout:       15 * time.Second,
+		}
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	transport := handler.client.(*http.Client).Transport.(*http.Transport)
+	if !transport.ForceAttemptHTTP2 {
+		t.Errorf("expect force attempt HTTP/2")
+	}
+	if transport.HTTP2 == nil {
+		t.Fatalf("expect HTTP/2 config, got none")
+	}
+
+	expect := http.HTTP2Config{
+		MaxReceiveBufferPerStream: 4 << 20,
+		SendPingTimeout:           30 * time.Second,
+		PingTimeout:               15 * time.Second,
+	}
+	if diff := cmp.Diff(expect, *transport.HTTP2); len(diff) != 0 {
+		t.Errorf("expect HTTP/2 config to match\n%s", diff)
+	}
+}
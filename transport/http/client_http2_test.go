@@ -0,0 +1,160 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewClientHandlerWithOptions(t *testing.T) {
+	http2Options := HTTP2Options{
+		MaxConcurrentStreams: 250,
+		InitialWindowSize:    4 << 20,
+		ReadIdleTimeout:      30 * time.Second,
+		PingTimeout:          15 * time.Second,
+	}
+
+	cases := map[string]struct {
+		Options     func(*ClientHandlerOptions, *[]HTTP2Options)
+		ExpectHTTP2 []HTTP2Options
+		ExpectForce bool
+		ExpectErr   bool
+	}{
+		"defaults": {
+			Options:     func(*ClientHandlerOptions, *[]HTTP2Options) {},
+			ExpectForce: true,
+		},
+		"http2 options": {
+			Options: func(o *ClientHandlerOptions, applied *[]HTTP2Options) {
+				o.HTTP2 = &http2Options
+				o.HTTP2Configurer = HTTP2ConfigurerFunc(func(t *http.Transport, v HTTP2Options) error {
+					*applied = append(*applied, v)
+					return nil
+				})
+			},
+			ExpectHTTP2: []HTTP2Options{http2Options},
+			ExpectForce: true,
+		},
+		"max concurrent streams without configurer": {
+			Options: func(o *ClientHandlerOptions, applied *[]HTTP2Options) {
+				o.HTTP2 = &http2Options
+			},
+			ExpectErr: true,
+		},
+		"configurer error": {
+			Options: func(o *ClientHandlerOptions, applied *[]HTTP2Options) {
+				o.HTTP2 = &http2Options
+				o.HTTP2Configurer = HTTP2ConfigurerFunc(func(*http.Transport, HTTP2Options) error {
+					return fmt.Errorf("configure error")
+				})
+			},
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var applied []HTTP2Options
+			handler, err := NewClientHandlerWithOptions(func(o *ClientHandlerOptions) {
+				c.Options(o, &applied)
+			})
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			transport := handler.client.(*http.Client).Transport.(*http.Transport)
+			if transport == http.DefaultTransport {
+				t.Errorf("expect transport to not be the default transport")
+			}
+			if e, a := c.ExpectForce, transport.ForceAttemptHTTP2; e != a {
+				t.Errorf("expect %v force attempt HTTP/2, got %v", e, a)
+			}
+			if diff := cmp.Diff(c.ExpectHTTP2, applied); len(diff) != 0 {
+				t.Errorf("expect HTTP/2 options to match\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewClientHandlerWithOptions_ConnectionPool(t *testing.T) {
+	type pool struct {
+		MaxIdleConns        int
+		MaxIdleConnsPerHost int
+		MaxConnsPerHost     int
+		IdleConnTimeout     time.Duration
+	}
+
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+
+	cases := map[string]struct {
+		Options func(*ClientHandlerOptions)
+		Expect  pool
+	}{
+		"defaults": {
+			Options: func(*ClientHandlerOptions) {},
+			Expect: pool{
+				MaxIdleConns:        defaultTransport.MaxIdleConns,
+				MaxIdleConnsPerHost: defaultTransport.MaxIdleConnsPerHost,
+				MaxConnsPerHost:     defaultTransport.MaxConnsPerHost,
+				IdleConnTimeout:     defaultTransport.IdleConnTimeout,
+			},
+		},
+		"all options": {
+			Options: func(o *ClientHandlerOptions) {
+				o.MaxIdleConns = 500
+				o.MaxIdleConnsPerHost = 100
+				o.MaxConnsPerHost = 200
+				o.IdleConnTimeout = 30 * time.Second
+			},
+			Expect: pool{
+				MaxIdleConns:        500,
+				MaxIdleConnsPerHost: 100,
+				MaxConnsPerHost:     200,
+				IdleConnTimeout:     30 * time.Second,
+			},
+		},
+		"custom transport": {
+			Options: func(o *ClientHandlerOptions) {
+				o.Transport = &http.Transport{
+					MaxIdleConns:    10,
+					IdleConnTimeout: time.Minute,
+				}
+				o.MaxIdleConnsPerHost = 5
+			},
+			Expect: pool{
+				MaxIdleConns:        10,
+				MaxIdleConnsPerHost: 5,
+				IdleConnTimeout:     time.Minute,
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			handler, err := NewClientHandlerWithOptions(c.Options)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			transport := handler.client.(*http.Client).Transport.(*http.Transport)
+			actual := pool{
+				MaxIdleConns:        transport.MaxIdleConns,
+				MaxIdleConnsPerHost: transport.MaxIdleConnsPerHost,
+				MaxConnsPerHost:     transport.MaxConnsPerHost,
+				IdleConnTimeout:     transport.IdleConnTimeout,
+			}
+			if diff := cmp.Diff(c.Expect, actual); len(diff) != 0 {
+				t.Errorf("expect connection pool options to match\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// WithGracefulShutdown returns a copy of the ClientHandler that tracks its
+// in-flight requests, so that the handler can be shut down with Shutdown.
+// Copies of the returned handler share the same shutdown state.
+func (c ClientHandler) WithGracefulShutdown() ClientHandler {
+	c.shutdown = &shutdownTracker{}
+	return c
+}
+
+// Shutdown stops the handler from sending new requests, and waits for the
+// in-flight requests to complete. Requests made after Shutdown is called fail
+// with a ShuttingDownError.
+//
+// Returns an error if the context is done before the in-flight requests
+// complete, or the handler was not created with WithGracefulShutdown.
+func (c ClientHandler) Shutdown(ctx context.Context) error {
+	if c.shutdown == nil {
+		return fmt.Errorf("client handler graceful shutdown not enabled")
+	}
+
+	return c.shutdown.shutdown(ctx)
+}
+
+// ShuttingDownError is returned by a ClientHandler for requests made after the
+// handler was shut down.
+type ShuttingDownError struct{}
+
+// RetryableError returns that the error is not retryable, as the handler will
+// not send any further requests.
+func (*ShuttingDownError) RetryableError() bool { return false }
+
+func (*ShuttingDownError) Error() string {
+	return "client handler is shutting down, request not sent"
+}
+
+type shutdownTracker struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight sync.WaitGroup
+}
+
+// begin tracks a new in-flight request, returning false if the handler is
+// shutting down.
+func (t *shutdownTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return false
+	}
+	t.inFlight.Add(1)
+	return true
+}
+
+func (t *shutdownTracker) done() {
+	t.inFlight.Done()
+}
+
+func (t *shutdownTracker) shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		t.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("in-flight requests did not complete before shutdown deadline, %w", ctx.Err())
+	}
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClientHandler_Shutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := NewClientHandler(ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/in-flight" {
+			close(started)
+			<-release
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}, nil
+	})).WithGracefulShutdown()
+
+	newRequest := func(path string) *Request {
+		req := NewStackRequest().(*Request)
+		req.URL.Scheme = "https"
+		req.URL.Host = "example.com"
+		req.URL.Path = path
+		return req
+	}
+
+	inFlightErr := make(chan error, 1)
+	go func() {
+		_, _, err := handler.Handle(context.Background(), newRequest("/in-flight"))
+		inFlightErr <- err
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- handler.Shutdown(context.Background())
+	}()
+
+	// Wait for the shutdown to begin, new requests are rejected.
+	var err error
+	for i := 0; i < 100; i++ {
+		if _, _, err = handler.Handle(context.Background(), newRequest("/new")); err != nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var shuttingDown *ShuttingDownError
+	if !errors.As(err, &shuttingDown) {
+		t.Fatalf("expect %T error, got %v", shuttingDown, err)
+	}
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("expect shutdown to wait for in-flight request, got %v", err)
+	default:
+	}
+
+	close(release)
+	if err := <-inFlightErr; err != nil {
+		t.Errorf("expect in-flight request to complete, got %v", err)
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("expect no shutdown error, got %v", err)
+	}
+}
+
+func TestClientHandler_ShutdownDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{})
+	handler := NewClientHandler(ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+		close(started)
+		<-release
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}, nil
+	})).WithGracefulShutdown()
+
+	req := NewStackRequest().(*Request)
+	req.URL.Host = "example.com"
+	go handler.Handle(context.Background(), req)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := handler.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect %v error, got %v", context.DeadlineExceeded, err)
+	}
+}
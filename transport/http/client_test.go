@@ -4,10 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
 )
 
 func TestClientHandler_Handle(t *testing.T) {
@@ -91,3 +97,100 @@ func TestClientHandler_Handle(t *testing.T) {
 	}
 
 }
+
+func TestClientHandler_RoundTrip(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Echo-Method", r.Method)
+		w.Header().Set("X-Echo-Query", r.URL.RawQuery)
+		w.WriteHeader(201)
+		w.Write(body)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	newStack := func(path string) *middleware.Stack {
+		stack := middleware.NewStack("stack", NewStackRequest)
+		stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+			func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+				middleware.SerializeOutput, middleware.Metadata, error,
+			) {
+				req := in.Request.(*Request)
+				u, _ := url.Parse(server.URL + path)
+				req.URL = u
+				req.Method = http.MethodPut
+				req.Query().Set("id", "1")
+				req, err := req.SetStream(strings.NewReader("hello"))
+				if err != nil {
+					return middleware.SerializeOutput{}, middleware.Metadata{}, err
+				}
+				req.ContentLength = 5
+				in.Request = req
+				return next.HandleSerialize(ctx, in)
+			}), middleware.After)
+		return stack
+	}
+
+	handler := NewClientHandler(server.Client())
+
+	t.Run("round trip", func(t *testing.T) {
+		var resp *Response
+		stack := newStack("/echo")
+		stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("captureResponse",
+			func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+				out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+			) {
+				out, metadata, err = next.HandleDeserialize(ctx, in)
+				resp, _ = out.RawResponse.(*Response)
+				return out, metadata, err
+			}), middleware.After)
+
+		_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if resp == nil {
+			t.Fatalf("expect response, got none")
+		}
+		defer resp.Body.Close()
+
+		if e, a := 201, resp.StatusCode; e != a {
+			t.Errorf("expect %v status code, got %v", e, a)
+		}
+		if e, a := http.MethodPut, resp.Header.Get("X-Echo-Method"); e != a {
+			t.Errorf("expect %v method, got %v", e, a)
+		}
+		if e, a := "id=1", resp.Header.Get("X-Echo-Query"); e != a {
+			t.Errorf("expect %v query, got %v", e, a)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := "hello", string(body); e != a {
+			t.Errorf("expect %q body, got %q", e, a)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, _, err := middleware.DecorateHandler(handler, newStack("/slow")).Handle(ctx, struct{}{})
+		if err == nil {
+			t.Fatalf("expect error, got none")
+		}
+		var cancelError *smithy.CanceledError
+		if !errors.As(err, &cancelError) {
+			t.Errorf("expect %T error, got %v", cancelError, err)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package http
+
+import "io"
+
+// WithUploadBufferSize returns a copy of the client handler that reads
+// request bodies in chunks of up to size bytes when sending them.
+//
+// By default, the HTTP client reads request bodies into a 32KB buffer. The
+// size does not change the size of the buffer the transport writes to the
+// connection with, see ClientHandlerOptions.UploadBufferSize. Larger
+// buffers reduce the number of reads of the body, and writes to the
+// connection, which may increase the throughput of streaming uploads over
+// high bandwidth, or high latency, networks, at the cost of memory per
+// request in flight. Smaller buffers reduce memory use. A zero size uses the
+// HTTP client's default.
+func (c ClientHandler) WithUploadBufferSize(size int) ClientHandler {
+	c.uploadBufferSize = size
+	return c
+}
+
+// uploadBufferBody is a request body read in chunks of the configured size
+// when sent. The HTTP client copies request bodies of unknown length with
+// io.Copy, which uses the body's WriteTo method. Bodies of known length are
+// wrapped by the HTTP client in an io.LimitReader, and read with the client's
+// buffer, so Read buffers the body in chunks of the configured size instead.
+type uploadBufferBody struct {
+	io.ReadCloser
+	size int
+
+	buf []byte
+	off int
+	err error
+}
+
+// Read reads from the buffered chunk of the body, reading the next chunk of
+// up to the buffer size from the body once the chunk is consumed. An error
+// returned with the chunk is returned once the chunk is consumed.
+func (b *uploadBufferBody) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if b.off == len(b.buf) {
+		if b.err != nil {
+			return 0, b.err
+		}
+		if cap(b.buf) == 0 {
+			b.buf = make([]byte, b.size)
+		}
+		n, err := b.ReadCloser.Read(b.buf[:cap(b.buf)])
+		b.buf, b.off, b.err = b.buf[:n], 0, err
+		if n == 0 {
+			return 0, err
+		}
+	}
+
+	n := copy(p, b.buf[b.off:])
+	b.off += n
+	return n, nil
+}
+
+// WriteTo writes the body to w, reading the body in chunks of the buffer
+// size.
+func (b *uploadBufferBody) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	if b.off < len(b.buf) {
+		n, err := w.Write(b.buf[b.off:])
+		b.off += n
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	if b.err != nil {
+		if b.err == io.EOF {
+			return written, nil
+		}
+		return written, b.err
+	}
+
+	buf := make([]byte, b.size)
+	n, err := io.CopyBuffer(w, struct{ io.Reader }{b.ReadCloser}, buf)
+	return written + n, err
+}
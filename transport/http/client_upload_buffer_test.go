@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// readSizeRecorder records the size of the largest read of the reader.
+type readSizeRecorder struct {
+	r       io.Reader
+	maxRead int
+}
+
+func (r *readSizeRecorder) Read(p []byte) (int, error) {
+	if len(p) > r.maxRead {
+		r.maxRead = len(p)
+	}
+	return r.r.Read(p)
+}
+
+func sendUpload(handler ClientHandler, url string, body io.Reader, contentLength int64) error {
+	req := NewStackRequest().(*Request)
+	req.Method = http.MethodPut
+	req.URL.Scheme = "http"
+	req.URL.Host = url
+
+	req, err := req.SetStream(body)
+	if err != nil {
+		return err
+	}
+	if contentLength != 0 {
+		req.ContentLength = contentLength
+	}
+
+	result, _, err := handler.Handle(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	resp := result.(*Response)
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func TestClientHandler_UploadBufferSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	cases := map[string]struct {
+		UploadBufferSize int
+		Expect           int
+	}{
+		"smaller than default": {
+			UploadBufferSize: 8 << 10,
+			Expect:           8 << 10,
+		},
+		"configured": {
+			UploadBufferSize: 64 << 10,
+			Expect:           64 << 10,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			handler, err := NewClientHandlerWithOptions(func(o *ClientHandlerOptions) {
+				o.UploadBufferSize = c.UploadBufferSize
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			// Unseekable bodies, so that the body is read while the request
+			// is sent. The HTTP client reads bodies of known, and unknown,
+			// length differently.
+			for _, contentLength := range []int64{-1, 1 << 20} {
+				body := &readSizeRecorder{r: bytes.NewReader(make([]byte, 1<<20))}
+				err := sendUpload(handler, server.Listener.Addr().String(),
+					struct{ io.Reader }{body}, contentLength)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+
+				if e, a := c.Expect, body.maxRead; e != a {
+					t.Errorf("expect %v byte body reads for %v length, got %v",
+						e, contentLength, a)
+				}
+			}
+		})
+	}
+}
+
+func TestNewClientHandlerWithOptions_UploadBufferSize(t *testing.T) {
+	cases := map[string]struct {
+		UploadBufferSize int
+		Transport        *http.Transport
+		Expect           int
+	}{
+		"default": {
+			Expect: http.DefaultTransport.(*http.Transport).WriteBufferSize,
+		},
+		"configured": {
+			UploadBufferSize: 64 << 10,
+			Expect:           64 << 10,
+		},
+		"custom transport": {
+			Transport: &http.Transport{WriteBufferSize: 16 << 10},
+			Expect:    16 << 10,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			handler, err := NewClientHandlerWithOptions(func(o *ClientHandlerOptions) {
+				o.Transport = c.Transport
+				o.UploadBufferSize = c.UploadBufferSize
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			transport := handler.client.(*http.Client).Transport.(*http.Transport)
+			if e, a := c.Expect, transport.WriteBufferSize; e != a {
+				t.Errorf("expect %v write buffer size, got %v", e, a)
+			}
+			if e, a := c.UploadBufferSize, handler.uploadBufferSize; e != a {
+				t.Errorf("expect %v upload buffer size, got %v", e, a)
+			}
+		})
+	}
+}
+
+// dataErrReader returns its error with the last of its data, and io.EOF for
+// reads after the error.
+type dataErrReader struct {
+	data []byte
+	err  error
+}
+
+func (r *dataErrReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	if len(r.data) == 0 {
+		return n, r.err
+	}
+	return n, nil
+}
+
+func TestUploadBufferBody_ReadError(t *testing.T) {
+	cases := map[string]struct {
+		Err       error
+		ExpectErr error
+	}{
+		"EOF with data": {
+			Err: io.EOF,
+		},
+		"error with data": {
+			Err:       fmt.Errorf("connection reset"),
+			ExpectErr: fmt.Errorf("connection reset"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			data := []byte("hello world")
+			body := &uploadBufferBody{
+				ReadCloser: ioutil.NopCloser(&dataErrReader{data: data, err: c.Err}),
+				size:       64,
+			}
+
+			// Reads smaller than the buffered chunk, so the error is
+			// returned with the chunk from the body.
+			var actual bytes.Buffer
+			_, err := io.CopyBuffer(&actual, struct{ io.Reader }{body}, make([]byte, 4))
+			if c.ExpectErr == nil {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+			} else if err == nil || err.Error() != c.ExpectErr.Error() {
+				t.Fatalf("expect %v error, got %v", c.ExpectErr, err)
+			}
+
+			if e, a := string(data), actual.String(); e != a {
+				t.Errorf("expect %q body, got %q", e, a)
+			}
+		})
+	}
+}
+
+func BenchmarkClientHandler_UploadBufferSize(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	payload := make([]byte, 8<<20)
+
+	for _, size := range []int{4 << 10, 32 << 10, 256 << 10} {
+		b.Run(fmt.Sprintf("%dKB", size>>10), func(b *testing.B) {
+			handler, err := NewClientHandlerWithOptions(func(o *ClientHandlerOptions) {
+				o.UploadBufferSize = size
+			})
+			if err != nil {
+				b.Fatalf("expect no error, got %v", err)
+			}
+
+			b.SetBytes(int64(len(payload)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				body := struct{ io.Reader }{bytes.NewReader(payload)}
+				if err := sendUpload(handler, server.Listener.Addr().String(), body, -1); err != nil {
+					b.Fatalf("expect no error, got %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type clockSkewKey struct{}
+
+// SetClockSkew returns a context with the offset between the service's clock
+// and the client's clock stored as a stack value. A positive skew indicates
+// the service's clock is ahead of the client's.
+//
+// Scoped to stack values. Use github.com/aws/smithy-go/middleware#ClearStackValues
+// to clear all stack values.
+func SetClockSkew(ctx context.Context, skew time.Duration) context.Context {
+	return middleware.WithStackValue(ctx, clockSkewKey{}, skew)
+}
+
+// GetClockSkew returns the offset between the service's clock and the
+// client's clock stored in the context, or zero if not set.
+//
+// Scoped to stack values. Use github.com/aws/smithy-go/middleware#ClearStackValues
+// to clear all stack values.
+func GetClockSkew(ctx context.Context) time.Duration {
+	v, _ := middleware.GetStackValue(ctx, clockSkewKey{}).(time.Duration)
+	return v
+}
+
+// SigningTime returns the current time corrected by the clock skew stored in
+// the context. Signers should use the signing time for the timestamps of
+// request signatures, so that signatures are not rejected by the service due
+// to the client's clock being skewed.
+func SigningTime(ctx context.Context) time.Time {
+	return timeNow().Add(GetClockSkew(ctx))
+}
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSigningTime(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	ctx := context.Background()
+	if e, a := now, SigningTime(ctx); !e.Equal(a) {
+		t.Errorf("expect %v signing time, got %v", e, a)
+	}
+
+	ctx = SetClockSkew(ctx, -5*time.Minute)
+	if e, a := -5*time.Minute, GetClockSkew(ctx); e != a {
+		t.Errorf("expect %v clock skew, got %v", e, a)
+	}
+	if e, a := now.Add(-5*time.Minute), SigningTime(ctx); !e.Equal(a) {
+		t.Errorf("expect %v signing time, got %v", e, a)
+	}
+}
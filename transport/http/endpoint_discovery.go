@@ -0,0 +1,261 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/internal/sync/singleflight"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// package variable that can be override in unit tests.
+var timeNow = time.Now
+
+// DiscoveredEndpoint is the endpoint returned by an
+// EndpointDiscoveryResolver, and how long it may be cached for.
+type DiscoveredEndpoint struct {
+	// The URL of the discovered endpoint. Only the scheme and host of the URL
+	// are applied to the request.
+	URL url.URL
+
+	// The duration the endpoint may be cached for. If zero the endpoint will
+	// not be cached.
+	TTL time.Duration
+}
+
+// EndpointDiscoveryResolver provides the interface for discovering the
+// endpoint an operation should be sent to, (e.g. calling a service's describe
+// endpoints operation).
+type EndpointDiscoveryResolver interface {
+	DiscoverEndpoint(ctx context.Context, input interface{}) (DiscoveredEndpoint, error)
+}
+
+// EndpointDiscoveryResolverFunc provides a wrapper around a function to be
+// used as an EndpointDiscoveryResolver.
+type EndpointDiscoveryResolverFunc func(context.Context, interface{}) (DiscoveredEndpoint, error)
+
+// DiscoverEndpoint invokes the wrapped function.
+func (fn EndpointDiscoveryResolverFunc) DiscoverEndpoint(ctx context.Context, input interface{}) (DiscoveredEndpoint, error) {
+	return fn(ctx, input)
+}
+
+// EndpointDiscoveryCache provides a cache of discovered endpoints by key. The
+// cache is safe to share across operations, and concurrent use. Concurrent
+// refreshes of the same key are deduplicated.
+type EndpointDiscoveryCache struct {
+	mu      sync.RWMutex
+	entries map[string]discoveredEndpointEntry
+
+	sfGroup singleflight.Group
+}
+
+type discoveredEndpointEntry struct {
+	endpoint DiscoveredEndpoint
+	expires  time.Time
+}
+
+// NewEndpointDiscoveryCache returns an initialized EndpointDiscoveryCache.
+func NewEndpointDiscoveryCache() *EndpointDiscoveryCache {
+	return &EndpointDiscoveryCache{
+		entries: map[string]discoveredEndpointEntry{},
+	}
+}
+
+// Get returns the cached endpoint for the key, and true if an unexpired
+// endpoint was found.
+func (c *EndpointDiscoveryCache) Get(key string) (DiscoveredEndpoint, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !timeNow().Before(entry.expires) {
+		return DiscoveredEndpoint{}, false
+	}
+
+	return entry.endpoint, true
+}
+
+// Set caches the endpoint for the key until the endpoint's TTL expires.
+// Endpoints without a TTL are not cached.
+func (c *EndpointDiscoveryCache) Set(key string, endpoint DiscoveredEndpoint) {
+	if endpoint.TTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = discoveredEndpointEntry{
+		endpoint: endpoint,
+		expires:  timeNow().Add(endpoint.TTL),
+	}
+}
+
+// Delete removes the cached endpoint for the key.
+func (c *EndpointDiscoveryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// EndpointDiscoveryOptions provides the configuration options for the
+// endpoint discovery middleware.
+type EndpointDiscoveryOptions struct {
+	// The resolver used to discover the endpoint when the cache does not have
+	// an unexpired endpoint for the operation's key. Required.
+	Resolver EndpointDiscoveryResolver
+
+	// Returns the cache key for the operation's input. The key should be
+	// composed of the operation name, and any identifiers the discovered
+	// endpoint is specific to. Required.
+	Key func(ctx context.Context, input interface{}) (string, error)
+
+	// The cache discovered endpoints are stored in. The cache should be shared
+	// across all operations of a client. If nil, a new cache will be created.
+	Cache *EndpointDiscoveryCache
+
+	// Sets if the operation requires endpoint discovery. If false, failing to
+	// discover an endpoint will fall back to the operation's resolved endpoint
+	// instead of failing the operation.
+	Required bool
+}
+
+// AddEndpointDiscoveryMiddleware adds the middleware to discover the
+// operation's endpoint to the stack's Initialize step, and the middleware to
+// apply the discovered endpoint to the request to the stack's Build step.
+func AddEndpointDiscoveryMiddleware(stack *middleware.Stack, options EndpointDiscoveryOptions) error {
+	if options.Resolver == nil {
+		return fmt.Errorf("endpoint discovery resolver is required")
+	}
+	if options.Key == nil {
+		return fmt.Errorf("endpoint discovery key function is required")
+	}
+	if options.Cache == nil {
+		options.Cache = NewEndpointDiscoveryCache()
+	}
+
+	if err := stack.Initialize.Add(&discoverEndpoint{options: options}, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s initialize middleware, %w",
+			(*discoverEndpoint)(nil).ID(), err)
+	}
+	if err := stack.Build.Add(&applyDiscoveredEndpoint{}, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s build middleware, %w",
+			(*applyDiscoveredEndpoint)(nil).ID(), err)
+	}
+
+	return nil
+}
+
+type endpointDiscoveryInProgressKey struct{}
+
+// isEndpointDiscoveryInProgress returns if the context is of an operation
+// invoked to discover an endpoint. Not scoped to stack values, so that the
+// value is visible to the nested discovery operation's stack.
+func isEndpointDiscoveryInProgress(ctx context.Context) bool {
+	v, _ := ctx.Value(endpointDiscoveryInProgressKey{}).(bool)
+	return v
+}
+
+type discoveredEndpointKey struct{}
+
+func getDiscoveredEndpoint(ctx context.Context) (v DiscoveredEndpoint, ok bool) {
+	v, ok = middleware.GetStackValue(ctx, discoveredEndpointKey{}).(DiscoveredEndpoint)
+	return v, ok
+}
+
+type discoverEndpoint struct {
+	options EndpointDiscoveryOptions
+}
+
+// ID returns the middleware identifier.
+func (*discoverEndpoint) ID() string { return "EndpointDiscovery" }
+
+// HandleInitialize discovers the endpoint for the operation, using the cached
+// endpoint if one is available.
+func (m *discoverEndpoint) HandleInitialize(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	// The resolver is likely to invoke an operation of the same client to
+	// discover the endpoint. Skip discovery for that operation to prevent
+	// recursively discovering endpoints.
+	if isEndpointDiscoveryInProgress(ctx) {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	endpoint, err := m.discover(ctx, in.Parameters)
+	if err != nil {
+		if m.options.Required {
+			return out, metadata, fmt.Errorf("failed to discover endpoint, %w", err)
+		}
+		return next.HandleInitialize(ctx, in)
+	}
+
+	ctx = middleware.WithStackValue(ctx, discoveredEndpointKey{}, endpoint)
+	return next.HandleInitialize(ctx, in)
+}
+
+func (m *discoverEndpoint) discover(ctx context.Context, input interface{}) (DiscoveredEndpoint, error) {
+	key, err := m.options.Key(ctx, input)
+	if err != nil {
+		return DiscoveredEndpoint{}, fmt.Errorf("failed to get endpoint discovery key, %w", err)
+	}
+
+	if endpoint, ok := m.options.Cache.Get(key); ok {
+		return endpoint, nil
+	}
+
+	resCh := m.options.Cache.sfGroup.DoChan(key, func() (interface{}, error) {
+		ctx := context.WithValue(ctx, endpointDiscoveryInProgressKey{}, true)
+		endpoint, err := m.options.Resolver.DiscoverEndpoint(ctx, input)
+		if err != nil {
+			return DiscoveredEndpoint{}, err
+		}
+
+		m.options.Cache.Set(key, endpoint)
+		return endpoint, nil
+	})
+
+	select {
+	case res := <-resCh:
+		return res.Val.(DiscoveredEndpoint), res.Err
+	case <-ctx.Done():
+		return DiscoveredEndpoint{}, fmt.Errorf("endpoint discovery canceled, %w", ctx.Err())
+	}
+}
+
+type applyDiscoveredEndpoint struct{}
+
+// ID returns the middleware identifier.
+func (*applyDiscoveredEndpoint) ID() string { return "ApplyDiscoveredEndpoint" }
+
+// HandleBuild updates the request's URL scheme and host to that of the
+// discovered endpoint, if one was discovered for the operation.
+func (m *applyDiscoveredEndpoint) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	endpoint, ok := getDiscoveredEndpoint(ctx)
+	if !ok {
+		return next.HandleBuild(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	if len(endpoint.URL.Scheme) != 0 {
+		req.URL.Scheme = endpoint.URL.Scheme
+	}
+	req.URL.Host = endpoint.URL.Host
+	req.Host = ""
+
+	return next.HandleBuild(ctx, in)
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestEndpointDiscovery(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	var stack *middleware.Stack
+	var discoveries int
+	var sentHosts []string
+
+	handler := middleware.HandlerFunc(
+		func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+			sentHosts = append(sentHosts, input.(*Request).URL.Host)
+			return &Response{}, middleware.Metadata{}, nil
+		})
+
+	resolver := EndpointDiscoveryResolverFunc(
+		func(ctx context.Context, input interface{}) (DiscoveredEndpoint, error) {
+			discoveries++
+
+			// Discovery operation is invoked with the same stack, and must
+			// not recurse into discovering an endpoint.
+			if _, _, err := stack.HandleMiddleware(ctx, "discovery", handler); err != nil {
+				return DiscoveredEndpoint{}, err
+			}
+
+			u, _ := url.Parse(fmt.Sprintf("https://discovered-%d.example.com", discoveries))
+			return DiscoveredEndpoint{URL: *u, TTL: time.Minute}, nil
+		})
+
+	stack = middleware.NewStack("stack", NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("OperationSerializer",
+		func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+			middleware.SerializeOutput, middleware.Metadata, error,
+		) {
+			in.Request.(*Request).URL.Scheme = "https"
+			in.Request.(*Request).URL.Host = "default.example.com"
+			return next.HandleSerialize(ctx, in)
+		}), middleware.After)
+
+	err := AddEndpointDiscoveryMiddleware(stack, EndpointDiscoveryOptions{
+		Resolver: resolver,
+		Key: func(ctx context.Context, input interface{}) (string, error) {
+			return "Operation:" + input.(string), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	invoke := func() {
+		t.Helper()
+		if _, _, err := stack.HandleMiddleware(context.Background(), "id", handler); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+
+	invoke()
+	invoke()
+
+	now = now.Add(2 * time.Minute)
+	invoke()
+
+	if e, a := 2, discoveries; e != a {
+		t.Errorf("expect %v discoveries, got %v", e, a)
+	}
+
+	expectHosts := []string{
+		"default.example.com",
+		"discovered-1.example.com",
+		"discovered-1.example.com",
+		"default.example.com",
+		"discovered-2.example.com",
+	}
+	if e, a := len(expectHosts), len(sentHosts); e != a {
+		t.Fatalf("expect %v requests, got %v, %v", e, a, sentHosts)
+	}
+	for i, e := range expectHosts {
+		if a := sentHosts[i]; e != a {
+			t.Errorf("expect %d request host %v, got %v", i, e, a)
+		}
+	}
+}
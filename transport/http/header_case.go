@@ -0,0 +1,44 @@
+package http
+
+import (
+	"strings"
+)
+
+// SetHeaderPreserveCase sets the request header values with the exact casing
+// of the name provided, replacing any existing values of the header
+// regardless of casing. The header name is not canonicalized, (e.g. "ETag"
+// is sent as "ETag" instead of "Etag"), for services that require the exact
+// casing of header names.
+//
+// Headers with preserved casing must be read directly from the request's
+// Header map using the exact casing, as http.Header's Get and Values methods
+// canonicalize the name.
+//
+// The casing of header names is only preserved for HTTP/1.x requests. HTTP/2
+// requires header names be sent lowercase, and the casing will be lost.
+func (r *Request) SetHeaderPreserveCase(name string, values ...string) {
+	r.removeHeaderFold(name)
+	r.Header[name] = append([]string{}, values...)
+}
+
+// AddHeaderPreserveCase appends the values to the request header with the
+// exact casing of the name provided. Any existing values of the header with
+// different casing are moved to the name provided. See SetHeaderPreserveCase
+// for the limitations of preserving header name casing.
+func (r *Request) AddHeaderPreserveCase(name string, values ...string) {
+	existing := r.removeHeaderFold(name)
+	r.Header[name] = append(existing, values...)
+}
+
+// removeHeaderFold removes all headers matching the name without regard to
+// case, returning the removed values.
+func (r *Request) removeHeaderFold(name string) []string {
+	var values []string
+	for k, vs := range r.Header {
+		if strings.EqualFold(k, name) {
+			values = append(values, vs...)
+			delete(r.Header, k)
+		}
+	}
+	return values
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRequestHeaderPreserveCase(t *testing.T) {
+	cases := map[string]struct {
+		Header    http.Header
+		Apply     func(*Request)
+		Expect    http.Header
+		ExpectRaw string
+	}{
+		"set": {
+			Header: http.Header{},
+			Apply: func(r *Request) {
+				r.SetHeaderPreserveCase("ETag", "abc")
+			},
+			Expect:    http.Header{"ETag": []string{"abc"}},
+			ExpectRaw: "\r\nETag: abc\r\n",
+		},
+		"set replaces canonical": {
+			Header: http.Header{"Etag": []string{"old"}},
+			Apply: func(r *Request) {
+				r.SetHeaderPreserveCase("ETag", "abc")
+			},
+			Expect:    http.Header{"ETag": []string{"abc"}},
+			ExpectRaw: "\r\nETag: abc\r\n",
+		},
+		"add moves canonical": {
+			Header: http.Header{"X-Amz-Meta": []string{"a"}},
+			Apply: func(r *Request) {
+				r.AddHeaderPreserveCase("x-amz-META", "b")
+			},
+			Expect:    http.Header{"x-amz-META": []string{"a", "b"}},
+			ExpectRaw: "\r\nx-amz-META: a\r\nx-amz-META: b\r\n",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewStackRequest().(*Request)
+			r.URL.Scheme = "http"
+			r.URL.Host = "example.com"
+			r.Header = c.Header
+
+			c.Apply(r)
+
+			if diff := cmp.Diff(c.Expect, r.Header); len(diff) != 0 {
+				t.Errorf("expect header to match\n%s", diff)
+			}
+
+			var buf bytes.Buffer
+			if err := r.Build(context.Background()).Write(&buf); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ExpectRaw, buf.String(); !strings.Contains(a, e) {
+				t.Errorf("expect raw request to contain %q, got %q", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// LogMode is a bitmask of the wire logging enabled for an operation
+// invocation.
+type LogMode uint64
+
+// The wire logging modes that can be combined into a LogMode.
+const (
+	// LogRequest logs the HTTP request message.
+	LogRequest LogMode = 1 << iota
+
+	// LogResponse logs the HTTP response message.
+	LogResponse
+
+	// LogBody includes the body of the logged request and response
+	// messages. Has no effect unless LogRequest or LogResponse is set.
+	LogBody
+
+	// LogRetries logs each attempt the retry middleware retries.
+	LogRetries
+)
+
+// IsRequest returns if the request should be logged.
+func (m LogMode) IsRequest() bool { return m&LogRequest != 0 }
+
+// IsResponse returns if the response should be logged.
+func (m LogMode) IsResponse() bool { return m&LogResponse != 0 }
+
+// IsBody returns if the bodies of the logged messages should be logged.
+func (m LogMode) IsBody() bool { return m&LogBody != 0 }
+
+// IsRetries returns if retried attempts should be logged.
+func (m LogMode) IsRetries() bool { return m&LogRetries != 0 }
+
+type logModeKey struct{}
+
+// SetLogMode returns a context with the wire logging modes of the operation
+// invoked with the context, overriding the logging configured for the client.
+// A zero LogMode disables the wire logging of the operation.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack values.
+func SetLogMode(ctx context.Context, mode LogMode) context.Context {
+	return middleware.WithStackValue(ctx, logModeKey{}, mode)
+}
+
+// GetLogMode returns the wire logging modes set on the context, and if the
+// modes were set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack values.
+func GetLogMode(ctx context.Context) (LogMode, bool) {
+	v, ok := middleware.GetStackValue(ctx, logModeKey{}).(LogMode)
+	return v, ok
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultEncryptionHeaderPrefix is the default prefix of the headers the
+// body encryption metadata is sent, and received in.
+const DefaultEncryptionHeaderPrefix = "X-Encryption-"
+
+// EncryptionMetadata is the metadata needed to decrypt an encrypted body,
+// (e.g. the IV, and wrapped data key). Keys are header name suffixes, and are
+// canonicalized as header names, (e.g. "Iv", "Wrapped-Key").
+type EncryptionMetadata map[string]string
+
+// Cipher provides client side encryption of request bodies, and decryption
+// of response bodies.
+type Cipher interface {
+	// Encrypt returns the ciphertext of the plaintext request body, and the
+	// metadata needed to decrypt it.
+	Encrypt(plaintext io.Reader) (ciphertext io.Reader, metadata EncryptionMetadata, err error)
+
+	// Decrypt returns the plaintext of the ciphertext response body, using
+	// the metadata received with the response.
+	Decrypt(ciphertext io.Reader, metadata EncryptionMetadata) (plaintext io.Reader, err error)
+}
+
+// BodyEncryptionOptions provides the options for the body encryption
+// middleware.
+type BodyEncryptionOptions struct {
+	// The prefix of the headers the encryption metadata is sent, and
+	// received in. Defaults to DefaultEncryptionHeaderPrefix.
+	HeaderPrefix string
+}
+
+// AddBodyEncryptionMiddleware adds the middleware encrypting the request
+// body to the end of the stack's Build step, and the middleware decrypting
+// the response body to the end of the Deserialize step, so that the response
+// body is decrypted before it is deserialized.
+//
+// The encryption metadata returned by the cipher is sent as request headers
+// with the header prefix, (e.g. X-Encryption-Iv). Response bodies are
+// decrypted if the response has any headers with the prefix.
+//
+// The request body is encrypted once, and the ciphertext is buffered in
+// memory unless the cipher returns an io.ReadSeeker, so that the body can be
+// rewound for retries, with the same metadata.
+func AddBodyEncryptionMiddleware(stack *middleware.Stack, cipher Cipher, optFns ...func(*BodyEncryptionOptions)) error {
+	options := BodyEncryptionOptions{
+		HeaderPrefix: DefaultEncryptionHeaderPrefix,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	prefix := http.CanonicalHeaderKey(options.HeaderPrefix)
+
+	encrypt := &bodyEncryption{cipher: cipher, prefix: prefix}
+	if err := stack.Build.Add(encrypt, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s build middleware, %w", encrypt.ID(), err)
+	}
+	decrypt := &bodyDecryption{cipher: cipher, prefix: prefix}
+	if err := stack.Deserialize.Add(decrypt, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s deserialize middleware, %w", decrypt.ID(), err)
+	}
+	return nil
+}
+
+type bodyEncryption struct {
+	cipher Cipher
+	prefix string
+}
+
+// ID returns the middleware identifier.
+func (*bodyEncryption) ID() string { return "BodyEncryption" }
+
+// HandleBuild replaces the request's body with its ciphertext, and sets the
+// encryption metadata headers.
+func (m *bodyEncryption) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	stream := req.GetStream()
+	if stream == nil {
+		return next.HandleBuild(ctx, in)
+	}
+
+	ciphertext, encMetadata, err := m.cipher.Encrypt(stream)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to encrypt request body, %w", err)
+	}
+
+	if _, ok := ciphertext.(io.ReadSeeker); !ok {
+		b, err := ioutil.ReadAll(ciphertext)
+		if err != nil {
+			return out, metadata, fmt.Errorf("failed to encrypt request body, %w", err)
+		}
+		ciphertext = bytes.NewReader(b)
+	}
+
+	req, err = req.SetStream(ciphertext)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to set encrypted request body, %w", err)
+	}
+
+	if n, ok, err := req.StreamLength(); err == nil && ok {
+		req.ContentLength = n
+	} else {
+		req.ContentLength = -1
+	}
+	req.Header.Del("Content-Length")
+
+	for k, v := range encMetadata {
+		req.Header.Set(m.prefix+k, v)
+	}
+
+	in.Request = req
+	return next.HandleBuild(ctx, in)
+}
+
+type bodyDecryption struct {
+	cipher Cipher
+	prefix string
+}
+
+// ID returns the middleware identifier.
+func (*bodyDecryption) ID() string { return "BodyDecryption" }
+
+// HandleDeserialize replaces the response's body with its plaintext, if the
+// response has encryption metadata headers.
+func (m *bodyDecryption) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+	if resp.Body == nil {
+		return out, metadata, err
+	}
+
+	encMetadata := EncryptionMetadata{}
+	for k, v := range resp.Header {
+		if len(v) != 0 && len(k) > len(m.prefix) && strings.HasPrefix(k, m.prefix) {
+			encMetadata[k[len(m.prefix):]] = v[0]
+		}
+	}
+	if len(encMetadata) == 0 {
+		return out, metadata, err
+	}
+
+	plaintext, err := m.cipher.Decrypt(resp.Body, encMetadata)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to decrypt response body, %w", err)
+	}
+
+	resp.Body = &decryptedBody{Reader: plaintext, closer: resp.Body}
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+
+	return out, metadata, err
+}
+
+// decryptedBody is the plaintext of an encrypted response body. Closing the
+// body closes the encrypted body.
+type decryptedBody struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (b *decryptedBody) Close() error {
+	return b.closer.Close()
+}
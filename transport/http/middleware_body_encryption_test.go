@@ -0,0 +1,192 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+// xorCipher is a stub cipher XORing the body with the key.
+type xorCipher struct {
+	key byte
+}
+
+func (c xorCipher) Encrypt(plaintext io.Reader) (io.Reader, EncryptionMetadata, error) {
+	b, err := ioutil.ReadAll(plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(c.xor(b))), EncryptionMetadata{
+		"Iv":          "000102",
+		"Wrapped-Key": "wrapped",
+	}, nil
+}
+
+func (c xorCipher) Decrypt(ciphertext io.Reader, metadata EncryptionMetadata) (io.Reader, error) {
+	if e, a := "wrapped", metadata["Wrapped-Key"]; e != a {
+		return nil, fmt.Errorf("expect %v wrapped key, got %v", e, a)
+	}
+	b, err := ioutil.ReadAll(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(c.xor(b)), nil
+}
+
+func (c xorCipher) xor(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[i] = b[i] ^ c.key
+	}
+	return out
+}
+
+func TestBodyEncryptionMiddleware(t *testing.T) {
+	const plaintext = "the quick brown fox"
+	cipher := xorCipher{key: 0x5a}
+
+	stack := middleware.NewStack("stack", NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+		func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+			middleware.SerializeOutput, middleware.Metadata, error,
+		) {
+			req, err := in.Request.(*Request).SetStream(strings.NewReader(plaintext))
+			if err != nil {
+				return middleware.SerializeOutput{}, middleware.Metadata{}, err
+			}
+			req.ContentLength = int64(len(plaintext))
+			in.Request = req
+			return next.HandleSerialize(ctx, in)
+		}), middleware.After)
+
+	var decrypted string
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			out, metadata, err = next.HandleDeserialize(ctx, in)
+			if err != nil {
+				return out, metadata, err
+			}
+			resp := out.RawResponse.(*Response)
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			decrypted = string(b)
+			return out, metadata, err
+		}), middleware.After)
+
+	if err := AddBodyEncryptionMiddleware(stack, cipher); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var sent [][]byte
+	var sentHeader http.Header
+	var sentLength int64
+	handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		req := input.(*Request)
+		sentHeader = req.Header
+		sentLength = req.ContentLength
+
+		// Read the body for two attempts, as the retry middleware would.
+		for i := 0; i < 2; i++ {
+			if err := req.RewindStream(); err != nil {
+				return nil, middleware.Metadata{}, err
+			}
+			b, err := ioutil.ReadAll(req.GetStream())
+			if err != nil {
+				return nil, middleware.Metadata{}, err
+			}
+			sent = append(sent, b)
+		}
+
+		// Echo the encrypted body, and metadata, as the stored object.
+		header := http.Header{}
+		for k, v := range req.Header {
+			if strings.HasPrefix(k, DefaultEncryptionHeaderPrefix) {
+				header[k] = v
+			}
+		}
+		return &Response{Response: &http.Response{
+			StatusCode: 200,
+			Header:     header,
+			Body:       ioutil.NopCloser(bytes.NewReader(sent[0])),
+		}}, middleware.Metadata{}, nil
+	})
+
+	_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	ciphertext := cipher.xor([]byte(plaintext))
+	for i, b := range sent {
+		if !bytes.Equal(ciphertext, b) {
+			t.Errorf("expect ciphertext sent on attempt %d, got %q", i, b)
+		}
+	}
+	if e, a := int64(len(ciphertext)), sentLength; e != a {
+		t.Errorf("expect %v content length, got %v", e, a)
+	}
+
+	expectHeader := http.Header{
+		"X-Encryption-Iv":          []string{"000102"},
+		"X-Encryption-Wrapped-Key": []string{"wrapped"},
+	}
+	if diff := cmp.Diff(expectHeader, sentHeader); len(diff) != 0 {
+		t.Errorf("expect encryption metadata headers to match\n%s", diff)
+	}
+
+	if e, a := plaintext, decrypted; e != a {
+		t.Errorf("expect %q decrypted body, got %q", e, a)
+	}
+}
+
+func TestBodyEncryptionMiddleware_UnencryptedResponse(t *testing.T) {
+	stack := middleware.NewStack("stack", NewStackRequest)
+
+	var body string
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			out, metadata, err = next.HandleDeserialize(ctx, in)
+			if err != nil {
+				return out, metadata, err
+			}
+			b, err := ioutil.ReadAll(out.RawResponse.(*Response).Body)
+			body = string(b)
+			return out, metadata, err
+		}), middleware.After)
+
+	if err := AddBodyEncryptionMiddleware(stack, xorCipher{key: 0x5a}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		return &Response{Response: &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader("plain")),
+		}}, middleware.Metadata{}, nil
+	})
+
+	_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "plain", body; e != a {
+		t.Errorf("expect %q body, got %q", e, a)
+	}
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AddressingStyle is how the bucket of a request is addressed.
+type AddressingStyle int
+
+// Enumeration of the bucket addressing styles.
+const (
+	// PathStyleAddressing addresses the bucket as the first segment of the
+	// URL path, (e.g. https://host/bucket/key).
+	PathStyleAddressing AddressingStyle = iota
+
+	// VirtualHostedStyleAddressing addresses the bucket as a subdomain of
+	// the URL host, (e.g. https://bucket.host/key). Buckets whose names are
+	// not compatible with virtual hosted addressing fall back to path style.
+	VirtualHostedStyleAddressing
+)
+
+func (s AddressingStyle) String() string {
+	switch s {
+	case PathStyleAddressing:
+		return "path"
+	case VirtualHostedStyleAddressing:
+		return "virtual-hosted"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+type bucketKey struct{}
+
+// SetBucket returns a context with the bucket the request is addressed to.
+// Set by the operation from the bucket member of its input.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetBucket(ctx context.Context, bucket string) context.Context {
+	return middleware.WithStackValue(ctx, bucketKey{}, bucket)
+}
+
+// GetBucket returns the bucket the request is addressed to, and if the
+// bucket was set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetBucket(ctx context.Context) (string, bool) {
+	v, ok := middleware.GetStackValue(ctx, bucketKey{}).(string)
+	return v, ok && len(v) != 0
+}
+
+// IsVirtualHostableBucket returns if the bucket name can be addressed as a
+// subdomain of a host. The name must be a DNS compatible label sequence of 3
+// to 63 lowercase letters, digits, hyphens, and dots, that starts and ends
+// with a letter or digit, and is not formatted as an IP address. If the
+// request is sent over HTTPS, the name must not contain dots, since the
+// subdomains would not match the host's wildcard certificate.
+func IsVirtualHostableBucket(bucket string, https bool) bool {
+	if len(bucket) < 3 || len(bucket) > 63 {
+		return false
+	}
+	if net.ParseIP(bucket) != nil {
+		return false
+	}
+
+	for _, label := range strings.Split(bucket, ".") {
+		if len(label) == 0 || (https && label != bucket) {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// BucketAddressingOptions provides the options for the bucket addressing
+// middleware.
+type BucketAddressingOptions struct {
+	// The addressing style of the request's bucket. Defaults to
+	// PathStyleAddressing.
+	AddressingStyle AddressingStyle
+}
+
+// AddBucketAddressingMiddleware adds the middleware addressing the request to
+// its bucket, see SetBucket, to the end of the stack's Build step.
+//
+// The request's serialized URL path is expected to be relative to the
+// bucket, (e.g. /key). The path is prefixed with the bucket for path style
+// addressing, otherwise the host is prefixed with the bucket. Requests
+// without a bucket are not modified.
+func AddBucketAddressingMiddleware(stack *middleware.Stack, optFns ...func(*BucketAddressingOptions)) error {
+	var options BucketAddressingOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Build.Add(&bucketAddressing{options: options}, middleware.After)
+}
+
+type bucketAddressing struct {
+	options BucketAddressingOptions
+}
+
+// ID returns the middleware identifier.
+func (*bucketAddressing) ID() string { return "BucketAddressing" }
+
+// HandleBuild rewrites the request's URL to address the request's bucket.
+func (m *bucketAddressing) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	bucket, ok := GetBucket(ctx)
+	if !ok {
+		return next.HandleBuild(ctx, in)
+	}
+
+	switch m.options.AddressingStyle {
+	case PathStyleAddressing:
+		setPathStyleBucket(req.URL, bucket)
+	case VirtualHostedStyleAddressing:
+		if IsVirtualHostableBucket(bucket, req.IsHTTPS()) && isVirtualHostableHost(req.URL.Hostname()) {
+			req.URL.Host = bucket + "." + req.URL.Host
+			if len(req.Host) != 0 {
+				req.Host = bucket + "." + req.Host
+			}
+		} else {
+			setPathStyleBucket(req.URL, bucket)
+		}
+	default:
+		return out, metadata, fmt.Errorf("unknown addressing style, %v", m.options.AddressingStyle)
+	}
+
+	return next.HandleBuild(ctx, in)
+}
+
+// isVirtualHostableHost returns if buckets can be addressed as a subdomain of
+// the host, which is not possible for IP addresses.
+func isVirtualHostableHost(host string) bool {
+	return len(host) != 0 && net.ParseIP(host) == nil
+}
+
+func setPathStyleBucket(u *url.URL, bucket string) {
+	u.Path = "/" + bucket + ensureLeadingSlash(u.Path)
+	if len(u.RawPath) != 0 {
+		u.RawPath = "/" + url.PathEscape(bucket) + ensureLeadingSlash(u.RawPath)
+	}
+}
+
+func ensureLeadingSlash(p string) string {
+	if len(p) == 0 || p[0] == '/' {
+		return p
+	}
+	return "/" + p
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestBucketAddressingMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Style      AddressingStyle
+		Endpoint   string
+		Path       string
+		Bucket     string
+		ExpectURL  string
+		ExpectHost string
+	}{
+		"path style": {
+			Style:     PathStyleAddressing,
+			Endpoint:  "https://s3.example.com",
+			Path:      "/photos/cat.jpg",
+			Bucket:    "my-bucket",
+			ExpectURL: "https://s3.example.com/my-bucket/photos/cat.jpg",
+		},
+		"path style empty path": {
+			Style:     PathStyleAddressing,
+			Endpoint:  "https://s3.example.com",
+			Bucket:    "my-bucket",
+			ExpectURL: "https://s3.example.com/my-bucket",
+		},
+		"virtual hosted style": {
+			Style:     VirtualHostedStyleAddressing,
+			Endpoint:  "https://s3.example.com",
+			Path:      "/photos/cat.jpg",
+			Bucket:    "my-bucket",
+			ExpectURL: "https://my-bucket.s3.example.com/photos/cat.jpg",
+		},
+		"virtual hosted style with port": {
+			Style:     VirtualHostedStyleAddressing,
+			Endpoint:  "http://localhost:9000",
+			Path:      "/cat.jpg",
+			Bucket:    "my.bucket",
+			ExpectURL: "http://my.bucket.localhost:9000/cat.jpg",
+		},
+		"virtual hosted style host override": {
+			Style:      VirtualHostedStyleAddressing,
+			Endpoint:   "https://s3.example.com",
+			Path:       "/cat.jpg",
+			Bucket:     "my-bucket",
+			ExpectURL:  "https://my-bucket.s3.example.com/cat.jpg",
+			ExpectHost: "my-bucket.s3.example.com",
+		},
+		"fallback uppercase": {
+			Style:     VirtualHostedStyleAddressing,
+			Endpoint:  "https://s3.example.com",
+			Path:      "/cat.jpg",
+			Bucket:    "My_Bucket",
+			ExpectURL: "https://s3.example.com/My_Bucket/cat.jpg",
+		},
+		"fallback dots over https": {
+			Style:     VirtualHostedStyleAddressing,
+			Endpoint:  "https://s3.example.com",
+			Path:      "/cat.jpg",
+			Bucket:    "my.bucket",
+			ExpectURL: "https://s3.example.com/my.bucket/cat.jpg",
+		},
+		"fallback ip endpoint": {
+			Style:     VirtualHostedStyleAddressing,
+			Endpoint:  "http://127.0.0.1:9000",
+			Path:      "/cat.jpg",
+			Bucket:    "my-bucket",
+			ExpectURL: "http://127.0.0.1:9000/my-bucket/cat.jpg",
+		},
+		"no bucket": {
+			Style:     VirtualHostedStyleAddressing,
+			Endpoint:  "https://s3.example.com",
+			Path:      "/",
+			ExpectURL: "https://s3.example.com/",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					middleware.SerializeOutput, middleware.Metadata, error,
+				) {
+					req := in.Request.(*Request)
+					req.URL, _ = req.URL.Parse(c.Endpoint + c.Path)
+					if len(c.ExpectHost) != 0 {
+						req.Host = req.URL.Host
+					}
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+			if err := AddBucketAddressingMiddleware(stack, func(o *BucketAddressingOptions) {
+				o.AddressingStyle = c.Style
+			}); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var req *Request
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				req = input.(*Request)
+				return nil, middleware.Metadata{}, nil
+			})
+
+			ctx := context.Background()
+			if len(c.Bucket) != 0 {
+				ctx = SetBucket(ctx, c.Bucket)
+			}
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectURL, req.URL.String(); e != a {
+				t.Errorf("expect %v URL, got %v", e, a)
+			}
+			if e, a := c.ExpectHost, req.Host; e != a {
+				t.Errorf("expect %v host, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestIsVirtualHostableBucket(t *testing.T) {
+	cases := []struct {
+		Bucket string
+		HTTPS  bool
+		Expect bool
+	}{
+		{Bucket: "my-bucket", HTTPS: true, Expect: true},
+		{Bucket: "abc", HTTPS: true, Expect: true},
+		{Bucket: "ab", HTTPS: true, Expect: false},
+		{Bucket: strings.Repeat("a", 63), HTTPS: true, Expect: true},
+		{Bucket: strings.Repeat("a", 64), HTTPS: true, Expect: false},
+		{Bucket: "my.bucket", HTTPS: false, Expect: true},
+		{Bucket: "my.bucket", HTTPS: true, Expect: false},
+		{Bucket: "my..bucket", HTTPS: false, Expect: false},
+		{Bucket: "-bucket", HTTPS: true, Expect: false},
+		{Bucket: "bucket-", HTTPS: true, Expect: false},
+		{Bucket: "MyBucket", HTTPS: true, Expect: false},
+		{Bucket: "my_bucket", HTTPS: true, Expect: false},
+		{Bucket: "192.168.5.4", HTTPS: false, Expect: false},
+	}
+
+	for i, c := range cases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if e, a := c.Expect, IsVirtualHostableBucket(c.Bucket, c.HTTPS); e != a {
+				t.Errorf("expect %v for %q, got %v", e, c.Bucket, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ChecksumTrailerOptions provides the options for the ChecksumTrailer
+// middleware.
+type ChecksumTrailerOptions struct {
+	// The name of the trailer the base64 encoded checksum of the request
+	// body is sent in. Required.
+	Trailer string
+
+	// Returns the hash used to compute the checksum of the request body.
+	// Defaults to SHA-256.
+	NewHash func() hash.Hash
+}
+
+// ChecksumTrailer is a Build step middleware that sends the request body with
+// chunked transfer-encoding, computing the checksum of the body as it is sent,
+// and sending the checksum in a trailer after the final chunk. This allows a
+// request body of unknown length to be sent with integrity protection, without
+// reading the body twice.
+//
+// The middleware must be added after ComputeContentLength, as it will
+// override the content length of the request to be unknown. The
+// ValidateContentLength middleware is incompatible with this middleware.
+//
+// Trailers are only sent when the request is sent over HTTP/1.1 or HTTP/2.
+type ChecksumTrailer struct {
+	options ChecksumTrailerOptions
+}
+
+// AddChecksumTrailerMiddleware adds the ChecksumTrailer middleware to the end
+// of the stack's Build step.
+func AddChecksumTrailerMiddleware(stack *middleware.Stack, options ChecksumTrailerOptions) error {
+	if len(options.Trailer) == 0 {
+		return fmt.Errorf("checksum trailer name is required")
+	}
+	if options.NewHash == nil {
+		options.NewHash = sha256.New
+	}
+
+	return stack.Build.Add(&ChecksumTrailer{options: options}, middleware.After)
+}
+
+// ID returns the middleware identifier.
+func (m *ChecksumTrailer) ID() string { return "ChecksumTrailer" }
+
+// HandleBuild wraps the request stream to compute its checksum, and updates
+// the request to be sent with chunked transfer-encoding and the checksum
+// trailer. Does nothing if the request has no stream.
+func (m *ChecksumTrailer) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	stream := req.GetStream()
+	if stream == nil {
+		return next.HandleBuild(ctx, in)
+	}
+
+	trailer := http.CanonicalHeaderKey(m.options.Trailer)
+	checksumStream := newChecksumTrailerReader(stream, m.options.NewHash(), trailer)
+
+	// The stream's start position is preserved by the checksum reader
+	// delegating seeks to the underlying stream.
+	req, err = req.SetStream(checksumStream)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to set checksum trailer stream, %w", err)
+	}
+
+	req.ContentLength = -1
+	req.Header.Del("Content-Length")
+	req.TransferEncoding = []string{"chunked"}
+	if req.Trailer == nil {
+		req.Trailer = http.Header{}
+	}
+	req.Trailer[trailer] = nil
+
+	in.Request = req
+	return next.HandleBuild(ctx, in)
+}
+
+// trailerSetter is implemented by request streams that set trailer values on
+// the built request as the stream is read.
+type trailerSetter interface {
+	setTrailer(http.Header)
+}
+
+type checksumTrailerReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	key    string
+
+	mu      sync.Mutex
+	trailer http.Header
+}
+
+func newChecksumTrailerReader(r io.Reader, h hash.Hash, key string) io.Reader {
+	cr := &checksumTrailerReader{
+		reader: r,
+		hash:   h,
+		key:    key,
+	}
+	if _, ok := r.(io.Seeker); ok {
+		return &seekableChecksumTrailerReader{checksumTrailerReader: cr}
+	}
+	return cr
+}
+
+func (r *checksumTrailerReader) setTrailer(trailer http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trailer = trailer
+}
+
+func (r *checksumTrailerReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.hash.Write(p[:n])
+	}
+
+	if err == io.EOF {
+		r.mu.Lock()
+		if r.trailer != nil {
+			r.trailer.Set(r.key, base64.StdEncoding.EncodeToString(r.hash.Sum(nil)))
+		}
+		r.mu.Unlock()
+	}
+
+	return n, err
+}
+
+type seekableChecksumTrailerReader struct {
+	*checksumTrailerReader
+}
+
+// Seek delegates to the underlying stream. The checksum is reset, since it
+// is only valid if the stream is read in full from its start position.
+func (r *seekableChecksumTrailerReader) Seek(offset int64, whence int) (int64, error) {
+	n, err := r.reader.(io.Seeker).Seek(offset, whence)
+	if err != nil {
+		return n, err
+	}
+
+	// Seeking to the current position is used to determine the stream's
+	// position, and does not invalidate the checksum.
+	if !(offset == 0 && whence == io.SeekCurrent) {
+		r.hash.Reset()
+	}
+	return n, nil
+}
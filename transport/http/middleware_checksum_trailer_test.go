@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestChecksumTrailer(t *testing.T) {
+	payload := strings.Repeat("abc123", 1024)
+	sum := sha256.Sum256([]byte(payload))
+	expectChecksum := base64.StdEncoding.EncodeToString(sum[:])
+
+	cases := map[string]struct {
+		Stream func() io.Reader
+	}{
+		"unseekable stream": {
+			Stream: func() io.Reader {
+				return ioutil.NopCloser(strings.NewReader(payload))
+			},
+		},
+		"seekable stream": {
+			Stream: func() io.Reader {
+				return bytes.NewReader([]byte(payload))
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var actualBody, actualChecksum string
+			var actualTransferEncoding []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actualTransferEncoding = r.TransferEncoding
+				b, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("expect no error reading body, got %v", err)
+				}
+				actualBody = string(b)
+				// Trailers are only available after the body is read.
+				actualChecksum = r.Trailer.Get("X-Checksum-Sha256")
+			}))
+			defer server.Close()
+
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("OperationSerializer",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					middleware.SerializeOutput, middleware.Metadata, error,
+				) {
+					req := in.Request.(*Request)
+					req.Method = http.MethodPut
+					req.URL, _ = url.Parse(server.URL)
+
+					var err error
+					in.Request, err = req.SetStream(c.Stream())
+					if err != nil {
+						return middleware.SerializeOutput{}, middleware.Metadata{}, err
+					}
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+			if err := AddComputeContentLengthMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			err := AddChecksumTrailerMiddleware(stack, ChecksumTrailerOptions{
+				Trailer: "X-Checksum-Sha256",
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := middleware.DecorateHandler(NewClientHandler(server.Client()), stack)
+			_, _, err = handler.Handle(context.Background(), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := []string{"chunked"}, actualTransferEncoding; len(a) != 1 || e[0] != a[0] {
+				t.Errorf("expect %v transfer encoding, got %v", e, a)
+			}
+			if e, a := payload, actualBody; e != a {
+				t.Errorf("expect body to match, got %d bytes", len(a))
+			}
+			if e, a := expectChecksum, actualChecksum; e != a {
+				t.Errorf("expect %v checksum trailer, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AddConnectionTraceMiddleware adds the middleware to record whether the
+// operation's request was sent over a reused connection, and the protocol
+// negotiated via ALPN, into the operation's metadata. The middleware is added
+// to the end of the Finalize step, so that the values of the last attempt are
+// recorded.
+//
+// The values are available from the metadata with GetConnReused and
+// GetALPNProtocol.
+func AddConnectionTraceMiddleware(stack *middleware.Stack) error {
+	return stack.Finalize.Add(&connectionTrace{}, middleware.After)
+}
+
+type (
+	connReusedKey   struct{}
+	alpnProtocolKey struct{}
+)
+
+// GetConnReused returns if the operation's request was sent over a
+// connection reused from the client's connection pool.
+func GetConnReused(metadata middleware.MetadataReader) bool {
+	v, _ := metadata.Get(connReusedKey{}).(bool)
+	return v
+}
+
+// GetALPNProtocol returns the application protocol negotiated via ALPN for
+// the connection the operation's request was sent over, (e.g. "h2"), and if
+// the value was set. The value is not set for connections without TLS.
+func GetALPNProtocol(metadata middleware.MetadataReader) (string, bool) {
+	v, ok := metadata.Get(alpnProtocolKey{}).(string)
+	return v, ok
+}
+
+type connectionTrace struct{}
+
+// ID returns the middleware identifier.
+func (*connectionTrace) ID() string { return "ConnectionTrace" }
+
+// HandleFinalize invokes the next handler with a client trace capturing the
+// connection the request was sent over.
+func (*connectionTrace) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	var (
+		mu       sync.Mutex
+		gotConn  bool
+		reused   bool
+		protocol string
+		hasTLS   bool
+	)
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			gotConn = true
+			reused = info.Reused
+			if c, ok := info.Conn.(interface{ ConnectionState() tls.ConnectionState }); ok {
+				hasTLS = true
+				protocol = c.ConnectionState().NegotiatedProtocol
+			}
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err == nil {
+				hasTLS = true
+				protocol = state.NegotiatedProtocol
+			}
+		},
+	}
+
+	out, metadata, err = next.HandleFinalize(httptrace.WithClientTrace(ctx, trace), in)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if gotConn {
+		metadata.Set(connReusedKey{}, reused)
+	}
+	if hasTLS {
+		metadata.Set(alpnProtocolKey{}, protocol)
+	}
+
+	return out, metadata, err
+}
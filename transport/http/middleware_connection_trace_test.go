@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestConnectionTraceMiddleware(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	stack := middleware.NewStack("stack", NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+		func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+			middleware.SerializeOutput, middleware.Metadata, error,
+		) {
+			req := in.Request.(*Request)
+			req.Method = http.MethodGet
+			req.URL.Scheme = endpoint.Scheme
+			req.URL.Host = endpoint.Host
+			return next.HandleSerialize(ctx, in)
+		}), middleware.After)
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("deserialize",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			out, metadata, err = next.HandleDeserialize(ctx, in)
+			out.Result = out.RawResponse
+			return out, metadata, err
+		}), middleware.After)
+	if err := AddConnectionTraceMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := middleware.DecorateHandler(NewClientHandler(server.Client()), stack)
+
+	for i, expectReused := range []bool{false, true} {
+		out, metadata, err := handler.Handle(context.Background(), struct{}{})
+		if err != nil {
+			t.Fatalf("%d, expect no error, got %v", i, err)
+		}
+		resp := out.(*Response)
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		if e, a := expectReused, GetConnReused(metadata); e != a {
+			t.Errorf("%d, expect %v connection reused, got %v", i, e, a)
+		}
+
+		protocol, ok := GetALPNProtocol(metadata)
+		if !ok {
+			t.Fatalf("%d, expect ALPN protocol in metadata", i)
+		}
+		if e, a := "h2", protocol; e != a {
+			t.Errorf("%d, expect %v ALPN protocol, got %v", i, e, a)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AddCookieJarMiddleware adds the middleware sending the cookies stored in
+// the jar for the request's URL with each request, and storing the cookies
+// set by each response in the jar. The middleware is added to the end of the
+// stack's Deserialize step, so that each attempt of the operation is sent
+// with the jar's current cookies.
+//
+// Use the middleware when the stack's client does not manage cookies itself.
+// Clients built with a ClientHandlerOptions Jar should not also use the
+// middleware, otherwise cookies would be sent twice.
+func AddCookieJarMiddleware(stack *middleware.Stack, jar http.CookieJar) error {
+	return stack.Deserialize.Add(&cookieJar{jar: jar}, middleware.After)
+}
+
+type cookieJar struct {
+	jar http.CookieJar
+}
+
+// ID returns the middleware identifier.
+func (*cookieJar) ID() string { return "CookieJar" }
+
+// HandleDeserialize adds the jar's cookies to the request, and stores the
+// response's cookies in the jar.
+func (m *cookieJar) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	// Cookies are keyed by the URL the request is sent to, including any
+	// host prefix or endpoint override already applied to the request.
+	u := *req.URL
+	if len(req.Host) != 0 {
+		u.Host = req.Host
+	}
+
+	for _, cookie := range m.jar.Cookies(&u) {
+		req.AddCookie(cookie)
+	}
+
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	if resp, ok := out.RawResponse.(*Response); ok && resp != nil && resp.Response != nil {
+		if cookies := resp.Cookies(); len(cookies) != 0 {
+			m.jar.SetCookies(&u, cookies)
+		}
+	}
+
+	return out, metadata, err
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCookieJarMiddleware(t *testing.T) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var sentCookies [][]string
+	handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		req := input.(*Request)
+		var names []string
+		for _, c := range req.Cookies() {
+			names = append(names, c.Name+"="+c.Value)
+		}
+		sentCookies = append(sentCookies, names)
+
+		header := http.Header{}
+		if req.URL.Path == "/login" {
+			header.Add("Set-Cookie", "session=abc123; Path=/")
+		}
+		return &Response{
+			Response: &http.Response{
+				StatusCode: 200,
+				Header:     header,
+			},
+		}, middleware.Metadata{}, nil
+	})
+
+	invoke := func(host, path string) {
+		t.Helper()
+		stack := middleware.NewStack("stack", NewStackRequest)
+		stack.Serialize.Add(middleware.SerializeMiddlewareFunc("OperationSerializer", func(
+			ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+		) (
+			middleware.SerializeOutput, middleware.Metadata, error,
+		) {
+			req := in.Request.(*Request)
+			req.URL.Scheme = "https"
+			req.URL.Host = host
+			req.URL.Path = path
+			return next.HandleSerialize(ctx, in)
+		}), middleware.After)
+		if err := AddCookieJarMiddleware(stack, jar); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+
+	invoke("example.com", "/login")
+	invoke("example.com", "/items")
+	invoke("other.example.org", "/items")
+
+	expect := [][]string{
+		nil,
+		{"session=abc123"},
+		nil,
+	}
+	if diff := cmp.Diff(expect, sentCookies); len(diff) != 0 {
+		t.Errorf("expect sent cookies to match\n%s", diff)
+	}
+}
+
+func TestNewClientHandlerWithOptions_Jar(t *testing.T) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler, err := NewClientHandlerWithOptions(func(o *ClientHandlerOptions) {
+		o.Jar = jar
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := http.CookieJar(jar), handler.client.(*http.Client).Jar; e != a {
+		t.Errorf("expect client jar to be set")
+	}
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/aws/smithy-go/rand"
+)
+
+// DefaultCorrelationIDHeader is the default header correlation IDs are sent
+// in.
+const DefaultCorrelationIDHeader = "X-Correlation-Id"
+
+type correlationIDKey struct{}
+
+// SetCorrelationID returns a context with the correlation ID of the incoming
+// request being served, (e.g. set by the server framework), to propagate to
+// the requests the context is used with.
+//
+// Unlike stack values, the correlation ID is not scoped to a single operation
+// invocation, so that it is propagated to every request made while serving
+// the incoming request.
+func SetCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// GetCorrelationID returns the correlation ID set on the context, and if it
+// was set.
+func GetCorrelationID(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(correlationIDKey{}).(string)
+	return v, ok && len(v) != 0
+}
+
+type correlationIDMetadataKey struct{}
+
+// GetCorrelationIDMetadata returns the correlation ID sent with the request,
+// either propagated from the context, or generated, and if it was sent.
+func GetCorrelationIDMetadata(metadata middleware.MetadataReader) (string, bool) {
+	v, ok := metadata.Get(correlationIDMetadataKey{}).(string)
+	return v, ok
+}
+
+// CorrelationIDOptions provides the options for the correlation ID
+// middleware.
+type CorrelationIDOptions struct {
+	// The header the correlation ID is sent in. Defaults to
+	// DefaultCorrelationIDHeader.
+	Header string
+
+	// Returns a new correlation ID for requests made without a correlation
+	// ID on the context. Defaults to a random UUID.
+	NewID func() (string, error)
+}
+
+// AddCorrelationIDMiddleware adds the middleware propagating the correlation
+// ID set on the context, see SetCorrelationID, as a request header to the end
+// of the stack's Build step. If the context does not have a correlation ID, a
+// new ID is generated. Requests that already have the header are not
+// modified.
+//
+// The header is set once per operation, so that retry attempts are sent with
+// the same correlation ID.
+func AddCorrelationIDMiddleware(stack *middleware.Stack, optFns ...func(*CorrelationIDOptions)) error {
+	options := CorrelationIDOptions{
+		Header: DefaultCorrelationIDHeader,
+		NewID: func() (string, error) {
+			return rand.NewUUID(rand.Reader).GetUUID()
+		},
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Build.Add(&correlationID{options: options}, middleware.After)
+}
+
+type correlationID struct {
+	options CorrelationIDOptions
+}
+
+// ID returns the middleware identifier.
+func (*correlationID) ID() string { return "CorrelationID" }
+
+// HandleBuild sets the correlation ID header on the request.
+func (m *correlationID) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	id := req.Header.Get(m.options.Header)
+	if len(id) == 0 {
+		var ok bool
+		if id, ok = GetCorrelationID(ctx); !ok {
+			if id, err = m.options.NewID(); err != nil {
+				return out, metadata, fmt.Errorf("failed to generate correlation ID, %w", err)
+			}
+		}
+		req.Header.Set(m.options.Header, id)
+	}
+
+	out, metadata, err = next.HandleBuild(ctx, in)
+	metadata.Set(correlationIDMetadataKey{}, id)
+	return out, metadata, err
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestCorrelationIDMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		CorrelationID string
+		RequestHeader string
+		Options       func(*CorrelationIDOptions)
+		Header        string
+		Expect        string
+		ExpectPattern *regexp.Regexp
+		ExpectErr     string
+	}{
+		"propagated": {
+			CorrelationID: "incoming-1234",
+			Header:        DefaultCorrelationIDHeader,
+			Expect:        "incoming-1234",
+		},
+		"generated": {
+			Header:        DefaultCorrelationIDHeader,
+			ExpectPattern: uuidPattern,
+		},
+		"custom header and generator": {
+			Options: func(o *CorrelationIDOptions) {
+				o.Header = "X-Request-Trace"
+				o.NewID = func() (string, error) { return "generated-id", nil }
+			},
+			Header: "X-Request-Trace",
+			Expect: "generated-id",
+		},
+		"header already set": {
+			CorrelationID: "incoming-1234",
+			RequestHeader: "explicit-id",
+			Header:        DefaultCorrelationIDHeader,
+			Expect:        "explicit-id",
+		},
+		"generator error": {
+			Options: func(o *CorrelationIDOptions) {
+				o.NewID = func() (string, error) { return "", fmt.Errorf("no entropy") }
+			},
+			ExpectErr: "failed to generate correlation ID",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+
+			var optFns []func(*CorrelationIDOptions)
+			if c.Options != nil {
+				optFns = append(optFns, c.Options)
+			}
+			if err := AddCorrelationIDMiddleware(stack, optFns...); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if len(c.RequestHeader) != 0 {
+				stack.Build.Add(middleware.BuildMiddlewareFunc("setHeader",
+					func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (
+						middleware.BuildOutput, middleware.Metadata, error,
+					) {
+						in.Request.(*Request).Header.Set(DefaultCorrelationIDHeader, c.RequestHeader)
+						return next.HandleBuild(ctx, in)
+					}), middleware.Before)
+			}
+
+			var actual string
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				actual = input.(*Request).Header.Get(c.Header)
+				return nil, middleware.Metadata{}, nil
+			})
+
+			ctx := context.Background()
+			if len(c.CorrelationID) != 0 {
+				ctx = SetCorrelationID(ctx, c.CorrelationID)
+			}
+
+			_, metadata, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if c.ExpectPattern != nil {
+				if !c.ExpectPattern.MatchString(actual) {
+					t.Errorf("expect generated ID to match %v, got %q", c.ExpectPattern, actual)
+				}
+			} else if e, a := c.Expect, actual; e != a {
+				t.Errorf("expect %q correlation ID, got %q", e, a)
+			}
+
+			if v, ok := GetCorrelationIDMetadata(metadata); !ok || v != actual {
+				t.Errorf("expect %q correlation ID metadata, got %q, %v", actual, v, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultCostTagHeaderPrefix is the default prefix of the headers cost
+// allocation tags are sent in.
+const DefaultCostTagHeaderPrefix = "X-Cost-Tag-"
+
+const (
+	maxCostTagKeyLen   = 128
+	maxCostTagValueLen = 256
+)
+
+type costTagsKey struct{}
+
+// SetCostTags returns a context with the cost allocation tags to send with
+// the request, (e.g. tenant, and cost center). The tags are copied, and
+// replace any tags previously set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetCostTags(ctx context.Context, tags map[string]string) context.Context {
+	v := make(map[string]string, len(tags))
+	for key, value := range tags {
+		v[key] = value
+	}
+	return middleware.WithStackValue(ctx, costTagsKey{}, v)
+}
+
+// GetCostTags returns the cost allocation tags set on the context, or nil if
+// none were set. The returned map must not be modified.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetCostTags(ctx context.Context) map[string]string {
+	v, _ := middleware.GetStackValue(ctx, costTagsKey{}).(map[string]string)
+	return v
+}
+
+// InvalidCostTagError is the error returned when a cost allocation tag's key
+// or value contains characters not allowed, or is too long.
+type InvalidCostTagError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidCostTagError) Error() string {
+	return fmt.Sprintf("invalid cost tag %q, %s", e.Key, e.Reason)
+}
+
+// CostTagsOptions provides the options for the cost tags middleware.
+type CostTagsOptions struct {
+	// The prefix of the header each tag is sent in, followed by the tag's
+	// key. Defaults to DefaultCostTagHeaderPrefix.
+	HeaderPrefix string
+}
+
+// AddCostTagsMiddleware adds the middleware sending the cost allocation tags
+// set on the context, see SetCostTags, as request headers to the end of the
+// stack's Build step.
+//
+// Tag keys may only contain letters, digits, and hyphens, and be at most 128
+// characters. Tag values may only contain letters, digits, spaces, and the
+// characters _.:/=+-@, and be at most 256 characters. The request fails with
+// an InvalidCostTagError if a tag is not valid.
+func AddCostTagsMiddleware(stack *middleware.Stack, optFns ...func(*CostTagsOptions)) error {
+	options := CostTagsOptions{
+		HeaderPrefix: DefaultCostTagHeaderPrefix,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Build.Add(&costTags{options: options}, middleware.After)
+}
+
+type costTags struct {
+	options CostTagsOptions
+}
+
+// ID returns the middleware identifier.
+func (*costTags) ID() string { return "CostTags" }
+
+// HandleBuild sets a header on the request for each cost allocation tag.
+func (m *costTags) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	tags := GetCostTags(ctx)
+	if len(tags) == 0 {
+		return next.HandleBuild(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	for key, value := range tags {
+		if err := validateCostTag(key, value); err != nil {
+			return out, metadata, err
+		}
+		req.Header.Set(m.options.HeaderPrefix+key, value)
+	}
+
+	return next.HandleBuild(ctx, in)
+}
+
+func validateCostTag(key, value string) error {
+	if len(key) == 0 {
+		return &InvalidCostTagError{Key: key, Reason: "key must not be empty"}
+	}
+	if len(key) > maxCostTagKeyLen {
+		return &InvalidCostTagError{Key: key,
+			Reason: fmt.Sprintf("key must be at most %d characters", maxCostTagKeyLen)}
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; !isCostTagAlphaNum(c) && c != '-' {
+			return &InvalidCostTagError{Key: key,
+				Reason: fmt.Sprintf("key contains invalid character %q", c)}
+		}
+	}
+
+	if len(value) > maxCostTagValueLen {
+		return &InvalidCostTagError{Key: key,
+			Reason: fmt.Sprintf("value must be at most %d characters", maxCostTagValueLen)}
+	}
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case isCostTagAlphaNum(c):
+		case c == ' ', c == '_', c == '.', c == ':', c == '/', c == '=',
+			c == '+', c == '-', c == '@':
+		default:
+			return &InvalidCostTagError{Key: key,
+				Reason: fmt.Sprintf("value contains invalid character %q", c)}
+		}
+	}
+
+	return nil
+}
+
+func isCostTagAlphaNum(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
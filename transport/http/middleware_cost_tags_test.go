@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCostTagsMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Tags         map[string]string
+		Options      func(*CostTagsOptions)
+		ExpectHeader http.Header
+		ExpectErr    string
+	}{
+		"no tags": {
+			ExpectHeader: http.Header{},
+		},
+		"default prefix": {
+			Tags: map[string]string{
+				"tenant":      "tenant-1234",
+				"cost-center": "eng/platform:42",
+			},
+			ExpectHeader: http.Header{
+				"X-Cost-Tag-Tenant":      []string{"tenant-1234"},
+				"X-Cost-Tag-Cost-Center": []string{"eng/platform:42"},
+			},
+		},
+		"custom prefix": {
+			Tags: map[string]string{
+				"tenant": "Acme Corp",
+			},
+			Options: func(o *CostTagsOptions) {
+				o.HeaderPrefix = "X-Billing-"
+			},
+			ExpectHeader: http.Header{
+				"X-Billing-Tenant": []string{"Acme Corp"},
+			},
+		},
+		"empty value": {
+			Tags: map[string]string{
+				"tenant": "",
+			},
+			ExpectHeader: http.Header{
+				"X-Cost-Tag-Tenant": []string{""},
+			},
+		},
+		"invalid key": {
+			Tags: map[string]string{
+				"tenant id": "abc",
+			},
+			ExpectErr: "key contains invalid character",
+		},
+		"empty key": {
+			Tags: map[string]string{
+				"": "abc",
+			},
+			ExpectErr: "key must not be empty",
+		},
+		"invalid value": {
+			Tags: map[string]string{
+				"tenant": "abc\r\nX-Injected: true",
+			},
+			ExpectErr: "value contains invalid character",
+		},
+		"value too long": {
+			Tags: map[string]string{
+				"tenant": strings.Repeat("a", 257),
+			},
+			ExpectErr: "value must be at most 256 characters",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+
+			var optFns []func(*CostTagsOptions)
+			if c.Options != nil {
+				optFns = append(optFns, c.Options)
+			}
+			if err := AddCostTagsMiddleware(stack, optFns...); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var header http.Header
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				header = input.(*Request).Header
+				return nil, middleware.Metadata{}, nil
+			})
+
+			ctx := context.Background()
+			if c.Tags != nil {
+				ctx = SetCostTags(ctx, c.Tags)
+			}
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				var tagErr *InvalidCostTagError
+				if !errors.As(err, &tagErr) {
+					t.Errorf("expect %T error, got %v", tagErr, err)
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.ExpectHeader, header); len(diff) != 0 {
+				t.Errorf("expect headers to match\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetCostTags_Copies(t *testing.T) {
+	tags := map[string]string{"tenant": "abc"}
+	ctx := SetCostTags(context.Background(), tags)
+	tags["tenant"] = "xyz"
+
+	if e, a := "abc", GetCostTags(ctx)["tenant"]; e != a {
+		t.Errorf("expect %q tag, got %q", e, a)
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type endpointOverrideKey struct{}
+
+// SetEndpointOverride returns a context with the endpoint URL the operation's
+// request is sent to, overriding the resolved endpoint. The endpoint must be
+// an absolute URL, (e.g. https://shard-1.example.com/prefix).
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetEndpointOverride(ctx context.Context, endpoint string) context.Context {
+	return middleware.WithStackValue(ctx, endpointOverrideKey{}, endpoint)
+}
+
+// GetEndpointOverride returns the endpoint URL override, and if the value was
+// set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetEndpointOverride(ctx context.Context) (string, bool) {
+	v, ok := middleware.GetStackValue(ctx, endpointOverrideKey{}).(string)
+	return v, ok
+}
+
+// AddEndpointOverrideMiddleware adds the middleware to apply the endpoint
+// override set with SetEndpointOverride to the end of the stack's Build
+// step. The middleware should be added after any other middleware modifying
+// the request's endpoint, so that the override takes precedence.
+func AddEndpointOverrideMiddleware(stack *middleware.Stack) error {
+	return stack.Build.Add(&endpointOverride{}, middleware.After)
+}
+
+type endpointOverride struct{}
+
+// ID returns the middleware identifier.
+func (*endpointOverride) ID() string { return "EndpointOverride" }
+
+// HandleBuild updates the request's URL to the endpoint override, if one was
+// set. The override's path is prefixed to the request's path.
+func (m *endpointOverride) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	v, ok := GetEndpointOverride(ctx)
+	if !ok {
+		return next.HandleBuild(ctx, in)
+	}
+
+	endpoint, err := url.Parse(v)
+	if err != nil {
+		return out, metadata, fmt.Errorf("invalid endpoint override %q, %w", v, err)
+	}
+	if !endpoint.IsAbs() || len(endpoint.Host) == 0 {
+		return out, metadata, fmt.Errorf("endpoint override %q must be an absolute URL", v)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	req.URL.Scheme = endpoint.Scheme
+	req.URL.Host = endpoint.Host
+	if len(endpoint.Path) != 0 {
+		req.URL.Path = JoinPath(endpoint.Path, req.URL.Path)
+		if len(req.URL.RawPath) != 0 {
+			req.URL.RawPath = JoinPath(endpoint.EscapedPath(), req.URL.RawPath)
+		}
+	}
+
+	// Clear the request's host so the Host header sent, and signed, is that
+	// of the override's host.
+	req.Host = ""
+
+	return next.HandleBuild(ctx, in)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestEndpointOverrideMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Override   string
+		NoOverride bool
+		ExpectURL  string
+		ExpectErr  string
+	}{
+		"no override": {
+			NoOverride: true,
+			ExpectURL:  "https://resolved.example.com/bucket/key",
+		},
+		"override": {
+			Override:  "http://localhost:8080",
+			ExpectURL: "http://localhost:8080/bucket/key",
+		},
+		"override with path": {
+			Override:  "https://shard-1.example.com/prefix/",
+			ExpectURL: "https://shard-1.example.com/prefix/bucket/key",
+		},
+		"relative override": {
+			Override:  "/prefix",
+			ExpectErr: "must be an absolute URL",
+		},
+		"host only override": {
+			Override:  "shard-1.example.com",
+			ExpectErr: "must be an absolute URL",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("ResolveEndpoint",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					middleware.SerializeOutput, middleware.Metadata, error,
+				) {
+					req := in.Request.(*Request)
+					req.URL.Scheme = "https"
+					req.URL.Host = "resolved.example.com"
+					req.URL.Path = "/bucket/key"
+					req.Host = "resolved.example.com"
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+			if err := AddEndpointOverrideMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var sent *Request
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				sent = input.(*Request)
+				return &Response{}, middleware.Metadata{}, nil
+			})
+
+			ctx := context.Background()
+			if !c.NoOverride {
+				ctx = SetEndpointOverride(ctx, c.Override)
+			}
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %v, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectURL, sent.URL.String(); e != a {
+				t.Errorf("expect %v URL, got %v", e, a)
+			}
+			if !c.NoOverride && len(sent.Host) != 0 {
+				t.Errorf("expect request host to be cleared, got %v", sent.Host)
+			}
+		})
+	}
+}
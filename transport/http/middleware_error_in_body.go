@@ -0,0 +1,106 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	stdxml "encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/smithy-go"
+	smithyxml "github.com/aws/smithy-go/encoding/xml"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AddErrorInBodyMiddleware adds the middleware detecting error responses
+// returned with a successful HTTP status code to the end of the stack's
+// Deserialize step, (e.g. an S3 CopyObject failing after the response status
+// was sent).
+//
+// The middleware buffers the body of successful responses, and must only be
+// added to operations whose service may return an error in the body of a
+// successful response. It must not be added to operations with a streaming
+// output payload.
+func AddErrorInBodyMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(&errorInBody{}, middleware.After)
+}
+
+type errorInBody struct{}
+
+// ID returns the middleware identifier.
+func (*errorInBody) ID() string { return "ErrorInBody" }
+
+// HandleDeserialize inspects the body of successful responses for an XML
+// error document. If the body is an error document, an APIError wrapped in a
+// ResponseError is returned. Otherwise the body is replaced with the buffered
+// copy so that it can be deserialized.
+//
+// Only bodies whose root element is Error, with a non-empty Code element, are
+// considered an error document.
+func (m *errorInBody) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || resp.Body == nil {
+		return out, metadata, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to read response body, %w", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if !isXMLErrorDocument(body) {
+		return out, metadata, nil
+	}
+
+	ec, decodeErr := smithyxml.DecodeErrorResponseComponents(bytes.NewReader(body))
+	if decodeErr != nil || len(ec.Code) == 0 {
+		return out, metadata, nil
+	}
+
+	return out, metadata, &ResponseError{
+		Response: resp,
+		Err: &smithy.GenericAPIError{
+			Code:    ec.Code,
+			Message: ec.Message,
+		},
+	}
+}
+
+// isXMLErrorDocument returns if the root element of the XML document is
+// Error. Returns false if the body is not an XML document.
+func isXMLErrorDocument(body []byte) bool {
+	decoder := stdxml.NewDecoder(bytes.NewReader(body))
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+
+		switch el := t.(type) {
+		case stdxml.StartElement:
+			return el.Name.Local == "Error"
+		case stdxml.CharData:
+			if len(bytes.TrimSpace(el)) != 0 {
+				return false
+			}
+		case stdxml.ProcInst, stdxml.Comment, stdxml.Directive:
+		default:
+			return false
+		}
+	}
+}
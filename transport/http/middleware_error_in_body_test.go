@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestErrorInBodyMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		StatusCode    int
+		Body          string
+		ExpectCode    string
+		ExpectMessage string
+	}{
+		"error in 200 body": {
+			StatusCode: 200,
+			Body: `<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>InternalError</Code>
+  <Message>We encountered an internal error. Please try again.</Message>
+  <RequestId>656c76696e6727732072657175657374</RequestId>
+</Error>`,
+			ExpectCode:    "InternalError",
+			ExpectMessage: "We encountered an internal error. Please try again.",
+		},
+		"successful body": {
+			StatusCode: 200,
+			Body: `<?xml version="1.0" encoding="UTF-8"?>
+<CopyObjectResult>
+  <LastModified>2009-10-12T17:50:30.000Z</LastModified>
+  <ETag>"9b2cf535f27731c974343645a3985328"</ETag>
+</CopyObjectResult>`,
+		},
+		"successful body with error member": {
+			StatusCode: 200,
+			Body:       `<DeleteResult><Error><Code>AccessDenied</Code><Key>abc</Key></Error></DeleteResult>`,
+		},
+		"error root without code": {
+			StatusCode: 200,
+			Body:       `<Error><Message>not an error envelope</Message></Error>`,
+		},
+		"non xml body": {
+			StatusCode: 200,
+			Body:       `{"Error":{"Code":"InternalError"}}`,
+		},
+		"empty body": {
+			StatusCode: 200,
+		},
+		"error status": {
+			StatusCode: 500,
+			Body:       `<Error><Code>InternalError</Code></Error>`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			if err := AddErrorInBodyMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				return &Response{
+					Response: &http.Response{
+						StatusCode: c.StatusCode,
+						Header:     http.Header{},
+						Body:       ioutil.NopCloser(strings.NewReader(c.Body)),
+					},
+				}, middleware.Metadata{}, nil
+			})
+
+			var resp *Response
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("captureResponse",
+				func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out, metadata, err = next.HandleDeserialize(ctx, in)
+					resp, _ = out.RawResponse.(*Response)
+					return out, metadata, err
+				}), middleware.After)
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if len(c.ExpectCode) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				var respErr *ResponseError
+				if !errors.As(err, &respErr) {
+					t.Errorf("expect %T error, got %v", respErr, err)
+				}
+				var apiErr smithy.APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("expect %T error, got %v", apiErr, err)
+				}
+				if e, a := c.ExpectCode, apiErr.ErrorCode(); e != a {
+					t.Errorf("expect %q code, got %q", e, a)
+				}
+				if e, a := c.ExpectMessage, apiErr.ErrorMessage(); e != a {
+					t.Errorf("expect %q message, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			actual, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Body, string(actual); e != a {
+				t.Errorf("expect body %q, got %q", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AddCaptureETagMiddleware adds the middleware to capture the ETag and
+// Last-Modified headers of successful responses into the operation's
+// metadata. The captured values can be used as the preconditions of a
+// subsequent conditional request, (e.g. If-Match, If-Unmodified-Since), for
+// optimistic locking.
+func AddCaptureETagMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(&captureETag{}, middleware.After)
+}
+
+type (
+	etagKey         struct{}
+	lastModifiedKey struct{}
+)
+
+// GetETag returns the entity tag of the response captured in the metadata,
+// and if the value was set. The entity tag is returned as sent by the
+// service, including quotes and any weak validator prefix.
+func GetETag(metadata middleware.MetadataReader) (string, bool) {
+	v, ok := metadata.Get(etagKey{}).(string)
+	return v, ok
+}
+
+// GetLastModified returns the last modified time of the response captured in
+// the metadata, and if the value was set.
+func GetLastModified(metadata middleware.MetadataReader) (time.Time, bool) {
+	v, ok := metadata.Get(lastModifiedKey{}).(time.Time)
+	return v, ok
+}
+
+type captureETag struct{}
+
+// ID returns the middleware identifier.
+func (*captureETag) ID() string { return "CaptureETag" }
+
+// HandleDeserialize captures the ETag and Last-Modified headers of the raw
+// response if the response status code is in the 2xx range.
+func (m *captureETag) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil {
+		return out, metadata, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return out, metadata, err
+	}
+
+	if v := resp.Header.Get("ETag"); len(v) != 0 {
+		metadata.Set(etagKey{}, v)
+	}
+	if v := resp.Header.Get("Last-Modified"); len(v) != 0 {
+		if t, parseErr := ParseTime(v); parseErr == nil {
+			metadata.Set(lastModifiedKey{}, t)
+		}
+	}
+
+	return out, metadata, err
+}
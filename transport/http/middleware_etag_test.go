@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestCaptureETagMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		StatusCode         int
+		Header             http.Header
+		ExpectETag         string
+		ExpectETagOK       bool
+		ExpectLastModified time.Time
+		ExpectLastModOK    bool
+	}{
+		"captured": {
+			StatusCode: 200,
+			Header: http.Header{
+				"Etag":          []string{`"abc123"`},
+				"Last-Modified": []string{"Wed, 21 Oct 2015 07:28:00 GMT"},
+			},
+			ExpectETag:         `"abc123"`,
+			ExpectETagOK:       true,
+			ExpectLastModified: time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC),
+			ExpectLastModOK:    true,
+		},
+		"weak etag": {
+			StatusCode:   204,
+			Header:       http.Header{"Etag": []string{`W/"abc123"`}},
+			ExpectETag:   `W/"abc123"`,
+			ExpectETagOK: true,
+		},
+		"no headers": {
+			StatusCode: 200,
+			Header:     http.Header{},
+		},
+		"not successful": {
+			StatusCode: 412,
+			Header:     http.Header{"Etag": []string{`"abc123"`}},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &captureETag{}
+			_, metadata, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out.RawResponse = &Response{
+						Response: &http.Response{StatusCode: c.StatusCode, Header: c.Header},
+					}
+					return out, metadata, nil
+				}))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			etag, ok := GetETag(metadata)
+			if e, a := c.ExpectETagOK, ok; e != a {
+				t.Errorf("expect %v etag ok, got %v", e, a)
+			}
+			if e, a := c.ExpectETag, etag; e != a {
+				t.Errorf("expect %v etag, got %v", e, a)
+			}
+
+			lastModified, ok := GetLastModified(metadata)
+			if e, a := c.ExpectLastModOK, ok; e != a {
+				t.Errorf("expect %v last modified ok, got %v", e, a)
+			}
+			if e, a := c.ExpectLastModified, lastModified; !e.Equal(a) {
+				t.Errorf("expect %v last modified, got %v", e, a)
+			}
+		})
+	}
+}
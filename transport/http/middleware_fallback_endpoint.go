@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AddFallbackEndpointMiddleware adds the middleware sending the request to
+// the fallback endpoint if the request could not be sent to the primary
+// endpoint, to the front of the stack's Finalize step. The fallback endpoint
+// must be an absolute URL, (e.g. https://backup.example.com). Only the
+// scheme, and host of the request are replaced, the request's path is not
+// modified.
+//
+// The request falls back only if the primary endpoint's attempts failed with
+// a connection error, (e.g. RequestSendError), not if the service returned an
+// error response. The request is sent to the fallback once, through the rest
+// of the Finalize step, so that it is retried, and re-signed for the fallback
+// host.
+func AddFallbackEndpointMiddleware(stack *middleware.Stack, endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid fallback endpoint %q, %w", endpoint, err)
+	}
+	if !u.IsAbs() || len(u.Host) == 0 {
+		return fmt.Errorf("fallback endpoint %q must be an absolute URL", endpoint)
+	}
+
+	return stack.Finalize.Add(&fallbackEndpoint{endpoint: u}, middleware.Before)
+}
+
+type fallbackEndpointKey struct{}
+
+// GetFallbackEndpoint returns the fallback endpoint the request was sent to,
+// and if the request fell back from the primary endpoint.
+func GetFallbackEndpoint(metadata middleware.MetadataReader) (string, bool) {
+	v, ok := metadata.Get(fallbackEndpointKey{}).(string)
+	return v, ok
+}
+
+type fallbackEndpoint struct {
+	endpoint *url.URL
+}
+
+// ID returns the middleware identifier.
+func (*fallbackEndpoint) ID() string { return "FallbackEndpoint" }
+
+// HandleFinalize invokes the next handler with the request, and again with
+// the request sent to the fallback endpoint if the first invocation failed
+// with a connection error.
+func (m *fallbackEndpoint) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	// The following middleware may modify the request, (e.g. signing), so
+	// the fallback is cloned from the unmodified request.
+	in.Request = req.Clone()
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	if err == nil || !isConnectionError(err) || ctx.Err() != nil {
+		return out, metadata, err
+	}
+
+	fallback := req.Clone()
+	fallback.URL.Scheme = m.endpoint.Scheme
+	fallback.URL.Host = m.endpoint.Host
+	fallback.Host = ""
+	if rewindErr := fallback.RewindStream(); rewindErr != nil {
+		return out, metadata, fmt.Errorf("failed to rewind transport stream for fallback endpoint, %v, %w",
+			rewindErr, err)
+	}
+
+	in.Request = fallback
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	metadata.Set(fallbackEndpointKey{}, m.endpoint.Scheme+"://"+m.endpoint.Host)
+
+	return out, metadata, err
+}
+
+func isConnectionError(err error) bool {
+	var v interface{ ConnectionError() bool }
+	return errors.As(err, &v) && v.ConnectionError()
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFallbackEndpointMiddleware(t *testing.T) {
+	type attempt struct {
+		URL           string
+		Authorization string
+	}
+
+	cases := map[string]struct {
+		Handle         func(req *Request) (*Response, error)
+		ExpectAttempts []attempt
+		ExpectFallback bool
+		ExpectErr      string
+	}{
+		"primary succeeds": {
+			Handle: func(req *Request) (*Response, error) {
+				return &Response{Response: &http.Response{StatusCode: 200}}, nil
+			},
+			ExpectAttempts: []attempt{
+				{URL: "https://primary.example.com/foo", Authorization: "signed primary.example.com"},
+			},
+		},
+		"primary connection failure": {
+			Handle: func(req *Request) (*Response, error) {
+				if req.URL.Host == "primary.example.com" {
+					return nil, &RequestSendError{Err: fmt.Errorf("dial tcp: connection refused")}
+				}
+				return &Response{Response: &http.Response{StatusCode: 200}}, nil
+			},
+			ExpectAttempts: []attempt{
+				{URL: "https://primary.example.com/foo", Authorization: "signed primary.example.com"},
+				{URL: "http://backup.example.com:8080/foo", Authorization: "signed backup.example.com:8080"},
+			},
+			ExpectFallback: true,
+		},
+		"fallback connection failure": {
+			Handle: func(req *Request) (*Response, error) {
+				return nil, &RequestSendError{Err: fmt.Errorf("dial tcp: connection refused")}
+			},
+			ExpectAttempts: []attempt{
+				{URL: "https://primary.example.com/foo", Authorization: "signed primary.example.com"},
+				{URL: "http://backup.example.com:8080/foo", Authorization: "signed backup.example.com:8080"},
+			},
+			ExpectFallback: true,
+			ExpectErr:      "connection refused",
+		},
+		"server error": {
+			Handle: func(req *Request) (*Response, error) {
+				resp := &Response{Response: &http.Response{StatusCode: 503}}
+				return resp, &ResponseError{
+					Response: resp,
+					Err:      &smithy.GenericAPIError{Code: "ServiceUnavailable"},
+				}
+			},
+			ExpectAttempts: []attempt{
+				{URL: "https://primary.example.com/foo", Authorization: "signed primary.example.com"},
+			},
+			ExpectErr: "ServiceUnavailable",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					middleware.SerializeOutput, middleware.Metadata, error,
+				) {
+					req := in.Request.(*Request)
+					req.URL.Scheme = "https"
+					req.URL.Host = "primary.example.com"
+					req.URL.Path = "/foo"
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+			stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Signing",
+				func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+					middleware.FinalizeOutput, middleware.Metadata, error,
+				) {
+					req := in.Request.(*Request)
+					req.Header.Set("Authorization", "signed "+req.URL.Host)
+					return next.HandleFinalize(ctx, in)
+				}), middleware.After)
+			if err := AddFallbackEndpointMiddleware(stack, "http://backup.example.com:8080"); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var attempts []attempt
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				req := input.(*Request)
+				attempts = append(attempts, attempt{
+					URL:           req.URL.String(),
+					Authorization: req.Header.Get("Authorization"),
+				})
+				resp, err := c.Handle(req)
+				return resp, middleware.Metadata{}, err
+			})
+
+			_, metadata, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.ExpectAttempts, attempts); len(diff) != 0 {
+				t.Errorf("expect attempts to match\n%s", diff)
+			}
+
+			endpoint, ok := GetFallbackEndpoint(metadata)
+			if e, a := c.ExpectFallback, ok; e != a {
+				t.Fatalf("expect %v fallback, got %v", e, a)
+			}
+			if ok {
+				if e, a := "http://backup.example.com:8080", endpoint; e != a {
+					t.Errorf("expect %v fallback endpoint, got %v", e, a)
+				}
+			}
+		})
+	}
+}
+
+func TestAddFallbackEndpointMiddleware_Invalid(t *testing.T) {
+	stack := middleware.NewStack("stack", NewStackRequest)
+
+	err := AddFallbackEndpointMiddleware(stack, "backup.example.com")
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := "must be an absolute URL", err.Error(); !strings.Contains(a, e) {
+		t.Errorf("expect error to contain %q, got %q", e, a)
+	}
+}
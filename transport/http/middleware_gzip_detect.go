@@ -0,0 +1,90 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// AddDetectGzipMiddleware adds the middleware decompressing gzip response
+// bodies the service did not declare with a Content-Encoding header. The
+// middleware is added to the end of the stack's Deserialize step.
+//
+// The middleware is a compatibility shim for services that incorrectly omit
+// the Content-Encoding header, and should only be used with such services.
+// Bodies of responses with a Content-Encoding header are not modified.
+func AddDetectGzipMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(&detectGzip{}, middleware.After)
+}
+
+type detectGzip struct{}
+
+// ID returns the middleware identifier.
+func (*detectGzip) ID() string { return "DetectGzip" }
+
+// HandleDeserialize peeks the first bytes of the raw response's body. If the
+// body starts with the gzip magic number, the body is replaced with a reader
+// decompressing the body. Otherwise the body is replaced with a reader
+// including the peeked bytes.
+func (*detectGzip) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil || resp.Body == nil {
+		return out, metadata, err
+	}
+	if len(resp.Header.Get("Content-Encoding")) != 0 {
+		return out, metadata, err
+	}
+
+	br := bufio.NewReader(resp.Body)
+	peeked, peekErr := br.Peek(len(gzipMagic))
+	if peekErr != nil && peekErr != io.EOF {
+		return out, metadata, fmt.Errorf("failed to peek response body, %w", peekErr)
+	}
+
+	if !bytes.Equal(peeked, gzipMagic) {
+		resp.Body = &detectGzipBody{Reader: br, closer: resp.Body}
+		return out, metadata, err
+	}
+
+	gz, gzErr := gzip.NewReader(br)
+	if gzErr != nil {
+		return out, metadata, fmt.Errorf("failed to read gzip response body, %w", gzErr)
+	}
+
+	resp.Body = &detectGzipBody{Reader: gz, closer: resp.Body}
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return out, metadata, err
+}
+
+// detectGzipBody reads from the reader wrapping the response body, and closes
+// the underlying body.
+type detectGzipBody struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (b *detectGzipBody) Close() error {
+	if c, ok := b.Reader.(io.Closer); ok {
+		c.Close()
+	}
+	return b.closer.Close()
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestDetectGzipMiddleware(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte(`{"hello":"world"}`))
+	gz.Close()
+
+	cases := map[string]struct {
+		Header             http.Header
+		Body               []byte
+		Expect             []byte
+		ExpectUncompressed bool
+	}{
+		"gzip without header": {
+			Header:             http.Header{"Content-Length": []string{"37"}},
+			Body:               gzipped.Bytes(),
+			Expect:             []byte(`{"hello":"world"}`),
+			ExpectUncompressed: true,
+		},
+		"plain body": {
+			Header: http.Header{},
+			Body:   []byte(`{"hello":"world"}`),
+			Expect: []byte(`{"hello":"world"}`),
+		},
+		"single byte body": {
+			Header: http.Header{},
+			Body:   []byte{0x1f},
+			Expect: []byte{0x1f},
+		},
+		"empty body": {
+			Header: http.Header{},
+			Body:   []byte{},
+			Expect: []byte{},
+		},
+		"gzip with content encoding": {
+			Header: http.Header{"Content-Encoding": []string{"gzip"}},
+			Body:   gzipped.Bytes(),
+			Expect: gzipped.Bytes(),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			if err := AddDetectGzipMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var resp *Response
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				resp = &Response{
+					Response: &http.Response{
+						StatusCode: 200,
+						Header:     c.Header,
+						Body:       ioutil.NopCloser(bytes.NewReader(c.Body)),
+					},
+				}
+				return resp, middleware.Metadata{}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			actual, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := resp.Body.Close(); err != nil {
+				t.Errorf("expect no close error, got %v", err)
+			}
+
+			if e, a := c.Expect, actual; !bytes.Equal(e, a) {
+				t.Errorf("expect body %q, got %q", e, a)
+			}
+			if e, a := c.ExpectUncompressed, resp.Uncompressed; e != a {
+				t.Errorf("expect uncompressed %v, got %v", e, a)
+			}
+			if c.ExpectUncompressed && len(resp.Header.Get("Content-Length")) != 0 {
+				t.Errorf("expect content length header removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultSingleValuedHeaders are the headers the header dedupe middleware
+// requires to be single valued by default.
+var DefaultSingleValuedHeaders = []string{
+	"Content-Length",
+	"Content-MD5",
+	"Content-Type",
+	"Date",
+	"Expect",
+}
+
+// ConflictingHeaderError is the error returned when a header that must be
+// single valued was set to conflicting values, (e.g. by multiple middleware).
+type ConflictingHeaderError struct {
+	Header string
+	Values []string
+}
+
+func (e *ConflictingHeaderError) Error() string {
+	return fmt.Sprintf("header %s must be single valued, got conflicting values [%s]",
+		e.Header, strings.Join(e.Values, ", "))
+}
+
+// HeaderDedupeOptions provides the options for the header dedupe middleware.
+type HeaderDedupeOptions struct {
+	// The headers that must be single valued. Defaults to
+	// DefaultSingleValuedHeaders.
+	SingleValuedHeaders []string
+}
+
+// AddHeaderDedupeMiddleware adds the middleware checking that single valued
+// headers have at most one value to the front of the stack's Finalize step,
+// after the request is built, and before it is retried, or signed.
+//
+// Headers set multiple times to the same value are collapsed into a single
+// value. Headers set to conflicting values fail the operation with a
+// ConflictingHeaderError, surfacing middleware that disagree on the header.
+func AddHeaderDedupeMiddleware(stack *middleware.Stack, optFns ...func(*HeaderDedupeOptions)) error {
+	options := HeaderDedupeOptions{
+		SingleValuedHeaders: DefaultSingleValuedHeaders,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	headers := make([]string, 0, len(options.SingleValuedHeaders))
+	for _, h := range options.SingleValuedHeaders {
+		headers = append(headers, http.CanonicalHeaderKey(h))
+	}
+
+	return stack.Finalize.Add(&headerDedupe{headers: headers}, middleware.Before)
+}
+
+type headerDedupe struct {
+	headers []string
+}
+
+// ID returns the middleware identifier.
+func (*headerDedupe) ID() string { return "HeaderDedupe" }
+
+// HandleFinalize collapses duplicate values of single valued headers, or
+// returns a ConflictingHeaderError if the values conflict.
+func (m *headerDedupe) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	for _, h := range m.headers {
+		values := req.Header[h]
+		if len(values) < 2 {
+			continue
+		}
+
+		for _, v := range values[1:] {
+			if v != values[0] {
+				return out, metadata, &ConflictingHeaderError{
+					Header: h,
+					Values: append([]string(nil), values...),
+				}
+			}
+		}
+		req.Header[h] = values[:1]
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
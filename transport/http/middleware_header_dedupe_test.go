@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHeaderDedupeMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Header       http.Header
+		Options      func(*HeaderDedupeOptions)
+		ExpectHeader http.Header
+		ExpectErr    *ConflictingHeaderError
+	}{
+		"single values": {
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+				"X-Foo":        []string{"a", "b"},
+			},
+			ExpectHeader: http.Header{
+				"Content-Type": []string{"application/json"},
+				"X-Foo":        []string{"a", "b"},
+			},
+		},
+		"identical duplicates": {
+			Header: http.Header{
+				"Content-Type":   []string{"application/json", "application/json"},
+				"Content-Length": []string{"3", "3", "3"},
+			},
+			ExpectHeader: http.Header{
+				"Content-Type":   []string{"application/json"},
+				"Content-Length": []string{"3"},
+			},
+		},
+		"conflicting values": {
+			Header: http.Header{
+				"Content-Type": []string{"application/json", "application/xml"},
+			},
+			ExpectErr: &ConflictingHeaderError{
+				Header: "Content-Type",
+				Values: []string{"application/json", "application/xml"},
+			},
+		},
+		"custom headers": {
+			Header: http.Header{
+				"Content-Type": []string{"application/json", "application/xml"},
+				"X-Foo":        []string{"a", "a"},
+			},
+			Options: func(o *HeaderDedupeOptions) {
+				o.SingleValuedHeaders = []string{"x-foo"}
+			},
+			ExpectHeader: http.Header{
+				"Content-Type": []string{"application/json", "application/xml"},
+				"X-Foo":        []string{"a"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Build.Add(middleware.BuildMiddlewareFunc("setHeaders",
+				func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (
+					middleware.BuildOutput, middleware.Metadata, error,
+				) {
+					in.Request.(*Request).Header = c.Header
+					return next.HandleBuild(ctx, in)
+				}), middleware.After)
+
+			var optFns []func(*HeaderDedupeOptions)
+			if c.Options != nil {
+				optFns = append(optFns, c.Options)
+			}
+			if err := AddHeaderDedupeMiddleware(stack, optFns...); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var header http.Header
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				header = input.(*Request).Header
+				return nil, middleware.Metadata{}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if c.ExpectErr != nil {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				var headerErr *ConflictingHeaderError
+				if !errors.As(err, &headerErr) {
+					t.Fatalf("expect %T error, got %v", headerErr, err)
+				}
+				if diff := cmp.Diff(c.ExpectErr, headerErr); len(diff) != 0 {
+					t.Errorf("expect error to match\n%s", diff)
+				}
+				if header != nil {
+					t.Errorf("expect request not sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.ExpectHeader, header); len(diff) != 0 {
+				t.Errorf("expect headers to match\n%s", diff)
+			}
+		})
+	}
+}
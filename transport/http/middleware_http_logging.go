@@ -11,6 +11,8 @@ import (
 
 // RequestResponseLogger is a deserialize middleware that will log the request and response HTTP messages and optionally
 // their respective bodies. Will not perform any logging if none of the options are set.
+//
+// The options are overridden for an operation invocation by the log modes set on the context, see SetLogMode.
 type RequestResponseLogger struct {
 	LogRequest         bool
 	LogRequestWithBody bool
@@ -32,21 +34,30 @@ func (r *RequestResponseLogger) HandleDeserialize(
 ) {
 	logger := middleware.GetLogger(ctx)
 
-	if r.LogRequest || r.LogRequestWithBody {
+	logRequest, logRequestWithBody := r.LogRequest, r.LogRequestWithBody
+	logResponse, logResponseWithBody := r.LogResponse, r.LogResponseWithBody
+	if mode, ok := GetLogMode(ctx); ok {
+		logRequestWithBody = mode.IsRequest() && mode.IsBody()
+		logRequest = mode.IsRequest() && !logRequestWithBody
+		logResponseWithBody = mode.IsResponse() && mode.IsBody()
+		logResponse = mode.IsResponse() && !logResponseWithBody
+	}
+
+	if logRequest || logRequestWithBody {
 		smithyRequest, ok := in.Request.(*Request)
 		if !ok {
 			return out, metadata, fmt.Errorf("unknown transport type %T", in)
 		}
 
 		rc := smithyRequest.Build(ctx)
-		reqBytes, err := httputil.DumpRequestOut(rc, r.LogRequestWithBody)
+		reqBytes, err := httputil.DumpRequestOut(rc, logRequestWithBody)
 		if err != nil {
 			return out, metadata, err
 		}
 
 		logger.Logf(logging.Debug, "Request\n%v", string(reqBytes))
 
-		if r.LogRequestWithBody {
+		if logRequestWithBody {
 			smithyRequest, err = smithyRequest.SetStream(rc.Body)
 			if err != nil {
 				return out, metadata, err
@@ -57,13 +68,13 @@ func (r *RequestResponseLogger) HandleDeserialize(
 
 	out, metadata, err = next.HandleDeserialize(ctx, in)
 
-	if (err == nil) && (r.LogResponse || r.LogResponseWithBody) {
+	if (err == nil) && (logResponse || logResponseWithBody) {
 		smithyResponse, ok := out.RawResponse.(*Response)
 		if !ok {
 			return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
 		}
 
-		respBytes, err := httputil.DumpResponse(smithyResponse.Response, r.LogResponseWithBody)
+		respBytes, err := httputil.DumpResponse(smithyResponse.Response, logResponseWithBody)
 		if err != nil {
 			return out, metadata, fmt.Errorf("failed to dump response %w", err)
 		}
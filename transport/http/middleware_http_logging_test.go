@@ -160,3 +160,90 @@ func TestRequestResponseLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestResponseLogger_LogMode(t *testing.T) {
+	m := smithyhttp.RequestResponseLogger{}
+
+	invoke := func(ctx context.Context) string {
+		logger := mockLogger{}
+		ctx = middleware.SetLogger(ctx, &logger)
+
+		req := &smithyhttp.Request{
+			Request: &http.Request{
+				URL: &url.URL{
+					Scheme: "https",
+					Path:   "/foo",
+					Host:   "example.amazonaws.com",
+				},
+				Header:        map[string][]string{},
+				ContentLength: 16,
+			},
+		}
+		req, err := req.SetStream(bytes.NewReader([]byte(`this is the body`)))
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		_, _, err = m.HandleDeserialize(ctx, middleware.DeserializeInput{Request: req}, middleware.DeserializeHandlerFunc(func(ctx context.Context, input middleware.DeserializeInput) (
+			middleware.DeserializeOutput, middleware.Metadata, error,
+		) {
+			return middleware.DeserializeOutput{
+				RawResponse: &smithyhttp.Response{
+					Response: &http.Response{
+						StatusCode:    200,
+						ContentLength: 17,
+						Header:        map[string][]string{},
+						Body:          ioutil.NopCloser(bytes.NewReader([]byte(`response the body`))),
+					},
+				},
+			}, middleware.Metadata{}, nil
+		}))
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		return logger.String()
+	}
+
+	verbose := invoke(smithyhttp.SetLogMode(context.Background(),
+		smithyhttp.LogRequest|smithyhttp.LogResponse|smithyhttp.LogBody))
+	expect := "Request\n" +
+		"GET /foo HTTP/1.1\r\n" +
+		"Host: example.amazonaws.com\r\n" +
+		"User-Agent: Go-http-client/1.1\r\n" +
+		"Content-Length: 16\r\n" +
+		"Accept-Encoding: gzip\r\n" +
+		"\r\n" +
+		"this is the body\n" +
+		"Response\n" +
+		"HTTP/0.0 200 OK\r\n" +
+		"Content-Length: 17\r\n" +
+		"\r\n" +
+		"response the body\n"
+	if diff := cmp.Diff(expect, verbose); len(diff) > 0 {
+		t.Error(diff)
+	}
+
+	if quiet := invoke(context.Background()); len(quiet) != 0 {
+		t.Errorf("expect no logs without log mode, got %q", quiet)
+	}
+}
+
+func TestRequestResponseLogger_LogModeDisables(t *testing.T) {
+	m := smithyhttp.RequestResponseLogger{LogRequest: true, LogResponse: true}
+
+	logger := mockLogger{}
+	ctx := middleware.SetLogger(context.Background(), &logger)
+	ctx = smithyhttp.SetLogMode(ctx, 0)
+
+	_, _, err := m.HandleDeserialize(ctx, middleware.DeserializeInput{Request: smithyhttp.NewStackRequest()}, middleware.DeserializeHandlerFunc(func(ctx context.Context, input middleware.DeserializeInput) (
+		middleware.DeserializeOutput, middleware.Metadata, error,
+	) {
+		return middleware.DeserializeOutput{}, middleware.Metadata{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if logs := logger.String(); len(logs) != 0 {
+		t.Errorf("expect no logs, got %q", logs)
+	}
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// SmithyAPIError is the canonical API error operation errors are normalized
+// into by the NormalizeAPIError middleware, regardless of the protocol the
+// error was deserialized from. The original error is available with Unwrap.
+type SmithyAPIError struct {
+	Code      string
+	Message   string
+	Fault     smithy.ErrorFault
+	RequestID string
+
+	// The HTTP status code of the error response, or zero if there was no
+	// response.
+	StatusCode int
+
+	// Informational only, whether the error is likely to succeed if the
+	// operation is retried. Set from the original error's RetryableError
+	// method if implemented, otherwise set for server faults, and throttling,
+	// (429), responses. Does not affect the retryer, which classifies the
+	// original error.
+	Retryable bool
+
+	Err error
+}
+
+// ErrorCode returns the error code for the API exception.
+func (e *SmithyAPIError) ErrorCode() string { return e.Code }
+
+// ErrorMessage returns the error message for the API exception.
+func (e *SmithyAPIError) ErrorMessage() string { return e.Message }
+
+// ErrorFault returns the fault for the API exception.
+func (e *SmithyAPIError) ErrorFault() smithy.ErrorFault { return e.Fault }
+
+// HTTPStatusCode returns the HTTP status code of the error response.
+func (e *SmithyAPIError) HTTPStatusCode() int { return e.StatusCode }
+
+// Unwrap returns the original error.
+func (e *SmithyAPIError) Unwrap() error { return e.Err }
+
+func (e *SmithyAPIError) Error() string {
+	return fmt.Sprintf("api error %s: %s, StatusCode: %d, RequestID: %s",
+		e.Code, e.Message, e.StatusCode, e.RequestID)
+}
+
+var _ smithy.APIError = (*SmithyAPIError)(nil)
+
+// NormalizeAPIErrorOptions provides the options for the NormalizeAPIError
+// middleware.
+type NormalizeAPIErrorOptions struct {
+	// The response headers the request ID is read from. Defaults to
+	// X-Amzn-Requestid, and X-Amz-Request-Id.
+	RequestIDHeaders []string
+}
+
+// AddNormalizeAPIErrorMiddleware adds the middleware normalizing API errors
+// into a SmithyAPIError to the front of the stack's Deserialize step, so that
+// errors are normalized after all other deserialize middleware.
+//
+// Only errors that include an smithy.APIError, (e.g. modeled, and unmodeled
+// service errors), are normalized. Other errors are returned as is.
+func AddNormalizeAPIErrorMiddleware(stack *middleware.Stack, optFns ...func(*NormalizeAPIErrorOptions)) error {
+	options := NormalizeAPIErrorOptions{
+		RequestIDHeaders: []string{"X-Amzn-Requestid", "X-Amz-Request-Id"},
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Deserialize.Add(&normalizeAPIError{options: options}, middleware.Before)
+}
+
+type normalizeAPIError struct {
+	options NormalizeAPIErrorOptions
+}
+
+// ID returns the middleware identifier.
+func (*normalizeAPIError) ID() string { return "NormalizeAPIError" }
+
+// HandleDeserialize replaces API errors returned by the next handler with a
+// SmithyAPIError wrapping the original error.
+func (m *normalizeAPIError) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err == nil {
+		return out, metadata, err
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return out, metadata, err
+	}
+	var normalized *SmithyAPIError
+	if errors.As(err, &normalized) {
+		return out, metadata, err
+	}
+
+	normalized = &SmithyAPIError{
+		Code:    apiErr.ErrorCode(),
+		Message: apiErr.ErrorMessage(),
+		Fault:   apiErr.ErrorFault(),
+		Err:     err,
+	}
+
+	if resp, ok := out.RawResponse.(*Response); ok && resp != nil && resp.Response != nil {
+		normalized.StatusCode = resp.StatusCode
+		for _, h := range m.options.RequestIDHeaders {
+			if v := resp.Header.Get(h); len(v) != 0 {
+				normalized.RequestID = v
+				break
+			}
+		}
+	}
+
+	if normalized.Fault == smithy.FaultUnknown {
+		switch {
+		case normalized.StatusCode >= 500:
+			normalized.Fault = smithy.FaultServer
+		case normalized.StatusCode >= 400:
+			normalized.Fault = smithy.FaultClient
+		}
+	}
+
+	var retryable interface{ RetryableError() bool }
+	if errors.As(err, &retryable) {
+		normalized.Retryable = retryable.RetryableError()
+	} else {
+		normalized.Retryable = normalized.Fault == smithy.FaultServer ||
+			normalized.StatusCode == 429
+	}
+
+	return out, metadata, normalized
+}
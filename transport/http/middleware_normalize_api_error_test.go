@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	smithyxml "github.com/aws/smithy-go/encoding/xml"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type mockModeledError struct {
+	Message string
+}
+
+func (e *mockModeledError) Error() string                 { return "ResourceNotFound: " + e.Message }
+func (e *mockModeledError) ErrorCode() string             { return "ResourceNotFound" }
+func (e *mockModeledError) ErrorMessage() string          { return e.Message }
+func (e *mockModeledError) ErrorFault() smithy.ErrorFault { return smithy.FaultClient }
+
+// deserializeJSONError decodes errors in the style of the JSON protocols,
+// with the error code in the __type member.
+func deserializeJSONError(resp *Response) error {
+	var body struct {
+		Type    string `json:"__type"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	code := body.Type
+	if i := strings.LastIndex(code, "#"); i != -1 {
+		code = code[i+1:]
+	}
+	if code == "ResourceNotFound" {
+		return &mockModeledError{Message: body.Message}
+	}
+	return &smithy.GenericAPIError{Code: code, Message: body.Message}
+}
+
+// deserializeXMLError decodes errors in the style of the XML protocols.
+func deserializeXMLError(resp *Response) error {
+	ec, err := smithyxml.DecodeErrorResponseComponents(resp.Body)
+	if err != nil {
+		return err
+	}
+	return &smithy.GenericAPIError{Code: ec.Code, Message: ec.Message}
+}
+
+func TestNormalizeAPIErrorMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		StatusCode  int
+		Header      http.Header
+		Body        string
+		Deserialize func(*Response) error
+		Expect      *SmithyAPIError
+		ExpectErr   string
+	}{
+		"json protocol": {
+			StatusCode:  400,
+			Header:      http.Header{"X-Amzn-Requestid": []string{"req-1234"}},
+			Body:        `{"__type":"com.example#ValidationException","message":"invalid name"}`,
+			Deserialize: deserializeJSONError,
+			Expect: &SmithyAPIError{
+				Code:       "ValidationException",
+				Message:    "invalid name",
+				Fault:      smithy.FaultClient,
+				RequestID:  "req-1234",
+				StatusCode: 400,
+			},
+		},
+		"xml protocol": {
+			StatusCode: 400,
+			Header:     http.Header{"X-Amz-Request-Id": []string{"req-1234"}},
+			Body: `<ErrorResponse><Error><Code>ValidationException</Code>` +
+				`<Message>invalid name</Message></Error></ErrorResponse>`,
+			Deserialize: deserializeXMLError,
+			Expect: &SmithyAPIError{
+				Code:       "ValidationException",
+				Message:    "invalid name",
+				Fault:      smithy.FaultClient,
+				RequestID:  "req-1234",
+				StatusCode: 400,
+			},
+		},
+		"server fault": {
+			StatusCode:  503,
+			Header:      http.Header{},
+			Body:        `<Error><Code>ServiceUnavailable</Code><Message>slow down</Message></Error>`,
+			Deserialize: deserializeXMLError,
+			Expect: &SmithyAPIError{
+				Code:       "ServiceUnavailable",
+				Message:    "slow down",
+				Fault:      smithy.FaultServer,
+				StatusCode: 503,
+				Retryable:  true,
+			},
+		},
+		"modeled error": {
+			StatusCode:  404,
+			Header:      http.Header{"X-Amzn-Requestid": []string{"req-5678"}},
+			Body:        `{"__type":"ResourceNotFound","message":"no such table"}`,
+			Deserialize: deserializeJSONError,
+			Expect: &SmithyAPIError{
+				Code:       "ResourceNotFound",
+				Message:    "no such table",
+				Fault:      smithy.FaultClient,
+				RequestID:  "req-5678",
+				StatusCode: 404,
+			},
+		},
+		"not api error": {
+			StatusCode: 500,
+			Header:     http.Header{},
+			Deserialize: func(*Response) error {
+				return fmt.Errorf("failed to decode response")
+			},
+			ExpectErr: "failed to decode response",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			if err := AddNormalizeAPIErrorMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer",
+				func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out, metadata, err = next.HandleDeserialize(ctx, in)
+					if err != nil {
+						return out, metadata, err
+					}
+					resp := out.RawResponse.(*Response)
+					return out, metadata, &ResponseError{Response: resp, Err: c.Deserialize(resp)}
+				}), middleware.After)
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				return &Response{
+					Response: &http.Response{
+						StatusCode: c.StatusCode,
+						Header:     c.Header,
+						Body:       ioutil.NopCloser(strings.NewReader(c.Body)),
+					},
+				}, middleware.Metadata{}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if err == nil {
+				t.Fatalf("expect error, got none")
+			}
+
+			if len(c.ExpectErr) != 0 {
+				var normalized *SmithyAPIError
+				if errors.As(err, &normalized) {
+					t.Errorf("expect error not normalized, got %v", err)
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+				return
+			}
+
+			var normalized *SmithyAPIError
+			if !errors.As(err, &normalized) {
+				t.Fatalf("expect %T error, got %v", normalized, err)
+			}
+			if diff := cmp.Diff(c.Expect, normalized, cmpopts.IgnoreFields(SmithyAPIError{}, "Err")); len(diff) != 0 {
+				t.Errorf("expect normalized error to match\n%s", diff)
+			}
+
+			var respErr *ResponseError
+			if !errors.As(normalized.Unwrap(), &respErr) {
+				t.Errorf("expect original %T error accessible, got %v", respErr, normalized.Unwrap())
+			}
+		})
+	}
+}
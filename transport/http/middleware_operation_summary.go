@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// OperationSummaryLogger is an initialize middleware that logs a single
+// summary line at the end of each operation, with the operation's name,
+// service, HTTP status, attempts, total latency, response size, and request
+// ID, formatted as space separated key=value pairs.
+//
+// The HTTP status, response size, and request ID are captured from the raw
+// response by a deserialize middleware added with the logger by
+// AddOperationSummaryLoggerMiddleware.
+type OperationSummaryLogger struct {
+	ServiceID     string
+	OperationName string
+
+	// The classification of summaries of successful operations. Defaults to
+	// logging.Info.
+	SuccessClassification logging.Classification
+
+	// The classification of summaries of failed operations. Defaults to
+	// logging.Warn.
+	FailureClassification logging.Classification
+
+	// Returns the number of attempts made for the operation from the
+	// operation's metadata, (e.g. retry.GetAttemptCount). If nil, or the
+	// value is not set, one attempt is logged.
+	GetAttemptCount func(middleware.MetadataReader) (int, bool)
+
+	// The response headers the request ID is read from. Defaults to
+	// X-Amzn-Requestid, and X-Amz-Request-Id.
+	RequestIDHeaders []string
+}
+
+// AddOperationSummaryLoggerMiddleware adds the OperationSummaryLogger to the
+// front of the stack's Initialize step, so that the latency includes all
+// other middleware, and the middleware capturing the raw response's summary
+// to the end of the Deserialize step.
+func AddOperationSummaryLoggerMiddleware(stack *middleware.Stack, m *OperationSummaryLogger) error {
+	if err := stack.Initialize.Add(m, middleware.Before); err != nil {
+		return fmt.Errorf("failed to add %s initialize middleware, %w", m.ID(), err)
+	}
+
+	headers := m.RequestIDHeaders
+	if len(headers) == 0 {
+		headers = []string{"X-Amzn-Requestid", "X-Amz-Request-Id"}
+	}
+	if err := stack.Deserialize.Add(&captureResponseSummary{requestIDHeaders: headers}, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s deserialize middleware, %w",
+			(*captureResponseSummary)(nil).ID(), err)
+	}
+
+	return nil
+}
+
+// ID is the middleware identifier.
+func (m *OperationSummaryLogger) ID() string { return "OperationSummaryLogger" }
+
+// HandleInitialize logs the summary of the operation after the next handler
+// returns.
+func (m *OperationSummaryLogger) HandleInitialize(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	start := timeNow()
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	latency := timeNow().Sub(start)
+
+	attempts := 1
+	if m.GetAttemptCount != nil {
+		if v, ok := m.GetAttemptCount(metadata); ok {
+			attempts = v
+		}
+	}
+
+	summary, _ := metadata.Get(responseSummaryKey{}).(responseSummary)
+
+	classification := m.SuccessClassification
+	if len(classification) == 0 {
+		classification = logging.Info
+	}
+	status := "success"
+	if err != nil {
+		classification = m.FailureClassification
+		if len(classification) == 0 {
+			classification = logging.Warn
+		}
+		status = "failure"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "operation=%s service=%s outcome=%s", m.OperationName, m.ServiceID, status)
+	fmt.Fprintf(&sb, " status=%d attempts=%d latency=%s", summary.StatusCode, attempts, latency)
+	fmt.Fprintf(&sb, " bytes=%d request_id=%s", summary.ContentLength, summary.RequestID)
+	if err != nil {
+		fmt.Fprintf(&sb, " error=%q", err.Error())
+	}
+
+	middleware.GetLogger(ctx).Logf(classification, "operation summary %s", sb.String())
+
+	return out, metadata, err
+}
+
+type responseSummaryKey struct{}
+
+// responseSummary is the summary of an operation's raw HTTP response.
+type responseSummary struct {
+	StatusCode    int
+	ContentLength int64
+	RequestID     string
+}
+
+type captureResponseSummary struct {
+	requestIDHeaders []string
+}
+
+// ID returns the middleware identifier.
+func (*captureResponseSummary) ID() string { return "CaptureResponseSummary" }
+
+// HandleDeserialize captures the summary of the raw response into the
+// metadata, for both successful and failed responses.
+func (m *captureResponseSummary) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil {
+		return out, metadata, err
+	}
+
+	summary := responseSummary{
+		StatusCode:    resp.StatusCode,
+		ContentLength: resp.ContentLength,
+	}
+	for _, h := range m.requestIDHeaders {
+		if v := resp.Header.Get(h); len(v) != 0 {
+			summary.RequestID = v
+			break
+		}
+	}
+	metadata.Set(responseSummaryKey{}, summary)
+
+	return out, metadata, err
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestOperationSummaryLogger(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+
+	cases := map[string]struct {
+		StatusCode           int
+		Err                  error
+		ExpectClassification logging.Classification
+		ExpectMessage        string
+	}{
+		"success": {
+			StatusCode:           200,
+			ExpectClassification: logging.Info,
+			ExpectMessage: "operation summary operation=GetItem service=Example outcome=success" +
+				" status=200 attempts=2 latency=250ms bytes=42 request_id=req-123",
+		},
+		"failure": {
+			StatusCode:           500,
+			Err:                  fmt.Errorf("internal error"),
+			ExpectClassification: logging.Warn,
+			ExpectMessage: "operation summary operation=GetItem service=Example outcome=failure" +
+				" status=500 attempts=2 latency=250ms bytes=42 request_id=req-123" +
+				` error="internal error"`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+
+			stack := middleware.NewStack("stack", NewStackRequest)
+			err := AddOperationSummaryLoggerMiddleware(stack, &OperationSummaryLogger{
+				ServiceID:     "Example",
+				OperationName: "GetItem",
+				GetAttemptCount: func(metadata middleware.MetadataReader) (int, bool) {
+					v, ok := metadata.Get("attempts").(int)
+					return v, ok
+				},
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				now = now.Add(250 * time.Millisecond)
+				var metadata middleware.Metadata
+				metadata.Set("attempts", 2)
+				return &Response{
+					Response: &http.Response{
+						StatusCode:    c.StatusCode,
+						ContentLength: 42,
+						Header:        http.Header{"X-Amzn-Requestid": []string{"req-123"}},
+					},
+				}, metadata, c.Err
+			})
+
+			var classifications []logging.Classification
+			var messages []string
+			ctx := middleware.SetLogger(context.Background(), logging.LoggerFunc(
+				func(classification logging.Classification, format string, v ...interface{}) {
+					classifications = append(classifications, classification)
+					messages = append(messages, fmt.Sprintf(format, v...))
+				}))
+
+			middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+
+			if e, a := 1, len(messages); e != a {
+				t.Fatalf("expect %v log entries, got %v", e, a)
+			}
+			if e, a := c.ExpectClassification, classifications[0]; e != a {
+				t.Errorf("expect %v classification, got %v", e, a)
+			}
+			if e, a := c.ExpectMessage, messages[0]; e != a {
+				t.Errorf("expect message\n%v\ngot\n%v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// OperationTimeoutOptions provides the options for the operation timeout
+// middleware.
+type OperationTimeoutOptions struct {
+	// Sets if the operation timeout also bounds reading the body of the
+	// response after the operation returns, (e.g. streaming output members).
+	// If set, the operation's context is not canceled until the response body
+	// is closed, and reading the body after the deadline fails.
+	//
+	// If not set, the operation's context is canceled when the operation
+	// returns.
+	BoundBodyRead bool
+}
+
+// AddOperationTimeoutMiddleware adds the middleware bounding the total time
+// an operation may take to the stack. The middleware is added to the front of
+// the Initialize step, so that the timeout includes all other middleware.
+//
+// Use middleware#Timeout to bound individual steps of the stack, (e.g. each
+// attempt of the operation).
+func AddOperationTimeoutMiddleware(
+	stack *middleware.Stack, timeout time.Duration, optFns ...func(*OperationTimeoutOptions),
+) error {
+	var options OperationTimeoutOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	if err := stack.Initialize.Add(&operationTimeout{
+		timeout: middleware.NewTimeout(timeout),
+		enabled: timeout > 0,
+	}, middleware.Before); err != nil {
+		return fmt.Errorf("failed to add %s initialize middleware, %w",
+			(*operationTimeout)(nil).ID(), err)
+	}
+
+	if options.BoundBodyRead {
+		return AddBoundResponseBodyReadMiddleware(stack)
+	}
+
+	return nil
+}
+
+// AddBoundResponseBodyReadMiddleware adds the middleware deferring the
+// cancellation of the context of the middleware#Timeout middleware, (and the
+// operation timeout), until the response body is closed. Reading the body
+// fails once the timeout elapses. The middleware is added to the end of the
+// Deserialize step.
+func AddBoundResponseBodyReadMiddleware(stack *middleware.Stack) error {
+	if err := stack.Deserialize.Add(&boundResponseBodyRead{}, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s deserialize middleware, %w",
+			(*boundResponseBodyRead)(nil).ID(), err)
+	}
+	return nil
+}
+
+type operationTimeout struct {
+	timeout *middleware.Timeout
+	enabled bool
+}
+
+// ID returns the middleware identifier.
+func (*operationTimeout) ID() string { return "OperationTimeout" }
+
+// HandleInitialize invokes the next handler with a context bounded by the
+// operation timeout.
+func (m *operationTimeout) HandleInitialize(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	if !m.enabled {
+		return next.HandleInitialize(ctx, in)
+	}
+	return m.timeout.HandleInitialize(ctx, in, next)
+}
+
+type boundResponseBodyRead struct{}
+
+// ID returns the middleware identifier.
+func (*boundResponseBodyRead) ID() string { return "OperationTimeoutBoundBodyRead" }
+
+// HandleDeserialize wraps the raw response's body so that reading the body
+// fails once the timeout elapses, and closing the body releases the timeout's
+// context.
+func (m *boundResponseBodyRead) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil || resp.Body == nil {
+		return out, metadata, err
+	}
+
+	tctx, release, ok := middleware.DeferTimeoutCancel(ctx)
+	if !ok {
+		return out, metadata, err
+	}
+
+	resp.Body = newDeadlineReadCloser(tctx, release, resp.Body)
+
+	return out, metadata, err
+}
+
+// deadlineReadCloser fails reads once its context is done. The underlying
+// reader is closed when the context is done, to unblock any in progress read.
+type deadlineReadCloser struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	body   io.ReadCloser
+
+	closeOnce sync.Once
+	closeErr  error
+	closed    chan struct{}
+}
+
+func newDeadlineReadCloser(ctx context.Context, cancel context.CancelFunc, body io.ReadCloser) *deadlineReadCloser {
+	r := &deadlineReadCloser{
+		ctx:    ctx,
+		cancel: cancel,
+		body:   body,
+		closed: make(chan struct{}),
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			r.closeBody()
+		case <-r.closed:
+		}
+	}()
+
+	return r
+}
+
+func (r *deadlineReadCloser) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, &smithy.CanceledError{Err: err}
+	}
+
+	n, err := r.body.Read(p)
+	if err != nil && err != io.EOF {
+		if ctxErr := r.ctx.Err(); ctxErr != nil {
+			return n, &smithy.CanceledError{Err: ctxErr}
+		}
+	}
+	return n, err
+}
+
+// Close closes the underlying reader, and releases the timeout's context.
+func (r *deadlineReadCloser) Close() error {
+	err := r.closeBody()
+	r.cancel()
+	return err
+}
+
+func (r *deadlineReadCloser) closeBody() error {
+	r.closeOnce.Do(func() {
+		r.closeErr = r.body.Close()
+		close(r.closed)
+	})
+	return r.closeErr
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestOperationTimeout_BoundBodyRead(t *testing.T) {
+	cases := map[string]struct {
+		Body      func() io.ReadCloser
+		Expect    string
+		ExpectErr bool
+	}{
+		"body read within deadline": {
+			Body: func() io.ReadCloser {
+				return ioutil.NopCloser(strings.NewReader("hello"))
+			},
+			Expect: "hello",
+		},
+		"slow body read aborted": {
+			Body: func() io.ReadCloser {
+				// Reads block until the pipe is closed, as nothing is ever
+				// written.
+				r, _ := io.Pipe()
+				return r
+			},
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer",
+				func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out, metadata, err = next.HandleDeserialize(ctx, in)
+					if err != nil {
+						return out, metadata, err
+					}
+					out.Result = out.RawResponse.(*Response).Body
+					return out, metadata, nil
+				}), middleware.After)
+
+			err := AddOperationTimeoutMiddleware(stack, 50*time.Millisecond,
+				func(o *OperationTimeoutOptions) {
+					o.BoundBodyRead = true
+				})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var opCtx context.Context
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				opCtx = ctx
+				return &Response{
+					Response: &http.Response{StatusCode: 200, Body: c.Body()},
+				}, middleware.Metadata{}, nil
+			})
+
+			result, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := opCtx.Err(); err != nil {
+				t.Fatalf("expect operation context not canceled before body read, got %v", err)
+			}
+
+			body := result.(io.ReadCloser)
+			b, err := ioutil.ReadAll(body)
+			if c.ExpectErr {
+				var canceled *smithy.CanceledError
+				if !errors.As(err, &canceled) {
+					t.Fatalf("expect %T error, got %v", canceled, err)
+				}
+				if e, a := context.DeadlineExceeded, canceled.Err; e != a {
+					t.Errorf("expect %v error, got %v", e, a)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if e, a := c.Expect, string(b); e != a {
+					t.Errorf("expect %v body, got %v", e, a)
+				}
+			}
+
+			body.Close()
+			if opCtx.Err() == nil {
+				t.Errorf("expect operation context canceled after body closed")
+			}
+		})
+	}
+}
+
+func TestBoundResponseBodyRead_Timeout(t *testing.T) {
+	stack := middleware.NewStack("stack", NewStackRequest)
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			out, metadata, err = next.HandleDeserialize(ctx, in)
+			if err != nil {
+				return out, metadata, err
+			}
+			out.Result = out.RawResponse.(*Response).Body
+			return out, metadata, nil
+		}), middleware.After)
+
+	if err := stack.Finalize.Add(middleware.NewTimeout(time.Minute), middleware.Before); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := AddBoundResponseBodyReadMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var opCtx context.Context
+	handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		opCtx = ctx
+		return &Response{
+			Response: &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader("hello")),
+			},
+		}, middleware.Metadata{}, nil
+	})
+
+	result, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := opCtx.Err(); err != nil {
+		t.Fatalf("expect timeout context not canceled before body read, got %v", err)
+	}
+
+	body := result.(io.ReadCloser)
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "hello", string(b); e != a {
+		t.Errorf("expect %v body, got %v", e, a)
+	}
+
+	body.Close()
+	if opCtx.Err() == nil {
+		t.Errorf("expect timeout context canceled after body closed")
+	}
+}
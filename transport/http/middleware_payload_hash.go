@@ -0,0 +1,107 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	smithyio "github.com/aws/smithy-go/io"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// UnsignedPayload is the payload hash used for requests whose payload cannot
+// be hashed prior to being sent, (e.g. a stream that cannot be rewound).
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// AddComputePayloadHashMiddleware adds the middleware computing the hex
+// encoded SHA-256 hash of the request's payload, to the end of the stack's
+// Build step. The hash is stored with SetPayloadHash, for signers to share,
+// see AddSigningMiddleware.
+//
+// The request's stream is rewound after it is hashed, see
+// Request.RewindStream. Streams that cannot be rewound are not read, and use
+// the UnsignedPayload hash instead. Streams set with
+// SetStreamWithRewindBuffer are only hashed if they are no longer than the
+// rewind buffer. Otherwise, the stream is rewound, and uses the
+// UnsignedPayload hash. The hash is not computed if a payload hash was
+// already set.
+func AddComputePayloadHashMiddleware(stack *middleware.Stack) error {
+	return stack.Build.Add(&computePayloadHash{}, middleware.After)
+}
+
+type computePayloadHash struct{}
+
+// ID returns the middleware identifier.
+func (*computePayloadHash) ID() string { return "ComputePayloadHash" }
+
+// HandleBuild computes the hash of the request's payload.
+func (m *computePayloadHash) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	if len(GetPayloadHash(ctx)) != 0 {
+		return next.HandleBuild(ctx, in)
+	}
+
+	stream := req.GetStream()
+	if stream != nil && !req.IsStreamRewindable() {
+		return next.HandleBuild(SetPayloadHash(ctx, UnsignedPayload), in)
+	}
+
+	hash := sha256.New()
+	complete := true
+	if stream != nil {
+		var err error
+		if req, complete, err = hashStream(hash, req); err != nil {
+			return out, metadata, fmt.Errorf("failed to compute payload hash, %w", err)
+		}
+		in.Request = req
+		if err := req.RewindStream(); err != nil {
+			return out, metadata, fmt.Errorf(
+				"failed to rewind request stream after computing payload hash, %w", err)
+		}
+	}
+
+	payloadHash := UnsignedPayload
+	if complete {
+		payloadHash = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return next.HandleBuild(SetPayloadHash(ctx, payloadHash), in)
+}
+
+// hashStream writes the request's stream to the hash, returning if the whole
+// stream was hashed. Streams with a rewind buffer are only read one byte past
+// the buffer's size, to find if the stream ends at the buffer's size. If the
+// stream is longer, the request is returned with the bytes read restored
+// ahead of the rest of the stream, so that the stream can still be rewound.
+func hashStream(hash io.Writer, req *Request) (*Request, bool, error) {
+	stream := req.GetStream()
+	rb, ok := stream.(*smithyio.RewindBuffer)
+	if !ok {
+		_, err := io.Copy(hash, stream)
+		return req, err == nil, err
+	}
+
+	var read bytes.Buffer
+	_, err := io.CopyN(io.MultiWriter(hash, &read), rb, int64(rb.Size())+1)
+	if err == io.EOF {
+		return req, true, nil
+	} else if err != nil {
+		return req, false, err
+	}
+
+	// Reading past the buffer's size prevents rewinding the buffer, so the
+	// stream is replaced with a new rewind buffer of the same size.
+	req, err = req.SetStreamWithRewindBuffer(io.MultiReader(&read, rb), rb.Size())
+	return req, false, err
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestComputePayloadHashMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Stream      func() io.Reader
+		SetStream   func(*Request) (*Request, error)
+		PayloadHash string
+		ExpectHash  string
+		ExpectBody  string
+	}{
+		"seekable stream": {
+			Stream: func() io.Reader {
+				return bytes.NewReader([]byte("hello world"))
+			},
+			ExpectHash: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+			ExpectBody: "hello world",
+		},
+		"no stream": {
+			ExpectHash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		"unseekable stream": {
+			Stream: func() io.Reader {
+				return ioutil.NopCloser(strings.NewReader("hello world"))
+			},
+			ExpectHash: UnsignedPayload,
+			ExpectBody: "hello world",
+		},
+		"stream factory": {
+			SetStream: func(r *Request) (*Request, error) {
+				return r.SetStreamFactory(func() (io.Reader, error) {
+					return ioutil.NopCloser(strings.NewReader("hello world")), nil
+				})
+			},
+			ExpectHash: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+			ExpectBody: "hello world",
+		},
+		"rewind buffer stream": {
+			SetStream: func(r *Request) (*Request, error) {
+				return r.SetStreamWithRewindBuffer(ioutil.NopCloser(strings.NewReader("hello world")), 64)
+			},
+			ExpectHash: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+			ExpectBody: "hello world",
+		},
+		"rewind buffer stream at buffer size": {
+			SetStream: func(r *Request) (*Request, error) {
+				return r.SetStreamWithRewindBuffer(ioutil.NopCloser(strings.NewReader("hello world")), 11)
+			},
+			ExpectHash: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+			ExpectBody: "hello world",
+		},
+		"rewind buffer stream exceeds buffer": {
+			SetStream: func(r *Request) (*Request, error) {
+				return r.SetStreamWithRewindBuffer(ioutil.NopCloser(strings.NewReader("hello world")), 5)
+			},
+			ExpectHash: UnsignedPayload,
+			ExpectBody: "hello world",
+		},
+		"payload hash already set": {
+			Stream: func() io.Reader {
+				return bytes.NewReader([]byte("hello world"))
+			},
+			PayloadHash: "abc123",
+			ExpectHash:  "abc123",
+			ExpectBody:  "hello world",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					middleware.SerializeOutput, middleware.Metadata, error,
+				) {
+					var req *Request
+					var err error
+					switch {
+					case c.SetStream != nil:
+						req, err = c.SetStream(in.Request.(*Request))
+					case c.Stream != nil:
+						req, err = in.Request.(*Request).SetStream(c.Stream())
+					default:
+						return next.HandleSerialize(ctx, in)
+					}
+					if err != nil {
+						return middleware.SerializeOutput{}, middleware.Metadata{}, err
+					}
+					in.Request = req
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+
+			if err := AddComputePayloadHashMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var payloadHash string
+			stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("capturePayloadHash",
+				func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+					middleware.FinalizeOutput, middleware.Metadata, error,
+				) {
+					payloadHash = GetPayloadHash(ctx)
+					return next.HandleFinalize(ctx, in)
+				}), middleware.After)
+
+			var body []byte
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				req := input.(*Request).Build(ctx)
+				if req.Body != nil {
+					var err error
+					if body, err = ioutil.ReadAll(req.Body); err != nil {
+						t.Fatalf("expect no error, got %v", err)
+					}
+				}
+				return &Response{Response: &http.Response{StatusCode: 200}}, middleware.Metadata{}, nil
+			})
+
+			ctx := context.Background()
+			if len(c.PayloadHash) != 0 {
+				ctx = SetPayloadHash(ctx, c.PayloadHash)
+			}
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectHash, payloadHash; e != a {
+				t.Errorf("expect %v payload hash, got %v", e, a)
+			}
+			if e, a := c.ExpectBody, string(body); e != a {
+				t.Errorf("expect %q body, got %q", e, a)
+			}
+		})
+	}
+}
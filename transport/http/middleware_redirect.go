@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultMaxRedirects is the default maximum number of redirects followed for
+// an operation.
+const DefaultMaxRedirects = 10
+
+// RedirectOptions provides the options for the redirect middleware.
+type RedirectOptions struct {
+	// The maximum number of redirects followed for an operation. Defaults to
+	// DefaultMaxRedirects.
+	MaxRedirects int
+}
+
+// TooManyRedirectsError is the error returned when an operation is
+// redirected more than the maximum number of redirects.
+type TooManyRedirectsError struct {
+	MaxRedirects int
+}
+
+// RetryableError returns that the error is not retryable.
+func (*TooManyRedirectsError) RetryableError() bool { return false }
+
+func (e *TooManyRedirectsError) Error() string {
+	return fmt.Sprintf("stopped after %d redirects", e.MaxRedirects)
+}
+
+// redirectResponseError is the error returned by the stack's Deserialize step
+// for redirect responses, so that the response is not deserialized, and is
+// followed by the redirect middleware.
+type redirectResponseError struct {
+	Response *Response
+	Location *url.URL
+}
+
+func (e *redirectResponseError) Error() string {
+	return fmt.Sprintf("redirected with %d to %s", e.Response.StatusCode, e.Location)
+}
+
+// AddRedirectMiddleware adds the middleware following redirect responses of
+// an operation to the stack. The redirect loop is added to the front of the
+// Finalize step, so that the middleware after it, (e.g. retry and request
+// signing), are invoked again for each redirect target. Redirect responses
+// are detected at the end of the Deserialize step, before they are
+// deserialized.
+//
+// Redirects with 301, 302, 307, or 308 status codes are followed with the
+// same method and body, rewinding the body. Redirects with a 303 status code
+// are followed with a GET request without a body. The stack's client must not
+// follow redirects itself, see ClientHandlerOptions.DisableRedirects.
+func AddRedirectMiddleware(stack *middleware.Stack, optFns ...func(*RedirectOptions)) error {
+	options := RedirectOptions{
+		MaxRedirects: DefaultMaxRedirects,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	if err := stack.Finalize.Add(&followRedirects{options: options}, middleware.Before); err != nil {
+		return fmt.Errorf("failed to add %s finalize middleware, %w",
+			(*followRedirects)(nil).ID(), err)
+	}
+	if err := stack.Deserialize.Add(&detectRedirect{}, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s deserialize middleware, %w",
+			(*detectRedirect)(nil).ID(), err)
+	}
+	return nil
+}
+
+type redirectCountKey struct{}
+
+// GetRedirectCount returns the number of redirects followed for the
+// operation, and if the value was set.
+func GetRedirectCount(metadata middleware.MetadataReader) (int, bool) {
+	v, ok := metadata.Get(redirectCountKey{}).(int)
+	return v, ok
+}
+
+type followRedirects struct {
+	options RedirectOptions
+}
+
+// ID returns the middleware identifier.
+func (*followRedirects) ID() string { return "FollowRedirects" }
+
+// HandleFinalize invokes the next handler, and again for each redirect
+// target, until the response is not a redirect.
+func (m *followRedirects) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	var redirects int
+	for {
+		out, metadata, err = next.HandleFinalize(ctx, in)
+
+		var redirectErr *redirectResponseError
+		if err == nil || !errors.As(err, &redirectErr) {
+			break
+		}
+		if body := redirectErr.Response.Body; body != nil {
+			body.Close()
+		}
+
+		if redirects >= m.options.MaxRedirects {
+			err = &TooManyRedirectsError{MaxRedirects: m.options.MaxRedirects}
+			break
+		}
+		redirects++
+
+		req, ok := in.Request.(*Request)
+		if !ok {
+			return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+		}
+
+		if in.Request, err = redirectRequest(req, redirectErr); err != nil {
+			break
+		}
+	}
+
+	if redirects > 0 {
+		metadata.Set(redirectCountKey{}, redirects)
+	}
+
+	return out, metadata, err
+}
+
+// redirectRequest returns a copy of the request for the redirect's target.
+func redirectRequest(req *Request, redirect *redirectResponseError) (*Request, error) {
+	rc := req.Clone()
+	rc.URL = req.URL.ResolveReference(redirect.Location)
+	rc.Host = ""
+
+	if redirect.Response.StatusCode == http.StatusSeeOther {
+		rc.Method = http.MethodGet
+		rc.Header.Del("Content-Length")
+		rc.Header.Del("Content-Type")
+		rc.ContentLength = 0
+
+		var err error
+		if rc, err = rc.SetStream(nil); err != nil {
+			return nil, fmt.Errorf("failed to clear request stream for redirect, %w", err)
+		}
+		return rc, nil
+	}
+
+	if err := rc.RewindStream(); err != nil {
+		return nil, fmt.Errorf("failed to rewind request stream for redirect, %w", err)
+	}
+
+	return rc, nil
+}
+
+type detectRedirect struct{}
+
+// ID returns the middleware identifier.
+func (*detectRedirect) ID() string { return "DetectRedirect" }
+
+// HandleDeserialize returns redirect responses with a Location header as an
+// error, so that they are followed instead of deserialized.
+func (*detectRedirect) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil {
+		return out, metadata, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return out, metadata, err
+	}
+
+	location := resp.Header.Get("Location")
+	if len(location) == 0 {
+		return out, metadata, err
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return out, metadata, &ResponseError{
+			Response: resp,
+			Err:      fmt.Errorf("invalid redirect location %q, %w", location, err),
+		}
+	}
+
+	return out, metadata, &redirectResponseError{Response: resp, Location: u}
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+type redirectTestRequest struct {
+	Method        string
+	URL           string
+	Authorization string
+	Body          string
+}
+
+func TestRedirectMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Method         string
+		Responses      map[string]*http.Response
+		MaxRedirects   int
+		ExpectRequests []redirectTestRequest
+		ExpectCount    int
+		ExpectErr      bool
+	}{
+		"redirect to new host": {
+			Method: "PUT",
+			Responses: map[string]*http.Response{
+				"a.example.com": {
+					StatusCode: 307,
+					Header:     http.Header{"Location": []string{"https://b.example.com/bucket/key"}},
+				},
+			},
+			ExpectRequests: []redirectTestRequest{
+				{Method: "PUT", URL: "https://a.example.com/bucket/key", Authorization: "sig a.example.com", Body: "payload"},
+				{Method: "PUT", URL: "https://b.example.com/bucket/key", Authorization: "sig b.example.com", Body: "payload"},
+			},
+			ExpectCount: 1,
+		},
+		"see other": {
+			Method: "POST",
+			Responses: map[string]*http.Response{
+				"a.example.com": {
+					StatusCode: 303,
+					Header:     http.Header{"Location": []string{"/result"}},
+				},
+			},
+			ExpectRequests: []redirectTestRequest{
+				{Method: "POST", URL: "https://a.example.com/bucket/key", Authorization: "sig a.example.com", Body: "payload"},
+				{Method: "GET", URL: "https://a.example.com/result", Authorization: "sig a.example.com"},
+			},
+			ExpectCount: 1,
+		},
+		"no redirect": {
+			Method: "PUT",
+			ExpectRequests: []redirectTestRequest{
+				{Method: "PUT", URL: "https://a.example.com/bucket/key", Authorization: "sig a.example.com", Body: "payload"},
+			},
+		},
+		"too many redirects": {
+			Method:       "PUT",
+			MaxRedirects: 1,
+			Responses: map[string]*http.Response{
+				"a.example.com": {
+					StatusCode: 301,
+					Header:     http.Header{"Location": []string{"https://b.example.com/bucket/key"}},
+				},
+				"b.example.com": {
+					StatusCode: 301,
+					Header:     http.Header{"Location": []string{"https://a.example.com/bucket/key"}},
+				},
+			},
+			ExpectRequests: []redirectTestRequest{
+				{Method: "PUT", URL: "https://a.example.com/bucket/key", Authorization: "sig a.example.com", Body: "payload"},
+				{Method: "PUT", URL: "https://b.example.com/bucket/key", Authorization: "sig b.example.com", Body: "payload"},
+			},
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("OperationSerializer", func(
+				ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+			) (
+				middleware.SerializeOutput, middleware.Metadata, error,
+			) {
+				req := in.Request.(*Request)
+				req.Method = c.Method
+				req.URL.Scheme = "https"
+				req.URL.Host = "a.example.com"
+				req.URL.Path = "/bucket/key"
+
+				var err error
+				if in.Request, err = req.SetStream(strings.NewReader("payload")); err != nil {
+					return middleware.SerializeOutput{}, middleware.Metadata{}, err
+				}
+				return next.HandleSerialize(ctx, in)
+			}), middleware.After)
+
+			// Mock signer, signing the request for its host.
+			stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Signing", func(
+				ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+			) (
+				middleware.FinalizeOutput, middleware.Metadata, error,
+			) {
+				req := in.Request.(*Request).Clone()
+				req.Header.Set("Authorization", "sig "+req.URL.Host)
+				in.Request = req
+				return next.HandleFinalize(ctx, in)
+			}), middleware.After)
+
+			err := AddRedirectMiddleware(stack, func(o *RedirectOptions) {
+				if c.MaxRedirects != 0 {
+					o.MaxRedirects = c.MaxRedirects
+				}
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var requests []redirectTestRequest
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				req := input.(*Request)
+				var body string
+				if stream := req.GetStream(); stream != nil {
+					b, _ := ioutil.ReadAll(stream)
+					body = string(b)
+				}
+				requests = append(requests, redirectTestRequest{
+					Method:        req.Method,
+					URL:           req.URL.String(),
+					Authorization: req.Header.Get("Authorization"),
+					Body:          body,
+				})
+
+				resp, ok := c.Responses[req.URL.Host]
+				if !ok || req.Method == "GET" {
+					resp = &http.Response{StatusCode: 200, Header: http.Header{}}
+				}
+				return &Response{Response: resp}, middleware.Metadata{}, nil
+			})
+
+			_, metadata, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if c.ExpectErr {
+				var redirectsErr *TooManyRedirectsError
+				if !errors.As(err, &redirectsErr) {
+					t.Fatalf("expect %T error, got %v", redirectsErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.ExpectRequests, requests); len(diff) != 0 {
+				t.Errorf("expect requests to match\n%s", diff)
+			}
+
+			count, _ := GetRedirectCount(metadata)
+			if e, a := c.ExpectCount, count; !c.ExpectErr && e != a {
+				t.Errorf("expect %v redirects, got %v", e, a)
+			}
+		})
+	}
+}
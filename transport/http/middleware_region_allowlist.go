@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type signingRegionKey struct{}
+
+// SetSigningRegion returns a context with the region the request is sent to,
+// and signed for. Set by the operation's endpoint resolution.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetSigningRegion(ctx context.Context, region string) context.Context {
+	return middleware.WithStackValue(ctx, signingRegionKey{}, region)
+}
+
+// GetSigningRegion returns the region the request is sent to, and signed for,
+// and if the region was set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetSigningRegion(ctx context.Context) (string, bool) {
+	v, ok := middleware.GetStackValue(ctx, signingRegionKey{}).(string)
+	return v, ok && len(v) != 0
+}
+
+// RegionNotAllowedError is the error returned when the region a request would
+// be sent to is not one of the allowed regions.
+type RegionNotAllowedError struct {
+	Region  string
+	Allowed []string
+}
+
+func (e *RegionNotAllowedError) Error() string {
+	region := e.Region
+	if len(region) == 0 {
+		region = "<unresolved>"
+	}
+	return fmt.Sprintf("region %s not allowed, expect one of [%s]",
+		region, strings.Join(e.Allowed, ", "))
+}
+
+// AddRegionAllowlistMiddleware adds the middleware rejecting requests whose
+// signing region, see SetSigningRegion, is not one of the allowed regions, to
+// the front of the stack's Finalize step. The region is checked after the
+// endpoint is resolved, and before the request is retried, signed, or sent.
+//
+// Requests without a resolved region are rejected, so that a request is never
+// sent to an unverified region. Regions are compared case sensitively.
+func AddRegionAllowlistMiddleware(stack *middleware.Stack, allowed []string) error {
+	m := &regionAllowlist{
+		allowed: make(map[string]struct{}, len(allowed)),
+		list:    append([]string(nil), allowed...),
+	}
+	for _, region := range allowed {
+		m.allowed[region] = struct{}{}
+	}
+
+	return stack.Finalize.Add(m, middleware.Before)
+}
+
+type regionAllowlist struct {
+	allowed map[string]struct{}
+	list    []string
+}
+
+// ID returns the middleware identifier.
+func (*regionAllowlist) ID() string { return "RegionAllowlist" }
+
+// HandleFinalize returns a RegionNotAllowedError if the request's signing
+// region is not allowed, without invoking the next handler.
+func (m *regionAllowlist) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	region, _ := GetSigningRegion(ctx)
+	if _, ok := m.allowed[region]; !ok || len(region) == 0 {
+		return out, metadata, &RegionNotAllowedError{
+			Region:  region,
+			Allowed: m.list,
+		}
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
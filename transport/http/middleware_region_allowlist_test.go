@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRegionAllowlistMiddleware(t *testing.T) {
+	allowed := []string{"eu-west-1", "eu-central-1"}
+
+	cases := map[string]struct {
+		Region    string
+		ExpectErr string
+	}{
+		"allowed region": {
+			Region: "eu-central-1",
+		},
+		"disallowed region": {
+			Region:    "us-east-1",
+			ExpectErr: "region us-east-1 not allowed, expect one of [eu-west-1, eu-central-1]",
+		},
+		"unresolved region": {
+			ExpectErr: "region <unresolved> not allowed, expect one of [eu-west-1, eu-central-1]",
+		},
+		"case sensitive": {
+			Region:    "EU-WEST-1",
+			ExpectErr: "region EU-WEST-1 not allowed, expect one of [eu-west-1, eu-central-1]",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			if err := AddRegionAllowlistMiddleware(stack, allowed); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			// Simulates endpoint resolution setting the signing region.
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("resolveEndpoint",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					middleware.SerializeOutput, middleware.Metadata, error,
+				) {
+					if len(c.Region) != 0 {
+						ctx = SetSigningRegion(ctx, c.Region)
+					}
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+
+			var sent bool
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				sent = true
+				return nil, middleware.Metadata{}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				var regionErr *RegionNotAllowedError
+				if !errors.As(err, &regionErr) {
+					t.Errorf("expect %T error, got %v", regionErr, err)
+				}
+				if e, a := c.ExpectErr, err.Error(); e != a {
+					t.Errorf("expect %q error, got %q", e, a)
+				}
+				if sent {
+					t.Errorf("expect request not sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !sent {
+				t.Errorf("expect request sent")
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// RequestFingerprintOptions provides the options for the request fingerprint
+// middleware.
+type RequestFingerprintOptions struct {
+	// The request headers included in the fingerprint, (e.g. the header an
+	// idempotency token is bound to).
+	Headers []string
+}
+
+// AddRequestFingerprintMiddleware adds the middleware to ensure the request of
+// each attempt of an operation has the same fingerprint, (method, path, query,
+// and fingerprinted headers), so that the service can deduplicate the
+// attempts. The fingerprint of the first attempt is stored, and subsequent
+// attempts fail with a FingerprintDriftError if their fingerprint differs.
+//
+// The middleware that stores the fingerprint state is added to the front of
+// the Initialize step, and the middleware that compares the fingerprints to
+// the end of the Finalize step. The retry middleware must be added to the
+// Finalize step before the fingerprint middleware.
+func AddRequestFingerprintMiddleware(stack *middleware.Stack, optFns ...func(*RequestFingerprintOptions)) error {
+	var options RequestFingerprintOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	if err := stack.Initialize.Add(&requestFingerprintState{}, middleware.Before); err != nil {
+		return fmt.Errorf("failed to add %s initialize middleware, %w",
+			(*requestFingerprintState)(nil).ID(), err)
+	}
+	if err := stack.Finalize.Add(&requestFingerprint{options: options}, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s finalize middleware, %w",
+			(*requestFingerprint)(nil).ID(), err)
+	}
+	return nil
+}
+
+// FingerprintDriftError is returned when the request of an attempt has a
+// different fingerprint than the operation's first attempt. Indicates a
+// middleware modified the request in a way that is not consistent across
+// attempts.
+type FingerprintDriftError struct {
+	Expect string
+	Actual string
+}
+
+// RetryableError returns that the error is not retryable, as subsequent
+// attempts would also differ from the first attempt.
+func (*FingerprintDriftError) RetryableError() bool { return false }
+
+func (e *FingerprintDriftError) Error() string {
+	return fmt.Sprintf("request fingerprint drifted between attempts, expect %q, got %q",
+		e.Expect, e.Actual)
+}
+
+type fingerprintStateKey struct{}
+
+type fingerprintState struct {
+	mu          sync.Mutex
+	fingerprint string
+	set         bool
+}
+
+type requestFingerprintState struct{}
+
+// ID returns the middleware identifier.
+func (*requestFingerprintState) ID() string { return "RequestFingerprintState" }
+
+// HandleInitialize stores the operation's fingerprint state in the context.
+func (*requestFingerprintState) HandleInitialize(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	ctx = middleware.WithStackValue(ctx, fingerprintStateKey{}, &fingerprintState{})
+	return next.HandleInitialize(ctx, in)
+}
+
+type requestFingerprint struct {
+	options RequestFingerprintOptions
+}
+
+// ID returns the middleware identifier.
+func (*requestFingerprint) ID() string { return "RequestFingerprint" }
+
+// HandleFinalize computes the fingerprint of the attempt's request, storing
+// it for the first attempt, and comparing it to the first attempt's for
+// subsequent attempts.
+func (m *requestFingerprint) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	state, ok := middleware.GetStackValue(ctx, fingerprintStateKey{}).(*fingerprintState)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	fingerprint := m.fingerprint(req)
+
+	state.mu.Lock()
+	if !state.set {
+		state.fingerprint = fingerprint
+		state.set = true
+	}
+	expect := state.fingerprint
+	state.mu.Unlock()
+
+	if fingerprint != expect {
+		return out, metadata, &FingerprintDriftError{Expect: expect, Actual: fingerprint}
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
+
+func (m *requestFingerprint) fingerprint(req *Request) string {
+	var sb strings.Builder
+	sb.WriteString(req.Method)
+	sb.WriteString(" ")
+	sb.WriteString(req.URL.EscapedPath())
+	if len(req.URL.RawQuery) != 0 {
+		sb.WriteString("?")
+		sb.WriteString(req.URL.RawQuery)
+	}
+	for _, h := range m.options.Headers {
+		sb.WriteString(" ")
+		sb.WriteString(strings.ToLower(h))
+		sb.WriteString(":")
+		sb.WriteString(strings.Join(req.Header.Values(h), ","))
+	}
+	return sb.String()
+}
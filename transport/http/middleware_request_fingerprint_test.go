@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRequestFingerprintMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Mutate      func(attempt int, req *Request)
+		ExpectDrift bool
+	}{
+		"consistent": {
+			Mutate: func(attempt int, req *Request) {
+				// Headers not fingerprinted may differ between attempts.
+				req.Header.Set("X-Amz-Date", fmt.Sprintf("attempt-%d", attempt))
+			},
+		},
+		"path drift": {
+			Mutate: func(attempt int, req *Request) {
+				if attempt > 1 {
+					req.URL.Path += "/again"
+				}
+			},
+			ExpectDrift: true,
+		},
+		"token drift": {
+			Mutate: func(attempt int, req *Request) {
+				req.Header.Set("X-Client-Token", fmt.Sprintf("token-%d", attempt))
+			},
+			ExpectDrift: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					middleware.SerializeOutput, middleware.Metadata, error,
+				) {
+					req := in.Request.(*Request)
+					req.Method = "PUT"
+					req.URL.Path = "/resource"
+					req.Header.Set("X-Client-Token", "token-1")
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+
+			// Mock retry middleware invoking the rest of the stack for two
+			// attempts.
+			var attempt int
+			stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Retry",
+				func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					for attempt = 1; attempt <= 2; attempt++ {
+						attemptIn := in
+						attemptIn.Request = in.Request.(*Request).Clone()
+						if out, metadata, err = next.HandleFinalize(ctx, attemptIn); err != nil {
+							return out, metadata, err
+						}
+					}
+					return out, metadata, err
+				}), middleware.After)
+			stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("mutate",
+				func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+					middleware.FinalizeOutput, middleware.Metadata, error,
+				) {
+					c.Mutate(attempt, in.Request.(*Request))
+					return next.HandleFinalize(ctx, in)
+				}), middleware.After)
+
+			err := AddRequestFingerprintMiddleware(stack, func(o *RequestFingerprintOptions) {
+				o.Headers = []string{"X-Client-Token"}
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var sent int
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				sent++
+				return &Response{}, middleware.Metadata{}, nil
+			})
+
+			_, _, err = middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if c.ExpectDrift {
+				var driftErr *FingerprintDriftError
+				if !errors.As(err, &driftErr) {
+					t.Fatalf("expect %T error, got %v", driftErr, err)
+				}
+				if e, a := 1, sent; e != a {
+					t.Errorf("expect %v requests sent, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := 2, sent; e != a {
+				t.Errorf("expect %v requests sent, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultSensitiveHeaders is the default set of request headers removed from
+// the sanitized request passed to request taps.
+var DefaultSensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+}
+
+// RequestTap provides the interface for read-only observers of the request
+// sent by an operation, (e.g. debug logging, metrics).
+type RequestTap interface {
+	TapRequest(ctx context.Context, req *Request)
+}
+
+// RequestTapFunc provides a wrapper around a function to be used as a
+// RequestTap.
+type RequestTapFunc func(ctx context.Context, req *Request)
+
+// TapRequest invokes the wrapped function.
+func (fn RequestTapFunc) TapRequest(ctx context.Context, req *Request) {
+	fn(ctx, req)
+}
+
+// RequestTapOptions provides the options for the request tap middleware.
+type RequestTapOptions struct {
+	// The request headers removed from the sanitized request passed to the
+	// tap. Defaults to DefaultSensitiveHeaders.
+	SensitiveHeaders []string
+}
+
+// AddRequestTapMiddleware adds the middleware to pass a sanitized view of the
+// operation's request to the tap. The middleware is added to the end of the
+// Finalize step so that the tap observes the request after it was signed.
+//
+// The tap is passed a clone of the request with the sensitive headers removed
+// and without the request's stream. The request sent to the transport is not
+// modified.
+func AddRequestTapMiddleware(stack *middleware.Stack, tap RequestTap, optFns ...func(*RequestTapOptions)) error {
+	options := RequestTapOptions{
+		SensitiveHeaders: DefaultSensitiveHeaders,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Finalize.Add(&requestTap{tap: tap, options: options}, middleware.After)
+}
+
+// SanitizeRequest returns a clone of the request with the headers removed
+// regardless of casing, and without the request's stream. The request is not
+// modified.
+func SanitizeRequest(req *Request, headers ...string) *Request {
+	rc := req.Clone()
+	for _, h := range headers {
+		rc.removeHeaderFold(h)
+	}
+	rc.stream = nil
+	rc.isStreamSeekable = false
+	rc.streamStartPos = 0
+	rc.streamFactory = nil
+	return rc
+}
+
+type requestTap struct {
+	tap     RequestTap
+	options RequestTapOptions
+}
+
+// ID returns the middleware identifier.
+func (*requestTap) ID() string { return "RequestTap" }
+
+// HandleFinalize passes the sanitized request to the tap, and invokes the
+// next handler with the unmodified request.
+func (m *requestTap) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	m.tap.TapRequest(ctx, SanitizeRequest(req, m.options.SensitiveHeaders...))
+
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRequestTapMiddleware(t *testing.T) {
+	stack := middleware.NewStack("stack", NewStackRequest)
+
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+		func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+			middleware.SerializeOutput, middleware.Metadata, error,
+		) {
+			req := in.Request.(*Request)
+			req.URL.Scheme = "https"
+			req.URL.Host = "example.com"
+			req.Header.Set("X-Custom", "value")
+			var err error
+			if in.Request, err = req.SetStream(strings.NewReader("body")); err != nil {
+				return middleware.SerializeOutput{}, middleware.Metadata{}, err
+			}
+			return next.HandleSerialize(ctx, in)
+		}), middleware.After)
+
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Signing",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+			middleware.FinalizeOutput, middleware.Metadata, error,
+		) {
+			req := in.Request.(*Request)
+			req.Header.Set("Authorization", "secret-signature")
+			req.Header["proxy-authorization"] = []string{"secret-proxy"}
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+
+	var tapped *Request
+	err := AddRequestTapMiddleware(stack, RequestTapFunc(func(ctx context.Context, req *Request) {
+		tapped = req
+	}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var sent *Request
+	handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		sent = input.(*Request)
+		return &Response{}, middleware.Metadata{}, nil
+	})
+
+	if _, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if tapped == nil {
+		t.Fatalf("expect tap to be invoked")
+	}
+	for _, h := range []string{"Authorization", "Proxy-Authorization"} {
+		for k := range tapped.Header {
+			if strings.EqualFold(k, h) {
+				t.Errorf("expect tapped request to not have %v header", k)
+			}
+		}
+	}
+	if e, a := "value", tapped.Header.Get("X-Custom"); e != a {
+		t.Errorf("expect %v tapped header, got %v", e, a)
+	}
+	if tapped.GetStream() != nil {
+		t.Errorf("expect tapped request to not have stream")
+	}
+
+	if e, a := "secret-signature", sent.Header.Get("Authorization"); e != a {
+		t.Errorf("expect %v sent Authorization header, got %v", e, a)
+	}
+	if e, a := "secret-proxy", sent.Header["proxy-authorization"][0]; e != a {
+		t.Errorf("expect %v sent proxy-authorization header, got %v", e, a)
+	}
+	body, err := ioutil.ReadAll(sent.GetStream())
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "body", string(body); e != a {
+		t.Errorf("expect %v sent body, got %v", e, a)
+	}
+}
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ResponseBodyTeeResult is the outcome of teeing a response body to a sink,
+// reported when the body is closed.
+type ResponseBodyTeeResult struct {
+	// The operation's sink the body was written to.
+	Sink io.Writer
+
+	// Set if the whole body was read by the consumer, and written to the
+	// sink. If not set, the sink only received part of the body, (e.g. the
+	// consumer closed the body before reading all of it), and should not be
+	// used as a complete copy.
+	Complete bool
+
+	// The number of bytes written to the sink.
+	Written int64
+
+	// The error returned by the sink, if writing to the sink failed.
+	SinkErr error
+}
+
+// ResponseBodyTeeOptions provides the options for the response body tee
+// middleware.
+type ResponseBodyTeeOptions struct {
+	// Sets if a read of the response body fails when writing to the sink
+	// fails. If not set, the body is no longer written to the sink after a
+	// sink error, and the consumer's reads are not affected.
+	FailOnSinkError bool
+
+	// Invoked once with the outcome of the tee when the response body is
+	// closed, (e.g. to discard a partial cache file). Invoked for the
+	// response bodies of all operations of the stack.
+	OnClose func(ResponseBodyTeeResult)
+}
+
+// ResponseBodyTeeError is the error returned by reads of a teed response body
+// when writing to the sink failed, and FailOnSinkError is set.
+type ResponseBodyTeeError struct {
+	Err error
+}
+
+func (e *ResponseBodyTeeError) Error() string {
+	return fmt.Sprintf("failed to write response body to tee sink, %v", e.Err)
+}
+
+// Unwrap returns the sink's error.
+func (e *ResponseBodyTeeError) Unwrap() error { return e.Err }
+
+type responseBodyTeeSinkKey struct{}
+
+// SetResponseBodyTeeSink returns a context with the sink the operation's
+// response body is written to by the response body tee middleware, see
+// AddResponseBodyTeeMiddleware.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetResponseBodyTeeSink(ctx context.Context, sink io.Writer) context.Context {
+	return middleware.WithStackValue(ctx, responseBodyTeeSinkKey{}, sink)
+}
+
+// GetResponseBodyTeeSink returns the sink the operation's response body is
+// written to, or nil if not set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetResponseBodyTeeSink(ctx context.Context) io.Writer {
+	v, _ := middleware.GetStackValue(ctx, responseBodyTeeSinkKey{}).(io.Writer)
+	return v
+}
+
+// AddResponseBodyTeeMiddleware adds the middleware writing the response body
+// to the operation's sink as the body is read by the consumer, (e.g. caching a
+// download to a file). The middleware is added to the end of the stack's
+// Deserialize step, and only tees the bodies of successful responses.
+//
+// The sink is set per operation with SetResponseBodyTeeSink, so that
+// concurrent operations of a client do not share a sink. Operations without a
+// sink are not teed.
+//
+// The sink only receives the bytes read by the consumer, so that the body is
+// not downloaded twice. Use ResponseBodyTeeOptions.OnClose to determine if
+// the sink received the whole body.
+func AddResponseBodyTeeMiddleware(stack *middleware.Stack, optFns ...func(*ResponseBodyTeeOptions)) error {
+	var options ResponseBodyTeeOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Deserialize.Add(&responseBodyTee{
+		options: options,
+	}, middleware.After)
+}
+
+type responseBodyTee struct {
+	options ResponseBodyTeeOptions
+}
+
+// ID returns the middleware identifier.
+func (*responseBodyTee) ID() string { return "ResponseBodyTee" }
+
+// HandleDeserialize wraps the body of successful responses with a reader that
+// writes to the operation's sink.
+func (m *responseBodyTee) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	sink := GetResponseBodyTeeSink(ctx)
+	if sink == nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || resp.Body == nil {
+		return out, metadata, err
+	}
+
+	resp.Body = &teeReadCloser{
+		body:    resp.Body,
+		sink:    sink,
+		options: m.options,
+		result:  ResponseBodyTeeResult{Sink: sink},
+	}
+
+	return out, metadata, err
+}
+
+type teeReadCloser struct {
+	body    io.ReadCloser
+	sink    io.Writer
+	options ResponseBodyTeeOptions
+
+	result    ResponseBodyTeeResult
+	closeOnce sync.Once
+}
+
+func (r *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 && r.result.SinkErr == nil {
+		written, werr := r.sink.Write(p[:n])
+		r.result.Written += int64(written)
+		if werr == nil && written != n {
+			werr = io.ErrShortWrite
+		}
+		if werr != nil {
+			r.result.SinkErr = werr
+			if r.options.FailOnSinkError {
+				return n, &ResponseBodyTeeError{Err: werr}
+			}
+		}
+	}
+
+	if err == io.EOF && r.result.SinkErr == nil {
+		r.result.Complete = true
+	}
+	return n, err
+}
+
+func (r *teeReadCloser) Close() error {
+	err := r.body.Close()
+	r.closeOnce.Do(func() {
+		if r.options.OnClose != nil {
+			r.options.OnClose(r.result)
+		}
+	})
+	return err
+}
@@ -0,0 +1,280 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type failingWriter struct {
+	n   int
+	buf bytes.Buffer
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.n {
+		return 0, fmt.Errorf("disk full")
+	}
+	return w.buf.Write(p)
+}
+
+func TestResponseBodyTeeMiddleware(t *testing.T) {
+	const body = "the quick brown fox jumps over the lazy dog"
+
+	cases := map[string]struct {
+		StatusCode      int
+		ReadN           int
+		Sink            func() (io.Writer, func() string)
+		FailOnSinkError bool
+		ExpectRead      string
+		ExpectSink      string
+		ExpectResult    ResponseBodyTeeResult
+		ExpectReadErr   bool
+		ExpectNoClose   bool
+		NoSink          bool
+	}{
+		"full read": {
+			StatusCode: 200,
+			ReadN:      -1,
+			ExpectRead: body,
+			ExpectSink: body,
+			ExpectResult: ResponseBodyTeeResult{
+				Complete: true,
+				Written:  int64(len(body)),
+			},
+		},
+		"partial read": {
+			StatusCode: 200,
+			ReadN:      9,
+			ExpectRead: "the quick",
+			ExpectSink: "the quick",
+			ExpectResult: ResponseBodyTeeResult{
+				Written: 9,
+			},
+		},
+		"sink error ignored": {
+			StatusCode: 200,
+			ReadN:      -1,
+			Sink: func() (io.Writer, func() string) {
+				w := &failingWriter{n: 10}
+				return w, w.buf.String
+			},
+			ExpectRead: body,
+			ExpectSink: "the quick ",
+			ExpectResult: ResponseBodyTeeResult{
+				Written: 10,
+				SinkErr: fmt.Errorf("disk full"),
+			},
+		},
+		"sink error fails read": {
+			StatusCode: 200,
+			ReadN:      -1,
+			Sink: func() (io.Writer, func() string) {
+				w := &failingWriter{n: 10}
+				return w, w.buf.String
+			},
+			FailOnSinkError: true,
+			ExpectReadErr:   true,
+			ExpectSink:      "the quick ",
+			ExpectResult: ResponseBodyTeeResult{
+				Written: 10,
+				SinkErr: fmt.Errorf("disk full"),
+			},
+		},
+		"no sink not teed": {
+			StatusCode:    200,
+			ReadN:         -1,
+			ExpectRead:    body,
+			ExpectNoClose: true,
+			NoSink:        true,
+		},
+		"error response not teed": {
+			StatusCode:    404,
+			ReadN:         -1,
+			ExpectRead:    body,
+			ExpectNoClose: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var sink io.Writer
+			var sinkContent func() string
+			if c.Sink != nil {
+				sink, sinkContent = c.Sink()
+			} else {
+				var buf bytes.Buffer
+				sink, sinkContent = &buf, buf.String
+			}
+
+			var results []ResponseBodyTeeResult
+			stack := middleware.NewStack("stack", NewStackRequest)
+			err := AddResponseBodyTeeMiddleware(stack, func(o *ResponseBodyTeeOptions) {
+				o.FailOnSinkError = c.FailOnSinkError
+				o.OnClose = func(r ResponseBodyTeeResult) {
+					results = append(results, r)
+				}
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var resp *Response
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("captureResponse",
+				func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out, metadata, err = next.HandleDeserialize(ctx, in)
+					resp, _ = out.RawResponse.(*Response)
+					return out, metadata, err
+				}), middleware.After)
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				return &Response{
+					Response: &http.Response{
+						StatusCode: c.StatusCode,
+						Header:     http.Header{},
+						Body:       ioutil.NopCloser(&splitBodyReader{r: strings.NewReader(body), n: 5}),
+					},
+				}, middleware.Metadata{}, nil
+			})
+
+			ctx := context.Background()
+			if !c.NoSink {
+				ctx = SetResponseBodyTeeSink(ctx, sink)
+			}
+			if _, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{}); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var read []byte
+			if c.ReadN < 0 {
+				read, err = ioutil.ReadAll(resp.Body)
+			} else {
+				read, err = ioutil.ReadAll(io.LimitReader(resp.Body, int64(c.ReadN)))
+			}
+			if c.ExpectReadErr {
+				var teeErr *ResponseBodyTeeError
+				if !errors.As(err, &teeErr) {
+					t.Errorf("expect %T error, got %v", teeErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			} else if e, a := c.ExpectRead, string(read); e != a {
+				t.Errorf("expect %q read, got %q", e, a)
+			}
+
+			resp.Body.Close()
+			resp.Body.Close()
+
+			if e, a := c.ExpectSink, sinkContent(); e != a {
+				t.Errorf("expect %q in sink, got %q", e, a)
+			}
+
+			if c.ExpectNoClose {
+				if len(results) != 0 {
+					t.Errorf("expect no tee results, got %v", results)
+				}
+				return
+			}
+			if e, a := 1, len(results); e != a {
+				t.Fatalf("expect %v tee results, got %v", e, a)
+			}
+			if diff := cmp.Diff(c.ExpectResult, results[0], cmp.Comparer(func(x, y error) bool {
+				return fmt.Sprint(x) == fmt.Sprint(y)
+			}), cmpopts.IgnoreFields(ResponseBodyTeeResult{}, "Sink")); len(diff) != 0 {
+				t.Errorf("expect tee result to match\n%s", diff)
+			}
+			if results[0].Sink != sink {
+				t.Errorf("expect tee result for operation's sink")
+			}
+		})
+	}
+}
+
+func TestResponseBodyTeeMiddleware_ConcurrentOperations(t *testing.T) {
+	type bodyKey struct{}
+
+	stack := middleware.NewStack("stack", NewStackRequest)
+	if err := AddResponseBodyTeeMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		body := strings.Repeat(ctx.Value(bodyKey{}).(string), 1000)
+		return &Response{
+			Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(&splitBodyReader{r: strings.NewReader(body), n: 7}),
+			},
+		}, middleware.Metadata{}, nil
+	}), stack)
+
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("readBody",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			out, metadata, err = next.HandleDeserialize(ctx, in)
+			if err != nil {
+				return out, metadata, err
+			}
+			resp := out.RawResponse.(*Response)
+			defer resp.Body.Close()
+			if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+				return out, metadata, err
+			}
+			return out, metadata, err
+		}), middleware.Before)
+
+	inputs := []string{"a", "b", "c", "d"}
+	sinks := make([]bytes.Buffer, len(inputs))
+	errs := make(chan error, len(inputs))
+	for i, input := range inputs {
+		go func(input string, sink *bytes.Buffer) {
+			ctx := context.WithValue(context.Background(), bodyKey{}, input)
+			ctx = SetResponseBodyTeeSink(ctx, sink)
+			_, _, err := handler.Handle(ctx, struct{}{})
+			errs <- err
+		}(input, &sinks[i])
+	}
+	for range inputs {
+		if err := <-errs; err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+
+	for i, input := range inputs {
+		if e, a := strings.Repeat(input, 1000), sinks[i].String(); e != a {
+			t.Errorf("expect operation %v sink to only have its body, got %q", input, a)
+		}
+	}
+}
+
+// splitBodyReader returns the content of the reader in reads of at most n
+// bytes.
+type splitBodyReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *splitBodyReader) Read(p []byte) (int, error) {
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	return r.r.Read(p)
+}
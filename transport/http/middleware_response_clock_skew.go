@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultResponseClockSkewWindow is the default maximum offset of a
+// response's Date from the client's clock.
+const DefaultResponseClockSkewWindow = 15 * time.Minute
+
+// ResponseClockSkewError is the error returned when the Date of a response is
+// further from the client's clock than the allowed window, (e.g. a replayed
+// response).
+type ResponseClockSkewError struct {
+	// The Date of the response.
+	ResponseDate time.Time
+
+	// The client's time the response was received at, corrected by the
+	// known clock skew.
+	ClientTime time.Time
+
+	// The maximum allowed offset of the response's Date.
+	Window time.Duration
+}
+
+// Skew returns the offset of the response's Date from the client's time.
+func (e *ResponseClockSkewError) Skew() time.Duration {
+	return e.ResponseDate.Sub(e.ClientTime)
+}
+
+func (e *ResponseClockSkewError) Error() string {
+	return fmt.Sprintf("response date %s is %v from client time %s, exceeds window %v",
+		e.ResponseDate.Format(time.RFC3339), e.Skew(), e.ClientTime.Format(time.RFC3339), e.Window)
+}
+
+// ResponseClockSkewOptions provides the options for the response clock skew
+// middleware.
+type ResponseClockSkewOptions struct {
+	// The maximum offset of a response's Date from the client's clock.
+	// Defaults to DefaultResponseClockSkewWindow.
+	Window time.Duration
+}
+
+// AddResponseClockSkewMiddleware adds the middleware rejecting responses
+// whose Date header is further from the client's clock than the window, to
+// the end of the stack's Deserialize step. The client's clock is corrected by
+// the clock skew stored in the context, see SetClockSkew.
+//
+// Responses without a Date header, or with a Date that cannot be parsed,
+// are not rejected.
+func AddResponseClockSkewMiddleware(stack *middleware.Stack, optFns ...func(*ResponseClockSkewOptions)) error {
+	options := ResponseClockSkewOptions{
+		Window: DefaultResponseClockSkewWindow,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Deserialize.Add(&responseClockSkew{window: options.Window}, middleware.After)
+}
+
+type responseClockSkew struct {
+	window time.Duration
+}
+
+// ID returns the middleware identifier.
+func (*responseClockSkew) ID() string { return "ResponseClockSkew" }
+
+// HandleDeserialize returns a ResponseClockSkewError if the response's Date
+// is outside the window.
+func (m *responseClockSkew) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+
+	v := resp.Header.Get("Date")
+	if len(v) == 0 {
+		return out, metadata, err
+	}
+	date, parseErr := ParseTime(v)
+	if parseErr != nil {
+		return out, metadata, err
+	}
+
+	clientTime := SigningTime(ctx)
+	if skew := date.Sub(clientTime); skew > m.window || skew < -m.window {
+		return out, metadata, &ResponseClockSkewError{
+			ResponseDate: date,
+			ClientTime:   clientTime,
+			Window:       m.window,
+		}
+	}
+
+	return out, metadata, err
+}
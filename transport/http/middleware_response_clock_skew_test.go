@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestResponseClockSkewMiddleware(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	cases := map[string]struct {
+		Date       string
+		ClockSkew  time.Duration
+		Window     time.Duration
+		ExpectSkew time.Duration
+		ExpectErr  bool
+	}{
+		"within window": {
+			Date: "Sat, 01 Jan 2022 12:10:00 GMT",
+		},
+		"no date": {},
+		"malformed date": {
+			Date: "not a date",
+		},
+		"ahead of window": {
+			Date:       "Sat, 01 Jan 2022 12:20:00 GMT",
+			ExpectSkew: 20 * time.Minute,
+			ExpectErr:  true,
+		},
+		"behind window": {
+			Date:       "Sat, 01 Jan 2022 09:00:00 GMT",
+			ExpectSkew: -3 * time.Hour,
+			ExpectErr:  true,
+		},
+		"corrected by clock skew": {
+			Date:      "Sat, 01 Jan 2022 13:00:00 GMT",
+			ClockSkew: time.Hour,
+		},
+		"custom window": {
+			Date:       "Sat, 01 Jan 2022 12:02:00 GMT",
+			Window:     time.Minute,
+			ExpectSkew: 2 * time.Minute,
+			ExpectErr:  true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+
+			var optFns []func(*ResponseClockSkewOptions)
+			if c.Window != 0 {
+				optFns = append(optFns, func(o *ResponseClockSkewOptions) {
+					o.Window = c.Window
+				})
+			}
+			if err := AddResponseClockSkewMiddleware(stack, optFns...); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				header := http.Header{}
+				if len(c.Date) != 0 {
+					header.Set("Date", c.Date)
+				}
+				return &Response{Response: &http.Response{StatusCode: 200, Header: header}},
+					middleware.Metadata{}, nil
+			})
+
+			ctx := SetClockSkew(context.Background(), c.ClockSkew)
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+			if !c.ExpectErr {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+
+			var skewErr *ResponseClockSkewError
+			if !errors.As(err, &skewErr) {
+				t.Fatalf("expect %T error, got %v", skewErr, err)
+			}
+			if e, a := c.ExpectSkew, skewErr.Skew(); e != a {
+				t.Errorf("expect %v skew, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultResponseSignatureHeader is the default response header the
+// signature of the response is read from.
+const DefaultResponseSignatureHeader = "X-Response-Signature"
+
+// ResponseSignatureKeyProvider provides the interface for retrieving the key
+// response signatures are verified with.
+type ResponseSignatureKeyProvider interface {
+	GetResponseSignatureKey(ctx context.Context) ([]byte, error)
+}
+
+// ResponseSignatureKeyProviderFunc provides a wrapper around a function to
+// be used as a ResponseSignatureKeyProvider.
+type ResponseSignatureKeyProviderFunc func(ctx context.Context) ([]byte, error)
+
+// GetResponseSignatureKey invokes the wrapped function.
+func (fn ResponseSignatureKeyProviderFunc) GetResponseSignatureKey(ctx context.Context) ([]byte, error) {
+	return fn(ctx)
+}
+
+// ResponseSignatureFunc computes the expected signature of a response, with
+// the key, response, and the response's body.
+type ResponseSignatureFunc func(key []byte, resp *Response, body []byte) (string, error)
+
+// HMACSHA256ResponseSignature is a ResponseSignatureFunc that computes the
+// hex encoded HMAC-SHA256 of the response's body.
+func HMACSHA256ResponseSignature(key []byte, resp *Response, body []byte) (string, error) {
+	h := hmac.New(sha256.New, key)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ResponseSignatureOptions provides the options for the response signature
+// verification middleware.
+type ResponseSignatureOptions struct {
+	// The response header the signature is read from. Defaults to
+	// DefaultResponseSignatureHeader.
+	Header string
+
+	// Computes the expected signature of the response. Defaults to
+	// HMACSHA256ResponseSignature.
+	Signature ResponseSignatureFunc
+}
+
+// ResponseSignatureError is the error returned when the signature of a
+// response is missing, or does not match the expected signature.
+type ResponseSignatureError struct {
+	Header string
+	Reason string
+}
+
+func (e *ResponseSignatureError) Error() string {
+	return fmt.Sprintf("response signature verification failed, %s %s", e.Header, e.Reason)
+}
+
+// AddResponseSignatureMiddleware adds the middleware verifying the signature
+// of responses to the end of the stack's Deserialize step, so that responses
+// are verified before they are deserialized.
+func AddResponseSignatureMiddleware(
+	stack *middleware.Stack, keyProvider ResponseSignatureKeyProvider, optFns ...func(*ResponseSignatureOptions),
+) error {
+	options := ResponseSignatureOptions{
+		Header:    DefaultResponseSignatureHeader,
+		Signature: HMACSHA256ResponseSignature,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Deserialize.Add(&verifyResponseSignature{
+		keyProvider: keyProvider,
+		options:     options,
+	}, middleware.After)
+}
+
+type verifyResponseSignature struct {
+	keyProvider ResponseSignatureKeyProvider
+	options     ResponseSignatureOptions
+}
+
+// ID returns the middleware identifier.
+func (*verifyResponseSignature) ID() string { return "VerifyResponseSignature" }
+
+// HandleDeserialize verifies the signature of the raw response. The response
+// body is buffered to compute the signature, and replaced with the buffered
+// copy, so that it can be deserialized by subsequent middleware.
+func (m *verifyResponseSignature) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+
+	signature := resp.Header.Get(m.options.Header)
+	if len(signature) == 0 {
+		return out, metadata, &ResponseSignatureError{
+			Header: m.options.Header,
+			Reason: "missing",
+		}
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		body, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return out, metadata, fmt.Errorf("failed to read response body, %w", err)
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	key, err := m.keyProvider.GetResponseSignatureKey(ctx)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to get response signature key, %w", err)
+	}
+
+	expect, err := m.options.Signature(key, resp, body)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to compute response signature, %w", err)
+	}
+
+	if !hmac.Equal([]byte(expect), []byte(signature)) {
+		return out, metadata, &ResponseSignatureError{
+			Header: m.options.Header,
+			Reason: "does not match",
+		}
+	}
+
+	return out, metadata, nil
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestResponseSignatureMiddleware(t *testing.T) {
+	key := []byte("secret")
+	const body = `{"value":"hello"}`
+	validSignature, _ := HMACSHA256ResponseSignature(key, nil, []byte(body))
+
+	cases := map[string]struct {
+		Header       http.Header
+		Body         string
+		ExpectReason string
+	}{
+		"valid signature": {
+			Header: http.Header{"X-Response-Signature": []string{validSignature}},
+			Body:   body,
+		},
+		"tampered body": {
+			Header:       http.Header{"X-Response-Signature": []string{validSignature}},
+			Body:         `{"value":"goodbye"}`,
+			ExpectReason: "does not match",
+		},
+		"invalid signature": {
+			Header:       http.Header{"X-Response-Signature": []string{"0123abcd"}},
+			Body:         body,
+			ExpectReason: "does not match",
+		},
+		"missing signature": {
+			Header:       http.Header{},
+			Body:         body,
+			ExpectReason: "missing",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+
+			// Reads the body after verification, as an operation deserializer
+			// would.
+			var decoded string
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("decode", func(
+				ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+			) (
+				out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+			) {
+				out, metadata, err = next.HandleDeserialize(ctx, in)
+				if err != nil {
+					return out, metadata, err
+				}
+				b, err := ioutil.ReadAll(out.RawResponse.(*Response).Body)
+				decoded = string(b)
+				return out, metadata, err
+			}), middleware.After)
+
+			err := AddResponseSignatureMiddleware(stack, ResponseSignatureKeyProviderFunc(
+				func(context.Context) ([]byte, error) { return key, nil }))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				return &Response{
+					Response: &http.Response{
+						StatusCode: 200,
+						Header:     c.Header,
+						Body:       ioutil.NopCloser(strings.NewReader(c.Body)),
+					},
+				}, middleware.Metadata{}, nil
+			})
+
+			_, _, err = middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if len(c.ExpectReason) != 0 {
+				var sigErr *ResponseSignatureError
+				if !errors.As(err, &sigErr) {
+					t.Fatalf("expect %T error, got %v", sigErr, err)
+				}
+				if e, a := c.ExpectReason, sigErr.Reason; e != a {
+					t.Errorf("expect %q reason, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.Body, decoded; e != a {
+				t.Errorf("expect decoded body %q, got %q", e, a)
+			}
+		})
+	}
+}
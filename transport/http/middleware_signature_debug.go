@@ -0,0 +1,225 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultSignatureErrorCodes are the error codes of the errors services
+// return when the request's signature is rejected.
+var DefaultSignatureErrorCodes = []string{
+	"SignatureDoesNotMatch",
+	"InvalidSignatureException",
+	"IncompleteSignature",
+}
+
+// DefaultSignatureDebugRedactedHeaders are the headers whose values are
+// redacted from the signature debug information, since they include
+// credentials.
+var DefaultSignatureDebugRedactedHeaders = []string{
+	"Authorization",
+	"X-Amz-Security-Token",
+}
+
+const redactedValue = "<redacted>"
+
+// SignatureDebugError wraps the error of a request whose signature was
+// rejected, with the intermediate values the client computed the signature
+// from, to compare against the values the service expected.
+//
+// The values of the redacted headers, (e.g. Authorization), are replaced with
+// "<redacted>" in the header, canonical request, and string to sign, so that
+// credentials are never included.
+type SignatureDebugError struct {
+	// The canonical request the signature was computed from.
+	CanonicalRequest string
+
+	// The string the signature was computed over.
+	StringToSign string
+
+	// The sorted, lowercase, names of the headers that were signed.
+	SignedHeaders []string
+
+	// The headers of the request that was sent.
+	Header http.Header
+
+	Err error
+}
+
+func (e *SignatureDebugError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "request signature rejected, %v", e.Err)
+	fmt.Fprintf(&b, "\ncanonical request:\n%s", e.CanonicalRequest)
+	fmt.Fprintf(&b, "\nstring to sign:\n%s", e.StringToSign)
+	fmt.Fprintf(&b, "\nsigned headers: %s", strings.Join(e.SignedHeaders, ";"))
+	return b.String()
+}
+
+// Unwrap returns the underlying error of the rejected request.
+func (e *SignatureDebugError) Unwrap() error { return e.Err }
+
+// SignatureDebugOptions provides the options for the signature debug
+// middleware.
+type SignatureDebugOptions struct {
+	// The error codes of the errors wrapped with the signature debug
+	// information. Defaults to DefaultSignatureErrorCodes.
+	ErrorCodes []string
+
+	// The headers whose values are redacted from the debug information.
+	// Defaults to DefaultSignatureDebugRedactedHeaders.
+	RedactedHeaders []string
+}
+
+// AddSignatureDebugMiddleware adds the middleware capturing the intermediate
+// values the request's signature was computed from, see SetOnSign, and the
+// headers of the sent request. If the operation fails with a signature error,
+// the error is wrapped in a SignatureDebugError with the captured values of
+// the last attempt.
+//
+// The capturing middleware are added to the front of the stack's Finalize
+// step, and the end of the Deserialize step. Signers must report the signing
+// details with the function returned by GetOnSign for the canonical request,
+// and string to sign to be captured. Intended for debugging only.
+func AddSignatureDebugMiddleware(stack *middleware.Stack, optFns ...func(*SignatureDebugOptions)) error {
+	options := SignatureDebugOptions{
+		ErrorCodes:      DefaultSignatureErrorCodes,
+		RedactedHeaders: DefaultSignatureDebugRedactedHeaders,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	m := &signatureDebug{
+		errorCodes: map[string]struct{}{},
+		redacted:   make([]string, 0, len(options.RedactedHeaders)),
+	}
+	for _, code := range options.ErrorCodes {
+		m.errorCodes[code] = struct{}{}
+	}
+	for _, h := range options.RedactedHeaders {
+		m.redacted = append(m.redacted, http.CanonicalHeaderKey(h))
+	}
+
+	if err := stack.Finalize.Add(m, middleware.Before); err != nil {
+		return fmt.Errorf("failed to add %s finalize middleware, %w", m.ID(), err)
+	}
+	capture := &captureSignedRequest{}
+	if err := stack.Deserialize.Add(capture, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s deserialize middleware, %w", capture.ID(), err)
+	}
+	return nil
+}
+
+// signatureDebugCapture is the signature debug information captured for the
+// last attempt of the operation.
+type signatureDebugCapture struct {
+	details SigningDetails
+	header  http.Header
+}
+
+type signatureDebugCaptureKey struct{}
+
+func getSignatureDebugCapture(ctx context.Context) *signatureDebugCapture {
+	v, _ := middleware.GetStackValue(ctx, signatureDebugCaptureKey{}).(*signatureDebugCapture)
+	return v
+}
+
+type signatureDebug struct {
+	errorCodes map[string]struct{}
+	redacted   []string
+}
+
+// ID returns the middleware identifier.
+func (*signatureDebug) ID() string { return "SignatureDebug" }
+
+// HandleFinalize captures the signing details of each attempt, and wraps
+// signature errors returned by the next handler with the captured details.
+func (m *signatureDebug) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	capture := &signatureDebugCapture{}
+	ctx = middleware.WithStackValue(ctx, signatureDebugCaptureKey{}, capture)
+
+	onSign := GetOnSign(ctx)
+	ctx = SetOnSign(ctx, func(ctx context.Context, details SigningDetails) {
+		capture.details = details
+		if onSign != nil {
+			onSign(ctx, details)
+		}
+	})
+
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	if err == nil {
+		return out, metadata, err
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return out, metadata, err
+	}
+	if _, ok := m.errorCodes[apiErr.ErrorCode()]; !ok {
+		return out, metadata, err
+	}
+
+	return out, metadata, m.newError(capture, err)
+}
+
+func (m *signatureDebug) newError(capture *signatureDebugCapture, err error) *SignatureDebugError {
+	var secrets []string
+	header := capture.header.Clone()
+	for _, h := range m.redacted {
+		values, ok := header[h]
+		if !ok {
+			continue
+		}
+		secrets = append(secrets, values...)
+		header[h] = []string{redactedValue}
+	}
+
+	redact := func(v string) string {
+		for _, secret := range secrets {
+			if len(secret) != 0 {
+				v = strings.ReplaceAll(v, secret, redactedValue)
+			}
+		}
+		return v
+	}
+
+	return &SignatureDebugError{
+		CanonicalRequest: redact(capture.details.CanonicalRequest),
+		StringToSign:     redact(capture.details.StringToSign),
+		SignedHeaders:    append([]string(nil), capture.details.SignedHeaders...),
+		Header:           header,
+		Err:              err,
+	}
+}
+
+// captureSignedRequest captures the headers of the request sent for the
+// attempt, after it was signed.
+type captureSignedRequest struct{}
+
+// ID returns the middleware identifier.
+func (*captureSignedRequest) ID() string { return "SignatureDebugCaptureRequest" }
+
+// HandleDeserialize captures the request's headers before invoking the next
+// handler.
+func (*captureSignedRequest) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	if capture := getSignatureDebugCapture(ctx); capture != nil {
+		if req, ok := in.Request.(*Request); ok {
+			capture.header = req.Header.Clone()
+		}
+	}
+	return next.HandleDeserialize(ctx, in)
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const mockSessionToken = "mock-session-token"
+
+// mockSigner signs the request in the style of SigV4, reporting the signing
+// details to the function set with SetOnSign.
+var mockSigner = middleware.FinalizeMiddlewareFunc("Signing",
+	func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+		middleware.FinalizeOutput, middleware.Metadata, error,
+	) {
+		req := in.Request.(*Request)
+		req.Header.Set("X-Amz-Security-Token", mockSessionToken)
+
+		details := SigningDetails{
+			SignedHeaders: []string{"host", "x-amz-security-token"},
+			Timestamp:     time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			CanonicalRequest: "GET\n/\n\nhost:example.amazonaws.com\n" +
+				"x-amz-security-token:" + mockSessionToken + "\n\nhost;x-amz-security-token\nUNSIGNED-PAYLOAD",
+			StringToSign: "AWS4-HMAC-SHA256\n20200102T030405Z\n20200102/us-west-2/svc/aws4_request\nabc123",
+		}
+		req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKID/20200102/us-west-2/svc/aws4_request, "+
+			"SignedHeaders=host;x-amz-security-token, Signature=def456")
+
+		if fn := GetOnSign(ctx); fn != nil {
+			fn(ctx, details)
+		}
+		return next.HandleFinalize(ctx, in)
+	})
+
+func TestSignatureDebugMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		ErrCode     string
+		ExpectDebug *SignatureDebugError
+	}{
+		"signature error": {
+			ErrCode: "SignatureDoesNotMatch",
+			ExpectDebug: &SignatureDebugError{
+				CanonicalRequest: "GET\n/\n\nhost:example.amazonaws.com\n" +
+					"x-amz-security-token:<redacted>\n\nhost;x-amz-security-token\nUNSIGNED-PAYLOAD",
+				StringToSign:  "AWS4-HMAC-SHA256\n20200102T030405Z\n20200102/us-west-2/svc/aws4_request\nabc123",
+				SignedHeaders: []string{"host", "x-amz-security-token"},
+				Header: http.Header{
+					"Authorization":        []string{"<redacted>"},
+					"X-Amz-Security-Token": []string{"<redacted>"},
+					"X-Foo":                []string{"bar"},
+				},
+			},
+		},
+		"other error": {
+			ErrCode: "ValidationException",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Build.Add(middleware.BuildMiddlewareFunc("setHeader",
+				func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (
+					middleware.BuildOutput, middleware.Metadata, error,
+				) {
+					in.Request.(*Request).Header.Set("X-Foo", "bar")
+					return next.HandleBuild(ctx, in)
+				}), middleware.After)
+			stack.Finalize.Add(mockSigner, middleware.After)
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer",
+				func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out, metadata, err = next.HandleDeserialize(ctx, in)
+					if err != nil {
+						return out, metadata, err
+					}
+					return out, metadata, &ResponseError{
+						Response: out.RawResponse.(*Response),
+						Err:      &smithy.GenericAPIError{Code: c.ErrCode, Message: "rejected"},
+					}
+				}), middleware.After)
+			if err := AddSignatureDebugMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				return &Response{Response: &http.Response{StatusCode: 403}}, middleware.Metadata{}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if err == nil {
+				t.Fatalf("expect error, got none")
+			}
+
+			var apiErr smithy.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expect API error accessible, got %v", err)
+			}
+			if e, a := c.ErrCode, apiErr.ErrorCode(); e != a {
+				t.Errorf("expect %v error code, got %v", e, a)
+			}
+
+			var debugErr *SignatureDebugError
+			if c.ExpectDebug == nil {
+				if errors.As(err, &debugErr) {
+					t.Errorf("expect no debug information, got %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &debugErr) {
+				t.Fatalf("expect %T error, got %v", debugErr, err)
+			}
+			if diff := cmp.Diff(c.ExpectDebug, debugErr, cmpopts.IgnoreFields(SignatureDebugError{}, "Err")); len(diff) != 0 {
+				t.Errorf("expect debug information to match\n%s", diff)
+			}
+
+			msg := err.Error()
+			for _, secret := range []string{mockSessionToken, "AKID", "def456"} {
+				if strings.Contains(msg, secret) {
+					t.Errorf("expect error not to include %q, got %q", secret, msg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Signer signs an HTTP request, (e.g. SigV4), by setting the request's
+// authorization headers or query parameters.
+type Signer interface {
+	// SignHTTP signs the request. The payloadHash is the hex encoded hash of
+	// the request's payload, or empty if it was not computed prior to
+	// signing.
+	SignHTTP(ctx context.Context, req *Request, payloadHash string) error
+}
+
+// SignerFunc provides a wrapper for a function to satisfy the Signer
+// interface.
+type SignerFunc func(ctx context.Context, req *Request, payloadHash string) error
+
+// SignHTTP invokes the wrapped function.
+func (fn SignerFunc) SignHTTP(ctx context.Context, req *Request, payloadHash string) error {
+	return fn(ctx, req, payloadHash)
+}
+
+type payloadHashKey struct{}
+
+// SetPayloadHash returns a context with the hex encoded hash of the
+// request's payload, for the signing middleware to sign the request with.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetPayloadHash(ctx context.Context, hash string) context.Context {
+	return middleware.WithStackValue(ctx, payloadHashKey{}, hash)
+}
+
+// GetPayloadHash returns the hex encoded hash of the request's payload, or
+// empty string if not set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetPayloadHash(ctx context.Context) string {
+	v, _ := middleware.GetStackValue(ctx, payloadHashKey{}).(string)
+	return v
+}
+
+// AddSigningMiddleware adds the middleware signing the request with the
+// signer, to the end of the stack's Finalize step, so that all headers are
+// present on the request when it is signed. The payload hash set by a prior
+// middleware with SetPayloadHash is passed to the signer.
+//
+// The request is signed each time it passes through the middleware, so
+// requests retried by a prior Finalize middleware are signed again.
+func AddSigningMiddleware(stack *middleware.Stack, signer Signer) error {
+	return stack.Finalize.Add(&signing{signer: signer}, middleware.After)
+}
+
+type signing struct {
+	signer Signer
+}
+
+// ID returns the middleware identifier.
+func (*signing) ID() string { return "Signing" }
+
+// HandleFinalize signs the request with the signer.
+func (m *signing) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	if err := m.signer.SignHTTP(ctx, req, GetPayloadHash(ctx)); err != nil {
+		return out, metadata, fmt.Errorf("failed to sign request, %w", err)
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// SigningDateTimeFormat is the ISO 8601 basic format of the signing timestamp
+// header, (e.g. 20150830T123600Z).
+const SigningDateTimeFormat = "20060102T150405Z"
+
+// SigningDateOptions provides the options for the signing date middleware.
+type SigningDateOptions struct {
+	// The request header the signing timestamp is set to. Defaults to
+	// X-Amz-Date.
+	Header string
+
+	// Formats the signing timestamp as the header's value. Defaults to
+	// SigningDateTimeFormat.
+	Format func(time.Time) string
+}
+
+// AddSigningDateMiddleware adds the middleware to set the request's signing
+// timestamp header to the end of the stack's Finalize step. The middleware
+// must be added before the middleware signing the request, so that the
+// signer uses the same timestamp as the header.
+func AddSigningDateMiddleware(stack *middleware.Stack, optFns ...func(*SigningDateOptions)) error {
+	options := SigningDateOptions{
+		Header: "X-Amz-Date",
+		Format: func(t time.Time) string {
+			return t.Format(SigningDateTimeFormat)
+		},
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	return stack.Finalize.Add(&signingDate{options: options}, middleware.After)
+}
+
+type signingTimestampKey struct{}
+
+// SetSigningTimestamp returns a context with the timestamp the request is
+// signed with.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetSigningTimestamp(ctx context.Context, t time.Time) context.Context {
+	return middleware.WithStackValue(ctx, signingTimestampKey{}, t)
+}
+
+// GetSigningTimestamp returns the timestamp the request is signed with, and
+// if the value was set. Signers must use the timestamp if set, so that the
+// signature agrees with the request's signing timestamp header.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetSigningTimestamp(ctx context.Context) (time.Time, bool) {
+	v, ok := middleware.GetStackValue(ctx, signingTimestampKey{}).(time.Time)
+	return v, ok
+}
+
+type signingDate struct {
+	options SigningDateOptions
+}
+
+// ID returns the middleware identifier.
+func (*signingDate) ID() string { return "SigningDate" }
+
+// HandleFinalize sets the request's signing timestamp header from the current
+// time corrected for clock skew, overriding any existing value, and stores the
+// timestamp in the context for the signer.
+func (m *signingDate) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	// The header's format has second precision, truncate the timestamp so the
+	// signer is not given a more precise value than the header.
+	t := SigningTime(ctx).UTC().Truncate(time.Second)
+
+	req.Header.Set(m.options.Header, m.options.Format(t))
+	ctx = SetSigningTimestamp(ctx, t)
+
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSigningDateMiddleware(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+	timeNow = func() time.Time {
+		return time.Date(2015, 8, 30, 12, 36, 0, 500000000, time.UTC)
+	}
+
+	cases := map[string]struct {
+		Header       string
+		ClockSkew    time.Duration
+		ExpectHeader string
+		ExpectTime   time.Time
+	}{
+		"default": {
+			Header:       "X-Amz-Date",
+			ExpectHeader: "20150830T123600Z",
+			ExpectTime:   time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+		},
+		"clock skew": {
+			Header:       "X-Amz-Date",
+			ClockSkew:    5 * time.Minute,
+			ExpectHeader: "20150830T124100Z",
+			ExpectTime:   time.Date(2015, 8, 30, 12, 41, 0, 0, time.UTC),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+
+			// Upstream middleware setting an inconsistent date.
+			stack.Build.Add(middleware.BuildMiddlewareFunc("upstream",
+				func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (
+					middleware.BuildOutput, middleware.Metadata, error,
+				) {
+					in.Request.(*Request).Header.Set("X-Amz-Date", "Sun, 30 Aug 2015 12:00:00 GMT")
+					return next.HandleBuild(ctx, in)
+				}), middleware.After)
+
+			if err := AddSigningDateMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var signedWith time.Time
+			stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Signing",
+				func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+					middleware.FinalizeOutput, middleware.Metadata, error,
+				) {
+					var ok bool
+					signedWith, ok = GetSigningTimestamp(ctx)
+					if !ok {
+						t.Errorf("expect signing timestamp to be set")
+					}
+					return next.HandleFinalize(ctx, in)
+				}), middleware.After)
+
+			var header string
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				header = input.(*Request).Header.Get(c.Header)
+				return &Response{}, middleware.Metadata{}, nil
+			})
+
+			ctx := SetClockSkew(context.Background(), c.ClockSkew)
+			if _, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{}); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectHeader, header; e != a {
+				t.Errorf("expect %v header, got %v", e, a)
+			}
+			if e, a := c.ExpectTime, signedWith; !e.Equal(a) {
+				t.Errorf("expect %v signing timestamp, got %v", e, a)
+			}
+			if e, a := header, signedWith.Format(SigningDateTimeFormat); e != a {
+				t.Errorf("expect signer timestamp %v to match header, got %v", a, e)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSigningMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		PayloadHash     string
+		SignErr         error
+		ExpectAuth      string
+		ExpectErr       string
+		ExpectNotCalled bool
+	}{
+		"payload hash": {
+			PayloadHash: "abc123",
+			ExpectAuth:  "FAKE SignedHeaders=x-amz-date;x-custom, PayloadHash=abc123",
+		},
+		"no payload hash": {
+			ExpectAuth: "FAKE SignedHeaders=x-amz-date;x-custom, PayloadHash=",
+		},
+		"sign error": {
+			SignErr:         fmt.Errorf("missing credentials"),
+			ExpectErr:       "failed to sign request, missing credentials",
+			ExpectNotCalled: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+
+			stack.Build.Add(middleware.BuildMiddlewareFunc("computePayloadHash",
+				func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (
+					middleware.BuildOutput, middleware.Metadata, error,
+				) {
+					if len(c.PayloadHash) != 0 {
+						ctx = SetPayloadHash(ctx, c.PayloadHash)
+					}
+					return next.HandleBuild(ctx, in)
+				}), middleware.After)
+
+			signer := SignerFunc(func(ctx context.Context, req *Request, payloadHash string) error {
+				if c.SignErr != nil {
+					return c.SignErr
+				}
+				var signed []string
+				for k := range req.Header {
+					signed = append(signed, strings.ToLower(k))
+				}
+				sort.Strings(signed)
+				req.Header.Set("Authorization", fmt.Sprintf("FAKE SignedHeaders=%s, PayloadHash=%s",
+					strings.Join(signed, ";"), payloadHash))
+				return nil
+			})
+			if err := AddSigningMiddleware(stack, signer); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			// Headers added by Finalize middleware added afterwards, before
+			// the signing middleware, must be present when signed.
+			stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("addHeaders",
+				func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+					middleware.FinalizeOutput, middleware.Metadata, error,
+				) {
+					req := in.Request.(*Request)
+					req.Header.Set("X-Amz-Date", "20220101T000000Z")
+					req.Header.Set("X-Custom", "value")
+					return next.HandleFinalize(ctx, in)
+				}), middleware.Before)
+
+			var called bool
+			var auth string
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				called = true
+				auth = input.(*Request).Header.Get("Authorization")
+				return &Response{Response: &http.Response{StatusCode: 200}}, middleware.Metadata{}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect %q error, got %q", e, a)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := !c.ExpectNotCalled, called; e != a {
+				t.Errorf("expect handler called %v, got %v", e, a)
+			}
+			if e, a := c.ExpectAuth, auth; e != a {
+				t.Errorf("expect %q Authorization, got %q", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// SlowRequestDiagnostics is the diagnostic record of an operation whose
+// request exceeded the slow request threshold.
+type SlowRequestDiagnostics struct {
+	// The total duration of the operation's attempts.
+	Duration time.Duration
+
+	// The operation's request, with the sensitive headers removed, see
+	// SanitizeRequest.
+	Request *Request
+
+	// The operation's metadata, (e.g. the attempt count, and transport time).
+	Metadata middleware.Metadata
+
+	// The error the operation failed with, if any.
+	Err error
+}
+
+// AddSlowRequestDiagnosticsMiddleware adds the middleware to invoke the
+// callback with a diagnostic record of operations taking longer than the
+// threshold to the front of the stack's Finalize step, so that the duration
+// includes all attempts of the operation. Nothing is captured for operations
+// completing within the threshold.
+func AddSlowRequestDiagnosticsMiddleware(
+	stack *middleware.Stack, threshold time.Duration, fn func(context.Context, SlowRequestDiagnostics),
+) error {
+	return stack.Finalize.Add(&slowRequestDiagnostics{
+		threshold: threshold,
+		fn:        fn,
+	}, middleware.Before)
+}
+
+type slowRequestDiagnostics struct {
+	threshold time.Duration
+	fn        func(context.Context, SlowRequestDiagnostics)
+}
+
+// ID returns the middleware identifier.
+func (*slowRequestDiagnostics) ID() string { return "SlowRequestDiagnostics" }
+
+// HandleFinalize measures the duration of the next handler, and invokes the
+// callback if the duration exceeds the threshold.
+func (m *slowRequestDiagnostics) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	start := timeNow()
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	duration := timeNow().Sub(start)
+
+	if duration <= m.threshold {
+		return out, metadata, err
+	}
+
+	m.fn(ctx, SlowRequestDiagnostics{
+		Duration: duration,
+		Request:  SanitizeRequest(req, DefaultSensitiveHeaders...),
+		Metadata: metadata,
+		Err:      err,
+	})
+
+	return out, metadata, err
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSlowRequestDiagnosticsMiddleware(t *testing.T) {
+	origTimeNow := timeNow
+	defer func() { timeNow = origTimeNow }()
+
+	cases := map[string]struct {
+		Latency       time.Duration
+		ExpectCapture bool
+	}{
+		"fast": {
+			Latency: 50 * time.Millisecond,
+		},
+		"at threshold": {
+			Latency: 100 * time.Millisecond,
+		},
+		"slow": {
+			Latency:       2 * time.Second,
+			ExpectCapture: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+			timeNow = func() time.Time { return now }
+
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					middleware.SerializeOutput, middleware.Metadata, error,
+				) {
+					req := in.Request.(*Request)
+					req.URL.Path = "/slow"
+					req.Header.Set("Authorization", "secret")
+					req.Header.Set("X-Custom", "value")
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+
+			var captured []SlowRequestDiagnostics
+			err := AddSlowRequestDiagnosticsMiddleware(stack, 100*time.Millisecond,
+				func(ctx context.Context, d SlowRequestDiagnostics) {
+					captured = append(captured, d)
+				})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				now = now.Add(c.Latency)
+				var metadata middleware.Metadata
+				metadata.Set("attempts", 2)
+				return &Response{}, metadata, nil
+			})
+
+			if _, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{}); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if !c.ExpectCapture {
+				if len(captured) != 0 {
+					t.Fatalf("expect no capture, got %v", captured)
+				}
+				return
+			}
+
+			if e, a := 1, len(captured); e != a {
+				t.Fatalf("expect %v captures, got %v", e, a)
+			}
+			d := captured[0]
+			if e, a := c.Latency, d.Duration; e != a {
+				t.Errorf("expect %v duration, got %v", e, a)
+			}
+			if e, a := "/slow", d.Request.URL.Path; e != a {
+				t.Errorf("expect %v path, got %v", e, a)
+			}
+			if e, a := "value", d.Request.Header.Get("X-Custom"); e != a {
+				t.Errorf("expect %v header, got %v", e, a)
+			}
+			if v := d.Request.Header.Get("Authorization"); len(v) != 0 {
+				t.Errorf("expect no Authorization header, got %v", v)
+			}
+			if e, a := 2, d.Metadata.Get("attempts"); e != a {
+				t.Errorf("expect %v attempts metadata, got %v", e, a)
+			}
+		})
+	}
+}
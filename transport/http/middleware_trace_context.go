@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+const (
+	traceParentHeader = "Traceparent"
+	traceStateHeader  = "Tracestate"
+
+	traceContextVersion = "00"
+)
+
+// TraceContext provides the W3C trace context of the span a request is being
+// made within. See https://www.w3.org/TR/trace-context/.
+type TraceContext struct {
+	// The ID of the whole trace. A trace ID of all zero bytes is invalid.
+	TraceID [16]byte
+
+	// The ID of the caller's span, the parent of the request. A span ID of
+	// all zero bytes is invalid.
+	SpanID [8]byte
+
+	// If the caller may have recorded the trace.
+	Sampled bool
+
+	// The vendor specific trace state, in the tracestate header format. Optional.
+	TraceState string
+}
+
+// IsValid returns if the trace context has valid non-zero trace and span IDs.
+func (c TraceContext) IsValid() bool {
+	return c.TraceID != [16]byte{} && c.SpanID != [8]byte{}
+}
+
+// TraceParent returns the trace context formatted as a traceparent header
+// value.
+func (c TraceContext) TraceParent() string {
+	var flags byte
+	if c.Sampled {
+		flags |= 0x01
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%02x", traceContextVersion,
+		hex.EncodeToString(c.TraceID[:]), hex.EncodeToString(c.SpanID[:]), flags)
+}
+
+// TraceContextCarrier provides the interface for retrieving the trace context
+// of the current span from the Context. Allows integration with any tracing
+// implementation, without depending on a specific vendor.
+type TraceContextCarrier interface {
+	// TraceContext returns the trace context of the current span, and if
+	// there is one.
+	TraceContext(ctx context.Context) (TraceContext, bool)
+}
+
+// TraceContextCarrierFunc provides a wrapper around a function to be used as
+// a TraceContextCarrier.
+type TraceContextCarrierFunc func(context.Context) (TraceContext, bool)
+
+// TraceContext invokes the wrapped function.
+func (fn TraceContextCarrierFunc) TraceContext(ctx context.Context) (TraceContext, bool) {
+	return fn(ctx)
+}
+
+// AddTraceContextMiddleware adds the middleware to inject the W3C trace
+// context headers to the stack's Build step. The trace context is retrieved
+// from the carrier.
+func AddTraceContextMiddleware(stack *middleware.Stack, carrier TraceContextCarrier) error {
+	return stack.Build.Add(&traceContext{carrier: carrier}, middleware.After)
+}
+
+type traceContext struct {
+	carrier TraceContextCarrier
+}
+
+// ID returns the middleware identifier.
+func (*traceContext) ID() string { return "TraceContext" }
+
+// HandleBuild sets the traceparent and tracestate headers on the request if
+// the context has a valid trace context. Does nothing otherwise.
+func (m *traceContext) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	tc, ok := m.carrier.TraceContext(ctx)
+	if !ok || !tc.IsValid() {
+		return next.HandleBuild(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	req.Header.Set(traceParentHeader, tc.TraceParent())
+	if len(tc.TraceState) != 0 {
+		req.Header.Set(traceStateHeader, tc.TraceState)
+	} else {
+		req.Header.Del(traceStateHeader)
+	}
+
+	return next.HandleBuild(ctx, in)
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+type traceContextKey struct{}
+
+func TestTraceContextMiddleware(t *testing.T) {
+	carrier := TraceContextCarrierFunc(func(ctx context.Context) (TraceContext, bool) {
+		tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+		return tc, ok
+	})
+
+	cases := map[string]struct {
+		Context      context.Context
+		ExpectHeader http.Header
+	}{
+		"no trace context": {
+			Context:      context.Background(),
+			ExpectHeader: http.Header{},
+		},
+		"invalid trace context": {
+			Context:      context.WithValue(context.Background(), traceContextKey{}, TraceContext{}),
+			ExpectHeader: http.Header{},
+		},
+		"sampled trace context": {
+			Context: context.WithValue(context.Background(), traceContextKey{}, TraceContext{
+				TraceID: [16]byte{
+					0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6,
+					0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36,
+				},
+				SpanID:     [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+				Sampled:    true,
+				TraceState: "congo=t61rcWkgMzE",
+			}),
+			ExpectHeader: http.Header{
+				"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+				"Tracestate":  []string{"congo=t61rcWkgMzE"},
+			},
+		},
+		"not sampled trace context": {
+			Context: context.WithValue(context.Background(), traceContextKey{}, TraceContext{
+				TraceID: [16]byte{0x01},
+				SpanID:  [8]byte{0x02},
+			}),
+			ExpectHeader: http.Header{
+				"Traceparent": []string{"00-01000000000000000000000000000000-0200000000000000-00"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &traceContext{carrier: carrier}
+			_, _, err := m.HandleBuild(c.Context,
+				middleware.BuildInput{Request: NewStackRequest()},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					req := in.Request.(*Request)
+					if diff := cmp.Diff(c.ExpectHeader, req.Header); len(diff) != 0 {
+						t.Errorf("expect header to match\n%s", diff)
+					}
+					return out, metadata, err
+				}))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// PayloadEncoder encodes an operation's payload member value, and provides
+// the Content-Type of the encoded payload.
+type PayloadEncoder struct {
+	// The Content-Type of the encoded payload, (e.g. application/json).
+	ContentType string
+
+	// Returns the reader of the encoded payload.
+	Encode func(v interface{}) (io.Reader, error)
+}
+
+// PayloadEncoders selects the encoder of an operation's payload member by the
+// type of the member's value, (e.g. the members of a union payload).
+//
+// Blob, string, and io.Reader payloads are encoded as is, with the
+// application/octet-stream, and text/plain Content-Type, unless an encoder is
+// registered for the type.
+type PayloadEncoders struct {
+	encoders map[reflect.Type]PayloadEncoder
+}
+
+// NewPayloadEncoders returns an initialized PayloadEncoders without any
+// registered encoders.
+func NewPayloadEncoders() *PayloadEncoders {
+	return &PayloadEncoders{
+		encoders: map[reflect.Type]PayloadEncoder{},
+	}
+}
+
+// Register registers the encoder for payload values of the same type as v,
+// (e.g. (*types.Structure)(nil)).
+func (e *PayloadEncoders) Register(v interface{}, encoder PayloadEncoder) *PayloadEncoders {
+	e.encoders[reflect.TypeOf(v)] = encoder
+	return e
+}
+
+// Encoder returns the encoder for the payload value, and if an encoder was
+// found.
+func (e *PayloadEncoders) Encoder(v interface{}) (PayloadEncoder, bool) {
+	if encoder, ok := e.encoders[reflect.TypeOf(v)]; ok {
+		return encoder, true
+	}
+
+	switch v.(type) {
+	case []byte:
+		return PayloadEncoder{
+			ContentType: "application/octet-stream",
+			Encode: func(v interface{}) (io.Reader, error) {
+				return bytes.NewReader(v.([]byte)), nil
+			},
+		}, true
+	case string:
+		return PayloadEncoder{
+			ContentType: "text/plain",
+			Encode: func(v interface{}) (io.Reader, error) {
+				return strings.NewReader(v.(string)), nil
+			},
+		}, true
+	case io.Reader:
+		return PayloadEncoder{
+			ContentType: "application/octet-stream",
+			Encode: func(v interface{}) (io.Reader, error) {
+				return v.(io.Reader), nil
+			},
+		}, true
+	default:
+		return PayloadEncoder{}, false
+	}
+}
+
+// SetPayload returns a clone of the request with the stream set to the
+// payload value encoded by the encoder selected for the value's type. The
+// request's Content-Type header is set to the encoder's Content-Type, if the
+// header is not already set, (e.g. by a member with the mediaType trait).
+//
+// Returns an error if there is no encoder for the value's type.
+func SetPayload(req *Request, v interface{}, encoders *PayloadEncoders) (*Request, error) {
+	encoder, ok := encoders.Encoder(v)
+	if !ok {
+		return req, fmt.Errorf("no payload encoder for type %T", v)
+	}
+
+	payload, err := encoder.Encode(v)
+	if err != nil {
+		return req, fmt.Errorf("failed to encode %T payload, %w", v, err)
+	}
+
+	rc, err := req.SetStream(payload)
+	if err != nil {
+		return req, err
+	}
+
+	if len(rc.Header.Get("Content-Type")) == 0 && len(encoder.ContentType) != 0 {
+		rc.Header.Set("Content-Type", encoder.ContentType)
+	}
+
+	return rc, nil
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSetPayload(t *testing.T) {
+	type structPayload struct {
+		Name string
+	}
+	type unknownPayload struct{}
+
+	encoders := NewPayloadEncoders().
+		Register((*structPayload)(nil), PayloadEncoder{
+			ContentType: "application/json",
+			Encode: func(v interface{}) (io.Reader, error) {
+				b, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+				return strings.NewReader(string(b)), nil
+			},
+		})
+
+	cases := map[string]struct {
+		Header            http.Header
+		Value             interface{}
+		ExpectContentType string
+		ExpectBody        string
+		ExpectErr         bool
+	}{
+		"structure": {
+			Header:            http.Header{},
+			Value:             &structPayload{Name: "abc"},
+			ExpectContentType: "application/json",
+			ExpectBody:        `{"Name":"abc"}`,
+		},
+		"blob": {
+			Header:            http.Header{},
+			Value:             []byte("raw bytes"),
+			ExpectContentType: "application/octet-stream",
+			ExpectBody:        "raw bytes",
+		},
+		"stream": {
+			Header:            http.Header{},
+			Value:             strings.NewReader("streamed"),
+			ExpectContentType: "application/octet-stream",
+			ExpectBody:        "streamed",
+		},
+		"string": {
+			Header:            http.Header{},
+			Value:             "text",
+			ExpectContentType: "text/plain",
+			ExpectBody:        "text",
+		},
+		"media type set": {
+			Header:            http.Header{"Content-Type": []string{"image/png"}},
+			Value:             []byte("png"),
+			ExpectContentType: "image/png",
+			ExpectBody:        "png",
+		},
+		"unknown type": {
+			Header:    http.Header{},
+			Value:     &unknownPayload{},
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.Header = c.Header
+
+			req, err := SetPayload(req, c.Value, encoders)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectContentType, req.Header.Get("Content-Type"); e != a {
+				t.Errorf("expect %v content type, got %v", e, a)
+			}
+			body, err := ioutil.ReadAll(req.GetStream())
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ExpectBody, string(body); e != a {
+				t.Errorf("expect %v body, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// QueryBuilder accumulates the query parameters of a request. Keys may be
+// repeated. Each modification replaces the raw query of the request's URL
+// with the parameters encoded in a deterministic order, sorted by key, with
+// the values of a key in the order they were added, so that the query of the request is stable for signing, and is
+// included in the request when it is built, see Build.
+type QueryBuilder struct {
+	url    *url.URL
+	values url.Values
+}
+
+// Query returns a builder of the request's query parameters, initialized
+// from the raw query of the request's URL. Malformed pairs of the existing
+// query are dropped when the query is next modified. The builder modifies the
+// URL of the request it was returned for, not of clones of the request.
+func (r *Request) Query() *QueryBuilder {
+	if r.URL == nil {
+		r.URL = &url.URL{}
+	}
+	values, _ := url.ParseQuery(r.URL.RawQuery)
+	if values == nil {
+		values = url.Values{}
+	}
+	return &QueryBuilder{url: r.URL, values: values}
+}
+
+// Add adds the value to the values of the key.
+func (b *QueryBuilder) Add(key, value string) {
+	b.values.Add(key, value)
+	b.update()
+}
+
+// Set replaces the values of the key with the value.
+func (b *QueryBuilder) Set(key, value string) {
+	b.values.Set(key, value)
+	b.update()
+}
+
+// Get returns the first value of the key, or empty string if the key is not
+// set.
+func (b *QueryBuilder) Get(key string) string {
+	return b.values.Get(key)
+}
+
+// Values returns a copy of the values of the key.
+func (b *QueryBuilder) Values(key string) []string {
+	return append([]string(nil), b.values[key]...)
+}
+
+// Has returns if the key is set.
+func (b *QueryBuilder) Has(key string) bool {
+	_, ok := b.values[key]
+	return ok
+}
+
+// Del removes the key and its values.
+func (b *QueryBuilder) Del(key string) {
+	b.values.Del(key)
+	b.update()
+}
+
+// Encode returns the query parameters encoded as a raw query, sorted by key.
+// The values of a repeated key are encoded in the order they were added, as
+// the order may be significant to the service. Keys and values are percent encoded per RFC 3986, with spaces
+// encoded as %20.
+func (b *QueryBuilder) Encode() string {
+	keys := make([]string, 0, len(b.values))
+	for k := range b.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		ek := escapeQuery(k)
+		for _, v := range b.values[k] {
+			if sb.Len() != 0 {
+				sb.WriteByte('&')
+			}
+			sb.WriteString(ek)
+			sb.WriteByte('=')
+			sb.WriteString(escapeQuery(v))
+		}
+	}
+	return sb.String()
+}
+
+func (b *QueryBuilder) update() {
+	b.url.RawQuery = b.Encode()
+}
+
+// escapeQuery percent encodes all but the RFC 3986 unreserved characters.
+func escapeQuery(v string) string {
+	return strings.ReplaceAll(url.QueryEscape(v), "+", "%20")
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRequestQuery(t *testing.T) {
+	cases := map[string]struct {
+		RawQuery string
+		Modify   func(*QueryBuilder)
+		Expect   string
+	}{
+		"sorted keys": {
+			Modify: func(q *QueryBuilder) {
+				q.Set("Version", "2012-11-05")
+				q.Set("Action", "SendMessage")
+				q.Set("MessageBody", "hello")
+			},
+			Expect: "Action=SendMessage&MessageBody=hello&Version=2012-11-05",
+		},
+		"repeated keys in insertion order": {
+			Modify: func(q *QueryBuilder) {
+				q.Add("tag", "b")
+				q.Add("tag", "a")
+				q.Add("id", "1")
+			},
+			Expect: "id=1&tag=b&tag=a",
+		},
+		"existing repeated keys": {
+			RawQuery: "list=3&list=1",
+			Modify: func(q *QueryBuilder) {
+				q.Add("list", "2")
+			},
+			Expect: "list=3&list=1&list=2",
+		},
+		"escaped": {
+			Modify: func(q *QueryBuilder) {
+				q.Set("message body", "a b+c/d~e_f.g-h&i=j")
+				q.Set("empty", "")
+				q.Set("unicode", "café")
+			},
+			Expect: "empty=&message%20body=a%20b%2Bc%2Fd~e_f.g-h%26i%3Dj&unicode=caf%C3%A9",
+		},
+		"existing query": {
+			RawQuery: "z=1&a=2",
+			Modify: func(q *QueryBuilder) {
+				q.Add("m", "3")
+			},
+			Expect: "a=2&m=3&z=1",
+		},
+		"delete": {
+			RawQuery: "a=1&b=2",
+			Modify: func(q *QueryBuilder) {
+				q.Del("a")
+			},
+			Expect: "b=2",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.URL.Scheme = "https"
+			req.URL.Host = "example.amazonaws.com"
+			req.URL.RawQuery = c.RawQuery
+
+			c.Modify(req.Query())
+
+			if e, a := c.Expect, req.URL.RawQuery; e != a {
+				t.Errorf("expect %q raw query, got %q", e, a)
+			}
+			if e, a := c.Expect, req.Build(context.Background()).URL.RawQuery; e != a {
+				t.Errorf("expect %q built raw query, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestRequestQuery_Values(t *testing.T) {
+	req := NewStackRequest().(*Request)
+	req.URL.RawQuery = "tag=a&tag=b&id=1"
+
+	q := req.Query()
+	if e, a := "1", q.Get("id"); e != a {
+		t.Errorf("expect %v value, got %v", e, a)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, q.Values("tag")); len(diff) != 0 {
+		t.Errorf("expect values to match\n%s", diff)
+	}
+	if q.Has("missing") {
+		t.Errorf("expect missing key not set")
+	}
+
+	clone := req.Clone()
+	clone.Query().Set("id", "2")
+	if e, a := "1", req.Query().Get("id"); e != a {
+		t.Errorf("expect original query not modified, got %v", a)
+	}
+}
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ByteRange is an inclusive range of bytes of a resource, as used by the
+// Range header. An End less than zero is an open ended range, from Start to
+// the end of the resource.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// String returns the range formatted as the value of a Range header, (e.g.
+// "bytes=0-1023", "bytes=1024-").
+func (r ByteRange) String() string {
+	if r.End < 0 {
+		return fmt.Sprintf("bytes=%d-", r.Start)
+	}
+	return fmt.Sprintf("bytes=%d-%d", r.Start, r.End)
+}
+
+// SetRange sets the Range header of the request to the range of bytes from
+// start to end inclusive. An end less than zero requests the bytes from
+// start to the end of the resource.
+func SetRange(req *Request, start, end int64) error {
+	if start < 0 {
+		return fmt.Errorf("range start must not be negative, got %d", start)
+	}
+	if end >= 0 && end < start {
+		return fmt.Errorf("range end %d must not be less than start %d", end, start)
+	}
+
+	req.Header.Set("Range", ByteRange{Start: start, End: end}.String())
+	return nil
+}
+
+// ParseContentRange parses the value of a Content-Range header, (e.g.
+// "bytes 0-1023/4096"). The size is -1 if the resource's size is unknown,
+// (e.g. "bytes 0-1023/*"). For unsatisfied ranges, (e.g. "bytes */4096"),
+// the returned range is {-1, -1}.
+func ParseContentRange(v string) (r ByteRange, size int64, err error) {
+	const unit = "bytes "
+	if !strings.HasPrefix(v, unit) {
+		return r, 0, fmt.Errorf("unsupported content range unit, %q", v)
+	}
+
+	parts := strings.SplitN(v[len(unit):], "/", 2)
+	if len(parts) != 2 {
+		return r, 0, fmt.Errorf("invalid content range, %q", v)
+	}
+
+	size = -1
+	if parts[1] != "*" {
+		if size, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return r, 0, fmt.Errorf("invalid content range size, %q, %w", v, err)
+		}
+	}
+
+	if parts[0] == "*" {
+		return ByteRange{Start: -1, End: -1}, size, nil
+	}
+
+	bounds := strings.SplitN(parts[0], "-", 2)
+	if len(bounds) != 2 {
+		return r, 0, fmt.Errorf("invalid content range, %q", v)
+	}
+	if r.Start, err = strconv.ParseInt(bounds[0], 10, 64); err != nil {
+		return r, 0, fmt.Errorf("invalid content range start, %q, %w", v, err)
+	}
+	if r.End, err = strconv.ParseInt(bounds[1], 10, 64); err != nil {
+		return r, 0, fmt.Errorf("invalid content range end, %q, %w", v, err)
+	}
+
+	return r, size, nil
+}
+
+// RangeNotSatisfiedError is the error returned when the service responds
+// with 416 Range Not Satisfiable, (e.g. the range starts after the end of
+// the resource).
+type RangeNotSatisfiedError struct {
+	Range ByteRange
+
+	// The size of the resource, if returned by the service, otherwise -1.
+	Size int64
+}
+
+// RetryableError returns that the error is not retryable, as the range will
+// not be satisfied by subsequent attempts.
+func (*RangeNotSatisfiedError) RetryableError() bool { return false }
+
+func (e *RangeNotSatisfiedError) Error() string {
+	if e.Size < 0 {
+		return fmt.Sprintf("range not satisfied, %s", e.Range)
+	}
+	return fmt.Sprintf("range not satisfied, %s, resource size %d", e.Range, e.Size)
+}
+
+type byteRangeKey struct{}
+
+// SetByteRange returns a context with the range of bytes the operation's
+// request will be made for, by the middleware added with
+// AddByteRangeMiddleware.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetByteRange(ctx context.Context, r ByteRange) context.Context {
+	return middleware.WithStackValue(ctx, byteRangeKey{}, r)
+}
+
+// GetByteRange returns the range of bytes of the operation's request, and if
+// it was set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetByteRange(ctx context.Context) (ByteRange, bool) {
+	v, ok := middleware.GetStackValue(ctx, byteRangeKey{}).(ByteRange)
+	return v, ok
+}
+
+// AddByteRangeMiddleware adds the middleware setting the Range header of the
+// request from the context's byte range, see SetByteRange, to the end of the
+// stack's Serialize step, and the middleware validating the response is the
+// requested range to the end of the Deserialize step.
+//
+// A 416 response is returned as a RangeNotSatisfiedError. A successful
+// response that is not 206 Partial Content, or whose Content-Range does not
+// start at the requested offset, is returned as an error. Operations without
+// a byte range are not modified.
+func AddByteRangeMiddleware(stack *middleware.Stack) error {
+	if err := stack.Serialize.Add(&setByteRange{}, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s serialize middleware, %w",
+			(*setByteRange)(nil).ID(), err)
+	}
+	if err := stack.Deserialize.Add(&validateByteRange{}, middleware.After); err != nil {
+		return fmt.Errorf("failed to add %s deserialize middleware, %w",
+			(*validateByteRange)(nil).ID(), err)
+	}
+	return nil
+}
+
+type setByteRange struct{}
+
+// ID returns the middleware identifier.
+func (*setByteRange) ID() string { return "SetByteRange" }
+
+// HandleSerialize sets the Range header of the request.
+func (*setByteRange) HandleSerialize(
+	ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+) (
+	out middleware.SerializeOutput, metadata middleware.Metadata, err error,
+) {
+	r, ok := GetByteRange(ctx)
+	if !ok {
+		return next.HandleSerialize(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	if err := SetRange(req, r.Start, r.End); err != nil {
+		return out, metadata, err
+	}
+
+	return next.HandleSerialize(ctx, in)
+}
+
+type validateByteRange struct{}
+
+// ID returns the middleware identifier.
+func (*validateByteRange) ID() string { return "ValidateByteRange" }
+
+// HandleDeserialize validates the raw response is the requested range.
+func (*validateByteRange) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	r, ok := GetByteRange(ctx)
+	if !ok {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+
+	switch {
+	case resp.StatusCode == 416:
+		rangeErr := &RangeNotSatisfiedError{Range: r, Size: -1}
+		if _, size, err := ParseContentRange(resp.Header.Get("Content-Range")); err == nil {
+			rangeErr.Size = size
+		}
+		return out, metadata, &ResponseError{Response: resp, Err: rangeErr}
+
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		// Left to the operation's error deserializer.
+		return out, metadata, err
+
+	case resp.StatusCode != 206:
+		return out, metadata, &ResponseError{
+			Response: resp,
+			Err:      fmt.Errorf("expect 206 partial content response for %s, got %d", r, resp.StatusCode),
+		}
+	}
+
+	contentRange, _, err := ParseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return out, metadata, &ResponseError{Response: resp, Err: err}
+	}
+	if contentRange.Start != r.Start || (r.End >= 0 && contentRange.End > r.End) {
+		return out, metadata, &ResponseError{
+			Response: resp,
+			Err: fmt.Errorf("response content range bytes %d-%d does not match %s",
+				contentRange.Start, contentRange.End, r),
+		}
+	}
+
+	return out, metadata, nil
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSetRange(t *testing.T) {
+	cases := map[string]struct {
+		Start, End int64
+		Expect     string
+		ExpectErr  bool
+	}{
+		"closed range": {
+			Start: 0, End: 1023,
+			Expect: "bytes=0-1023",
+		},
+		"open range": {
+			Start: 1024, End: -1,
+			Expect: "bytes=1024-",
+		},
+		"single byte": {
+			Start: 5, End: 5,
+			Expect: "bytes=5-5",
+		},
+		"negative start": {
+			Start: -1, End: 5,
+			ExpectErr: true,
+		},
+		"end before start": {
+			Start: 10, End: 5,
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			err := SetRange(req, c.Start, c.End)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Expect, req.Header.Get("Range"); e != a {
+				t.Errorf("expect %v range, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestByteRangeMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Range         ByteRange
+		StatusCode    int
+		ContentRange  string
+		ExpectErr     string
+		ExpectSize    int64
+		ExpectNotSat  bool
+		ExpectRequest string
+	}{
+		"partial content": {
+			Range:         ByteRange{Start: 100, End: 199},
+			StatusCode:    206,
+			ContentRange:  "bytes 100-199/1000",
+			ExpectRequest: "bytes=100-199",
+		},
+		"open range": {
+			Range:         ByteRange{Start: 900, End: -1},
+			StatusCode:    206,
+			ContentRange:  "bytes 900-999/1000",
+			ExpectRequest: "bytes=900-",
+		},
+		"range not satisfiable": {
+			Range:         ByteRange{Start: 2000, End: -1},
+			StatusCode:    416,
+			ContentRange:  "bytes */1000",
+			ExpectRequest: "bytes=2000-",
+			ExpectNotSat:  true,
+			ExpectSize:    1000,
+		},
+		"range not satisfiable unknown size": {
+			Range:         ByteRange{Start: 2000, End: -1},
+			StatusCode:    416,
+			ExpectRequest: "bytes=2000-",
+			ExpectNotSat:  true,
+			ExpectSize:    -1,
+		},
+		"range ignored": {
+			Range:         ByteRange{Start: 100, End: 199},
+			StatusCode:    200,
+			ExpectRequest: "bytes=100-199",
+			ExpectErr:     "expect 206 partial content",
+		},
+		"mismatched content range": {
+			Range:         ByteRange{Start: 100, End: 199},
+			StatusCode:    206,
+			ContentRange:  "bytes 0-99/1000",
+			ExpectRequest: "bytes=100-199",
+			ExpectErr:     "does not match",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			if err := AddByteRangeMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var sentRange string
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				sentRange = input.(*Request).Header.Get("Range")
+				header := http.Header{}
+				if len(c.ContentRange) != 0 {
+					header.Set("Content-Range", c.ContentRange)
+				}
+				return &Response{
+					Response: &http.Response{
+						StatusCode: c.StatusCode,
+						Header:     header,
+					},
+				}, middleware.Metadata{}, nil
+			})
+
+			ctx := SetByteRange(context.Background(), c.Range)
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(ctx, struct{}{})
+
+			if e, a := c.ExpectRequest, sentRange; e != a {
+				t.Errorf("expect %v range header, got %v", e, a)
+			}
+
+			if c.ExpectNotSat {
+				var rangeErr *RangeNotSatisfiedError
+				if !errors.As(err, &rangeErr) {
+					t.Fatalf("expect %T error, got %v", rangeErr, err)
+				}
+				if e, a := c.Range, rangeErr.Range; e != a {
+					t.Errorf("expect %v range, got %v", e, a)
+				}
+				if e, a := c.ExpectSize, rangeErr.Size; e != a {
+					t.Errorf("expect %v size, got %v", e, a)
+				}
+				return
+			}
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 
+	smithyio "github.com/aws/smithy-go/io"
 	iointernal "github.com/aws/smithy-go/transport/http/internal/io"
 )
 
@@ -19,6 +21,7 @@ type Request struct {
 	stream           io.Reader
 	isStreamSeekable bool
 	streamStartPos   int64
+	streamFactory    func() (io.Reader, error)
 }
 
 // NewStackRequest returns an initialized request ready to be populated with the
@@ -44,6 +47,11 @@ func (r *Request) IsHTTPS() bool {
 
 // Clone returns a deep copy of the Request for the new context. A reference to
 // the Stream is copied, but the underlying stream is not copied.
+//
+// The clone's headers, URL, and trailers are independent of the original's.
+// Since the stream is shared, a seekable stream must be rewound, see
+// RewindStream, before each clone reads it. Only one of the requests sharing
+// a stream that is not seekable can read the stream.
 func (r *Request) Clone() *Request {
 	rc := *r
 	rc.Request = rc.Request.Clone(context.TODO())
@@ -91,15 +99,20 @@ func streamLength(stream io.Reader, seekable bool, startPos int64) (size int64,
 }
 
 // RewindStream will rewind the io.Reader to the relative start position if it
-// is an io.Seeker.
+// is an io.Seeker. If the request's stream was set with a stream factory, the
+// stream is replaced with a new stream from the factory instead.
 func (r *Request) RewindStream() error {
+	if r.streamFactory != nil {
+		return r.setStreamFromFactory()
+	}
+
 	// If there is no stream there is nothing to rewind.
 	if r.stream == nil {
 		return nil
 	}
 
 	if !r.isStreamSeekable {
-		return fmt.Errorf("request stream is not seekable")
+		return fmt.Errorf("request stream is not rewindable, stream is not seekable")
 	}
 	_, err := r.stream.(io.Seeker).Seek(r.streamStartPos, io.SeekStart)
 	return err
@@ -116,9 +129,21 @@ func (r *Request) IsStreamSeekable() bool {
 	return r.isStreamSeekable
 }
 
+// IsStreamRewindable returns whether the stream can be rewound, see
+// RewindStream. Streams are rewindable if they are seekable, or were set with
+// a stream factory. Streams set with SetStreamWithRewindBuffer may fail to
+// rewind once more than the buffer's size was read.
+func (r *Request) IsStreamRewindable() bool {
+	return r.isStreamSeekable || r.streamFactory != nil
+}
+
 // SetStream returns a clone of the request with the stream set to the provided
 // reader. May return an error if the provided reader is seekable but returns
 // an error.
+//
+// The stream can be rewound, see RewindStream, if the reader is an io.Seeker,
+// or an in-memory *bytes.Buffer. The buffer's unread contents are read without
+// draining the buffer, so that the stream can be read again.
 func (r *Request) SetStream(reader io.Reader) (rc *Request, err error) {
 	rc = r.Clone()
 
@@ -128,6 +153,10 @@ func (r *Request) SetStream(reader io.Reader) (rc *Request, err error) {
 
 	var isStreamSeekable bool
 	var streamStartPos int64
+	if buf, ok := reader.(*bytes.Buffer); ok && buf != nil && buf.Len() != 0 {
+		reader = bytes.NewReader(buf.Bytes())
+	}
+
 	switch v := reader.(type) {
 	case io.Seeker:
 		n, err := v.Seek(0, io.SeekCurrent)
@@ -151,19 +180,86 @@ func (r *Request) SetStream(reader io.Reader) (rc *Request, err error) {
 	rc.stream = reader
 	rc.isStreamSeekable = isStreamSeekable
 	rc.streamStartPos = streamStartPos
+	rc.streamFactory = nil
 
 	return rc, err
 }
 
+// SetStreamFactory returns a clone of the request with the stream set to a
+// new stream from the factory. Each time the request's stream is rewound,
+// (e.g. for a retry attempt), the stream is replaced with a new stream from
+// the factory, instead of seeking the stream. This allows generated streams
+// that cannot be seeked to be retried without buffering the stream.
+//
+// Returns an error if the factory fails to create the stream.
+func (r *Request) SetStreamFactory(factory func() (io.Reader, error)) (*Request, error) {
+	rc := r.Clone()
+	rc.streamFactory = factory
+	if err := rc.setStreamFromFactory(); err != nil {
+		return r, err
+	}
+	return rc, nil
+}
+
+// SetStreamWithRewindBuffer returns a clone of the request with the stream set
+// to the reader, retaining the first size bytes read in memory, so that the
+// stream can be rewound for a retry attempt if no more than size bytes were
+// sent. Once more bytes were read, rewinding the stream fails with a
+// smithyio.RewindBufferExceededError, and the request is not retried.
+//
+// Use for streams that are not seekable, and too large to buffer in full.
+func (r *Request) SetStreamWithRewindBuffer(reader io.Reader, size int) (*Request, error) {
+	buf := smithyio.NewRewindBuffer(reader, size)
+	return r.SetStreamFactory(func() (io.Reader, error) {
+		if err := buf.Rewind(); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	})
+}
+
+func (r *Request) setStreamFromFactory() error {
+	stream, err := r.streamFactory()
+	if err != nil {
+		return fmt.Errorf("failed to create request stream, %w", err)
+	}
+	if stream == http.NoBody {
+		stream = nil
+	}
+
+	r.stream = stream
+	r.isStreamSeekable = false
+	r.streamStartPos = 0
+	return nil
+}
+
 // Build returns a build standard HTTP request value from the Smithy request.
 // The request's stream is wrapped in a safe container that allows it to be
 // reused for subsequent attempts.
+//
+// If the request has a stream, and its ContentLength is 0, the length of the
+// stream is used, or -1 if the stream's length cannot be determined, so that
+// the stream is sent, instead of being dropped. Requests created with
+// NewStackRequest default to a ContentLength of -1, and are not modified.
 func (r *Request) Build(ctx context.Context) *http.Request {
 	req := r.Request.Clone(ctx)
 
 	if r.stream == nil && req.ContentLength == -1 {
 		req.ContentLength = 0
 	}
+	if r.stream != nil && req.ContentLength == 0 {
+		if n, ok, err := r.StreamLength(); err == nil && ok {
+			req.ContentLength = n
+		} else {
+			req.ContentLength = -1
+		}
+	}
+
+	// Streams that set trailer values as they are read must update the
+	// trailers of the built request, not of the cloned source request.
+	if ts, ok := r.stream.(trailerSetter); ok && req.Trailer != nil {
+		ts.setTrailer(req.Trailer)
+	}
 
 	switch stream := r.stream.(type) {
 	case *io.PipeReader:
@@ -173,7 +269,9 @@ func (r *Request) Build(ctx context.Context) *http.Request {
 		// HTTP Client Request must only have a non-nil body if the
 		// ContentLength is explicitly unknown (-1) or non-zero. The HTTP
 		// Client will interpret a non-nil body and ContentLength 0 as
-		// "unknown". This is unwanted behavior.
+		// "unknown". This is unwanted behavior. A ContentLength of 0 with a
+		// stream was resolved above, so only streams known to be empty are
+		// not sent.
 		if req.ContentLength != 0 && r.stream != nil {
 			req.Body = iointernal.NewSafeReadCloser(ioutil.NopCloser(stream))
 		}
@@ -3,15 +3,24 @@ package http
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
 )
 
+// unseekableBuffer is an in-memory stream of known length that is neither
+// seekable, nor a *bytes.Buffer.
+type unseekableBuffer struct {
+	*bytes.Buffer
+}
+
 func TestRequestRewindable(t *testing.T) {
 	cases := map[string]struct {
 		Stream    io.Reader
@@ -24,9 +33,12 @@ func TestRequestRewindable(t *testing.T) {
 			Stream: bytes.NewBuffer([]byte{}),
 			// ExpectErr: "stream is not seekable",
 		},
+		"buffer rewindable": {
+			Stream: bytes.NewBuffer([]byte("abc123")),
+		},
 		"not empty not rewindable": {
-			Stream:    bytes.NewBuffer([]byte("abc123")),
-			ExpectErr: "stream is not seekable",
+			Stream:    unseekableBuffer{bytes.NewBuffer([]byte("abc123"))},
+			ExpectErr: "stream is not rewindable",
 		},
 		"nil stream": {},
 	}
@@ -57,6 +69,128 @@ func TestRequestRewindable(t *testing.T) {
 	}
 }
 
+func TestRequestRewindStream(t *testing.T) {
+	cases := map[string]struct {
+		Stream func() io.Reader
+	}{
+		"bytes reader": {
+			Stream: func() io.Reader {
+				return bytes.NewReader([]byte("abc123"))
+			},
+		},
+		"bytes reader offset": {
+			Stream: func() io.Reader {
+				r := bytes.NewReader([]byte("xyzabc123"))
+				r.Seek(3, io.SeekStart)
+				return r
+			},
+		},
+		"bytes buffer": {
+			Stream: func() io.Reader {
+				return bytes.NewBuffer([]byte("abc123"))
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, err := NewStackRequest().(*Request).SetStream(c.Stream())
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			for i := 0; i < 3; i++ {
+				if err := req.RewindStream(); err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				b, err := ioutil.ReadAll(req.GetStream())
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if e, a := "abc123", string(b); e != a {
+					t.Errorf("expect %q on read %d, got %q", e, i, a)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestRewindStream_pipe(t *testing.T) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("abc123"))
+		pw.Close()
+	}()
+
+	req, err := NewStackRequest().(*Request).SetStream(pr)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if req.IsStreamSeekable() {
+		t.Errorf("expect pipe stream not seekable")
+	}
+
+	if _, err := ioutil.ReadAll(req.GetStream()); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	err = req.RewindStream()
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := "not rewindable", err.Error(); !strings.Contains(a, e) {
+		t.Errorf("expect error to contain %q, got %q", e, a)
+	}
+}
+
+func TestRequestClone(t *testing.T) {
+	req := NewStackRequest().(*Request)
+	req.Method = http.MethodPost
+	req.URL.Scheme = "https"
+	req.URL.Host = "example.amazonaws.com"
+	req.URL.Path = "/foo"
+	req.Header.Set("X-Foo", "bar")
+
+	req, err := req.SetStream(bytes.NewReader([]byte("abc123")))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	clone := req.Clone()
+	clone.Method = http.MethodPut
+	clone.URL.Path = "/bar"
+	clone.URL.RawQuery = "baz=qux"
+	clone.Header.Set("X-Foo", "modified")
+	clone.Header.Set("X-Clone", "true")
+
+	if e, a := http.MethodPost, req.Method; e != a {
+		t.Errorf("expect %v method, got %v", e, a)
+	}
+	if e, a := "https://example.amazonaws.com/foo", req.URL.String(); e != a {
+		t.Errorf("expect %v URL, got %v", e, a)
+	}
+	expectHeader := http.Header{"X-Foo": []string{"bar"}}
+	if e, a := expectHeader, req.Header; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v headers, got %v", e, a)
+	}
+
+	if e, a := req.GetStream(), clone.GetStream(); e != a {
+		t.Errorf("expect clone to share stream")
+	}
+	for _, r := range []*Request{req, clone} {
+		if err := r.RewindStream(); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		b, err := ioutil.ReadAll(r.GetStream())
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := "abc123", string(b); e != a {
+			t.Errorf("expect %q stream, got %q", e, a)
+		}
+	}
+}
+
 func TestRequestBuild_contentLength(t *testing.T) {
 	cases := []struct {
 		Request  *Request
@@ -96,6 +230,27 @@ func TestRequestBuild_contentLength(t *testing.T) {
 			},
 			Expected: 100,
 		},
+		{
+			Request: &Request{
+				Request: &http.Request{},
+				stream:  bytes.NewReader(make([]byte, 100)),
+			},
+			Expected: 100,
+		},
+		{
+			Request: &Request{
+				Request: &http.Request{},
+				stream:  ioutil.NopCloser(bytes.NewReader(make([]byte, 100))),
+			},
+			Expected: -1,
+		},
+		{
+			Request: &Request{
+				Request: &http.Request{},
+				stream:  bytes.NewReader(nil),
+			},
+			Expected: 0,
+		},
 	}
 
 	for i, tt := range cases {
@@ -105,10 +260,73 @@ func TestRequestBuild_contentLength(t *testing.T) {
 			if build.ContentLength != tt.Expected {
 				t.Errorf("expect %v, got %v", tt.Expected, build.ContentLength)
 			}
+			if e, a := tt.Request.stream != nil && build.ContentLength != 0, build.Body != nil; e != a {
+				t.Errorf("expect body %v, got %v", e, a)
+			}
 		})
 	}
 }
 
+func TestRequestBuild(t *testing.T) {
+	// ContentLength not set, length taken from the stream.
+	req := &Request{
+		Request: &http.Request{
+			URL:    &url.URL{},
+			Header: http.Header{},
+		},
+	}
+	req.Method = http.MethodPut
+	req.URL.Scheme = "https"
+	req.URL.Host = "example.amazonaws.com"
+	req.URL.Path = "/bucket/key"
+	req.URL.RawQuery = "versionId=abc"
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Add("X-Foo", "bar")
+	req.Header.Add("X-Foo", "baz")
+
+	req, err := req.SetStream(strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	build := req.Build(ctx)
+
+	if e, a := req.Method, build.Method; e != a {
+		t.Errorf("expect %v method, got %v", e, a)
+	}
+	if e, a := req.URL.String(), build.URL.String(); e != a {
+		t.Errorf("expect %v URL, got %v", e, a)
+	}
+	if e, a := req.Header, build.Header; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v headers, got %v", e, a)
+	}
+	if e, a := int64(11), build.ContentLength; e != a {
+		t.Errorf("expect %v content length, got %v", e, a)
+	}
+	if e, a := "value", build.Context().Value(ctxKey{}); e != a {
+		t.Errorf("expect built request to have context, got %v", a)
+	}
+
+	if build.Body == nil {
+		t.Fatalf("expect body, got none")
+	}
+	body, err := ioutil.ReadAll(build.Body)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "hello world", string(body); e != a {
+		t.Errorf("expect %q body, got %q", e, a)
+	}
+
+	build.Header.Set("X-Foo", "modified")
+	if e, a := "bar", req.Header.Get("X-Foo"); e != a {
+		t.Errorf("expect source request headers not modified, got %v", a)
+	}
+}
+
 func TestRequestSetStream(t *testing.T) {
 	cases := map[string]struct {
 		reader                 io.Reader
@@ -143,7 +361,7 @@ func TestRequestSetStream(t *testing.T) {
 			expectReqContentLength: -1,
 		},
 		"unseekable stream": {
-			reader:                 bytes.NewBuffer([]byte("abc123")),
+			reader:                 unseekableBuffer{bytes.NewBuffer([]byte("abc123"))},
 			expectContentLength:    6,
 			expectNilStream:        false,
 			expectNilBody:          false,
@@ -219,3 +437,41 @@ func TestRequestSetStream(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestSetStreamFactory(t *testing.T) {
+	var calls int
+	req, err := NewStackRequest().(*Request).SetStreamFactory(func() (io.Reader, error) {
+		calls++
+		return strings.NewReader("call " + strconv.Itoa(calls)), nil
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if i > 1 {
+			if err := req.RewindStream(); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		}
+
+		b, err := ioutil.ReadAll(req.Build(context.Background()).Body)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := "call "+strconv.Itoa(i), string(b); e != a {
+			t.Errorf("expect %v body, got %v", e, a)
+		}
+	}
+
+	if e, a := 2, calls; e != a {
+		t.Errorf("expect %v factory calls, got %v", e, a)
+	}
+
+	_, err = req.SetStreamFactory(func() (io.Reader, error) {
+		return nil, fmt.Errorf("factory error")
+	})
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
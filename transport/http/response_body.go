@@ -0,0 +1,72 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// BufferBody reads up to limit bytes of the response body into memory, and
+// returns the buffered bytes, and if the body was truncated to the limit.
+// Intended for error responses, so that multiple error decoders can inspect
+// the body, while bounding the memory used for large error bodies.
+//
+// The response's body is replaced with a reader over the buffered bytes,
+// followed by the unread remainder of the body, so that the body can still be
+// streamed. Closing the body closes the original body.
+//
+// Calling BufferBody again returns the previously buffered bytes without
+// reading the body. If the body was not truncated, the body is also reset to
+// read the buffered bytes from the start.
+func (r *Response) BufferBody(limit int64) (body []byte, truncated bool, err error) {
+	if limit < 0 {
+		return nil, false, fmt.Errorf("buffer limit must not be negative, %d", limit)
+	}
+
+	if b, ok := r.Body.(*bufferedBody); ok {
+		if !b.truncated {
+			b.Reader = bytes.NewReader(b.buf)
+		}
+		return b.buf, b.truncated, nil
+	}
+
+	if r.Body == nil {
+		return nil, false, nil
+	}
+
+	// Read one byte past the limit to determine if the body was truncated.
+	buf, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to buffer response body, %w", err)
+	}
+
+	b := &bufferedBody{
+		buf:    buf,
+		closer: r.Body,
+	}
+	if int64(len(buf)) > limit {
+		b.buf = buf[:limit]
+		b.truncated = true
+		b.Reader = io.MultiReader(bytes.NewReader(buf), r.Body)
+	} else {
+		b.Reader = bytes.NewReader(buf)
+	}
+	r.Body = b
+
+	return b.buf, b.truncated, nil
+}
+
+// bufferedBody is a response body whose leading bytes were buffered in
+// memory.
+type bufferedBody struct {
+	io.Reader
+	closer io.Closer
+
+	buf       []byte
+	truncated bool
+}
+
+func (b *bufferedBody) Close() error {
+	return b.closer.Close()
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type recordCloseBody struct {
+	*strings.Reader
+	closed bool
+}
+
+func (b *recordCloseBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestResponseBufferBody(t *testing.T) {
+	cases := map[string]struct {
+		Body            string
+		Limit           int64
+		ExpectBuffered  string
+		ExpectTruncated bool
+	}{
+		"within limit": {
+			Body:           `{"__type":"ValidationException"}`,
+			Limit:          1024,
+			ExpectBuffered: `{"__type":"ValidationException"}`,
+		},
+		"at limit": {
+			Body:           "abc123",
+			Limit:          6,
+			ExpectBuffered: "abc123",
+		},
+		"truncated": {
+			Body:            "abc123",
+			Limit:           3,
+			ExpectBuffered:  "abc",
+			ExpectTruncated: true,
+		},
+		"empty": {
+			Limit: 1024,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			body := &recordCloseBody{Reader: strings.NewReader(c.Body)}
+			resp := &Response{Response: &http.Response{StatusCode: 400, Body: body}}
+
+			for i := 0; i < 2; i++ {
+				buf, truncated, err := resp.BufferBody(c.Limit)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if e, a := c.ExpectBuffered, string(buf); e != a {
+					t.Errorf("expect %q buffered on call %d, got %q", e, i, a)
+				}
+				if e, a := c.ExpectTruncated, truncated; e != a {
+					t.Errorf("expect %v truncated on call %d, got %v", e, i, a)
+				}
+			}
+
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Body, string(b); e != a {
+				t.Errorf("expect %q body, got %q", e, a)
+			}
+
+			if err := resp.Body.Close(); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !body.closed {
+				t.Errorf("expect original body closed")
+			}
+		})
+	}
+}
+
+func TestResponseBufferBody_Rewind(t *testing.T) {
+	resp := &Response{Response: &http.Response{
+		Body: ioutil.NopCloser(strings.NewReader("abc123")),
+	}}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := resp.BufferBody(1024); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := "abc123", string(b); e != a {
+			t.Errorf("expect %q body on read %d, got %q", e, i, a)
+		}
+	}
+}
+
+func TestResponseBufferBody_NilBody(t *testing.T) {
+	resp := &Response{Response: &http.Response{}}
+
+	buf, truncated, err := resp.BufferBody(1024)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if len(buf) != 0 || truncated {
+		t.Errorf("expect nothing buffered, got %q, %v", buf, truncated)
+	}
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ResponseDecoder binds the components of an HTTP response to the fields of
+// an operation's output value using explicitly registered binding functions,
+// instead of reflection. Bindings are applied in the order they were
+// registered.
+//
+// Generated clients construct a ResponseDecoder per operation, and add it to
+// the operation's stack with AddResponseDecoderMiddleware.
+type ResponseDecoder struct {
+	newOutput func() interface{}
+	bindings  []responseBinding
+}
+
+type responseBinding func(resp *Response, output interface{}) error
+
+// NewResponseDecoder returns an initialized ResponseDecoder. The newOutput
+// function returns the output value bindings will be applied to, and must
+// return a pointer, (e.g. &GetObjectOutput{}).
+func NewResponseDecoder(newOutput func() interface{}) *ResponseDecoder {
+	return &ResponseDecoder{
+		newOutput: newOutput,
+	}
+}
+
+// BindStatus registers the function to bind the response's HTTP status code
+// to the output value.
+func (d *ResponseDecoder) BindStatus(fn func(output interface{}, statusCode int) error) *ResponseDecoder {
+	d.bindings = append(d.bindings, func(resp *Response, output interface{}) error {
+		if err := fn(output, resp.StatusCode); err != nil {
+			return fmt.Errorf("failed to bind response status code, %w", err)
+		}
+		return nil
+	})
+	return d
+}
+
+// BindHeader registers the function to bind the values of the named response
+// header to the output value. The function is not invoked if the response
+// does not include the header.
+func (d *ResponseDecoder) BindHeader(name string, fn func(output interface{}, values []string) error) *ResponseDecoder {
+	name = http.CanonicalHeaderKey(name)
+	d.bindings = append(d.bindings, func(resp *Response, output interface{}) error {
+		values := resp.Header[name]
+		if len(values) == 0 {
+			return nil
+		}
+		if err := fn(output, values); err != nil {
+			return fmt.Errorf("failed to bind response header %s, %w", name, err)
+		}
+		return nil
+	})
+	return d
+}
+
+// BindPayload registers the function to bind the response's body to the
+// output value. If the function retains the body, (e.g. a streaming output
+// member), it is responsible for closing it.
+func (d *ResponseDecoder) BindPayload(fn func(output interface{}, body io.Reader) error) *ResponseDecoder {
+	d.bindings = append(d.bindings, func(resp *Response, output interface{}) error {
+		if resp.Body == nil {
+			return nil
+		}
+		if err := fn(output, resp.Body); err != nil {
+			return fmt.Errorf("failed to bind response payload, %w", err)
+		}
+		return nil
+	})
+	return d
+}
+
+// Decode returns a new output value with the registered bindings applied from
+// the response.
+func (d *ResponseDecoder) Decode(resp *Response) (interface{}, error) {
+	output := d.newOutput()
+	for _, bind := range d.bindings {
+		if err := bind(resp, output); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+// AddResponseDecoderMiddleware adds the middleware decoding successful
+// responses with the ResponseDecoder to the stack's Deserialize step.
+func AddResponseDecoderMiddleware(stack *middleware.Stack, d *ResponseDecoder) error {
+	return stack.Deserialize.Add(&responseDecoderMiddleware{decoder: d}, middleware.After)
+}
+
+type responseDecoderMiddleware struct {
+	decoder *ResponseDecoder
+}
+
+// ID returns the middleware identifier.
+func (*responseDecoderMiddleware) ID() string { return "OperationDeserializer" }
+
+// HandleDeserialize decodes the raw HTTP response into the operation's
+// result. Responses with a status code outside of the 2xx range are returned
+// as a ResponseError.
+func (m *responseDecoderMiddleware) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return out, metadata, &ResponseError{
+			Response: resp,
+			Err:      fmt.Errorf("unexpected response status %s", resp.Status),
+		}
+	}
+
+	out.Result, err = m.decoder.Decode(resp)
+	if err != nil {
+		return out, metadata, &ResponseError{Response: resp, Err: err}
+	}
+
+	return out, metadata, nil
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockDecoderOutput struct {
+	StatusCode int
+	Count      int
+	Tags       []string
+	Payload    string
+}
+
+func newMockResponseDecoder() *ResponseDecoder {
+	return NewResponseDecoder(func() interface{} { return &mockDecoderOutput{} }).
+		BindStatus(func(output interface{}, statusCode int) error {
+			output.(*mockDecoderOutput).StatusCode = statusCode
+			return nil
+		}).
+		BindHeader("x-count", func(output interface{}, values []string) error {
+			v, err := strconv.Atoi(values[0])
+			if err != nil {
+				return err
+			}
+			output.(*mockDecoderOutput).Count = v
+			return nil
+		}).
+		BindHeader("X-Tag", func(output interface{}, values []string) error {
+			output.(*mockDecoderOutput).Tags = values
+			return nil
+		}).
+		BindPayload(func(output interface{}, body io.Reader) error {
+			b, err := ioutil.ReadAll(body)
+			if err != nil {
+				return err
+			}
+			output.(*mockDecoderOutput).Payload = string(b)
+			return nil
+		})
+}
+
+func TestResponseDecoderMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		StatusCode int
+		Header     http.Header
+		Body       string
+		Expect     *mockDecoderOutput
+		ExpectErr  string
+	}{
+		"bind all": {
+			StatusCode: 201,
+			Header: http.Header{
+				"X-Count": []string{"42"},
+				"X-Tag":   []string{"a", "b"},
+			},
+			Body: "hello",
+			Expect: &mockDecoderOutput{
+				StatusCode: 201,
+				Count:      42,
+				Tags:       []string{"a", "b"},
+				Payload:    "hello",
+			},
+		},
+		"missing header": {
+			StatusCode: 200,
+			Header:     http.Header{},
+			Expect: &mockDecoderOutput{
+				StatusCode: 200,
+			},
+		},
+		"binding error": {
+			StatusCode: 200,
+			Header: http.Header{
+				"X-Count": []string{"abc"},
+			},
+			ExpectErr: "failed to bind response header X-Count",
+		},
+		"error status": {
+			StatusCode: 404,
+			Header:     http.Header{},
+			ExpectErr:  "unexpected response status",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			if err := AddResponseDecoderMiddleware(stack, newMockResponseDecoder()); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				return &Response{
+					Response: &http.Response{
+						StatusCode: c.StatusCode,
+						Status:     fmt.Sprintf("%d %s", c.StatusCode, http.StatusText(c.StatusCode)),
+						Header:     c.Header,
+						Body:       ioutil.NopCloser(strings.NewReader(c.Body)),
+					},
+				}, middleware.Metadata{}, nil
+			})
+
+			result, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				var respErr *ResponseError
+				if !errors.As(err, &respErr) {
+					t.Errorf("expect %T error, got %v", respErr, err)
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if diff := cmp.Diff(c.Expect, result); len(diff) != 0 {
+				t.Errorf("expect output to match\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultUnsignedHeaders is the default set of request headers excluded from
+// the signed headers, as they may be modified after the request is signed,
+// (e.g. by proxies or the HTTP client).
+var DefaultUnsignedHeaders = []string{
+	"Authorization",
+	"User-Agent",
+	"X-Amzn-Trace-Id",
+	"Expect",
+	"Transfer-Encoding",
+}
+
+// SignedHeadersOptions provides the options for computing the signed headers
+// of a request.
+type SignedHeadersOptions struct {
+	// Headers which must be present on the request, and are always signed,
+	// (e.g. Content-Type). The host header is always signed.
+	RequiredHeaders []string
+
+	// Headers which are not signed. Defaults to DefaultUnsignedHeaders.
+	UnsignedHeaders []string
+}
+
+// SignedHeaders returns the sorted, lowercase, names of the request headers
+// to be signed, from the headers present on the request at the time of
+// signing. The host header is always included, from the request's Host or URL
+// host.
+//
+// Returns an error if the request does not have a host, or is missing any of
+// the required headers.
+func SignedHeaders(req *Request, optFns ...func(*SignedHeadersOptions)) ([]string, error) {
+	options := SignedHeadersOptions{
+		UnsignedHeaders: DefaultUnsignedHeaders,
+	}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	if len(req.Host) == 0 && (req.URL == nil || len(req.URL.Host) == 0) {
+		return nil, fmt.Errorf("request host is required to be signed")
+	}
+
+	unsigned := map[string]struct{}{}
+	for _, h := range options.UnsignedHeaders {
+		unsigned[strings.ToLower(h)] = struct{}{}
+	}
+
+	names := map[string]struct{}{
+		"host": {},
+	}
+	for k := range req.Header {
+		name := strings.ToLower(k)
+		if _, ok := unsigned[name]; ok {
+			continue
+		}
+		names[name] = struct{}{}
+	}
+
+	for _, h := range options.RequiredHeaders {
+		name := strings.ToLower(h)
+		if _, ok := names[name]; !ok {
+			return nil, fmt.Errorf("required signed header %s not set on request", h)
+		}
+	}
+
+	signed := make([]string, 0, len(names))
+	for name := range names {
+		signed = append(signed, name)
+	}
+	sort.Strings(signed)
+
+	return signed, nil
+}
+
+// SigningDetails describes how a request was signed, for debugging signature
+// mismatches.
+type SigningDetails struct {
+	// The sorted, lowercase, names of the headers that were signed.
+	SignedHeaders []string
+
+	// The timestamp the request was signed with.
+	Timestamp time.Time
+
+	// The canonical request the signature was computed from, if the signer
+	// computes one, (e.g. SigV4).
+	CanonicalRequest string
+
+	// The string the signature was computed over, if the signer computes
+	// one, (e.g. SigV4).
+	StringToSign string
+}
+
+// OnSignFunc is invoked by signers with the details of how a request was
+// signed.
+type OnSignFunc func(ctx context.Context, details SigningDetails)
+
+type onSignKey struct{}
+
+// SetOnSign returns a context with the function signers invoke with the
+// details of how the operation's request was signed.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func SetOnSign(ctx context.Context, fn OnSignFunc) context.Context {
+	return middleware.WithStackValue(ctx, onSignKey{}, fn)
+}
+
+// GetOnSign returns the function signers invoke with the details of how the
+// operation's request was signed, or nil if not set.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetOnSign(ctx context.Context) OnSignFunc {
+	v, _ := middleware.GetStackValue(ctx, onSignKey{}).(OnSignFunc)
+	return v
+}
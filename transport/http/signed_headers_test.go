@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSignedHeaders(t *testing.T) {
+	cases := map[string]struct {
+		Host      string
+		URLHost   string
+		Header    http.Header
+		Options   func(*SignedHeadersOptions)
+		Expect    []string
+		ExpectErr bool
+	}{
+		"host only": {
+			URLHost: "example.com",
+			Header:  http.Header{},
+			Expect:  []string{"host"},
+		},
+		"sorted lowercase": {
+			URLHost: "example.com",
+			Header: http.Header{
+				"X-Amz-Date":     []string{"20150830T123600Z"},
+				"Content-Type":   []string{"application/json"},
+				"x-custom":       []string{"a"},
+				"Authorization":  []string{"sig"},
+				"User-Agent":     []string{"agent"},
+				"Content-Length": []string{"10"},
+			},
+			Expect: []string{"content-length", "content-type", "host", "x-amz-date", "x-custom"},
+		},
+		"host from request host": {
+			Host:   "example.com",
+			Header: http.Header{"Host": []string{"example.com"}},
+			Expect: []string{"host"},
+		},
+		"required header": {
+			URLHost: "example.com",
+			Header:  http.Header{"Content-Type": []string{"application/json"}},
+			Options: func(o *SignedHeadersOptions) {
+				o.RequiredHeaders = []string{"Content-Type"}
+			},
+			Expect: []string{"content-type", "host"},
+		},
+		"missing required header": {
+			URLHost: "example.com",
+			Header:  http.Header{},
+			Options: func(o *SignedHeadersOptions) {
+				o.RequiredHeaders = []string{"Content-Type"}
+			},
+			ExpectErr: true,
+		},
+		"missing host": {
+			Header:    http.Header{},
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.Host = c.Host
+			req.URL.Host = c.URLHost
+			req.Header = c.Header
+
+			var optFns []func(*SignedHeadersOptions)
+			if c.Options != nil {
+				optFns = append(optFns, c.Options)
+			}
+
+			actual, err := SignedHeaders(req, optFns...)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if diff := cmp.Diff(c.Expect, actual); len(diff) != 0 {
+				t.Errorf("expect signed headers to match\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOnSign(t *testing.T) {
+	ctx := context.Background()
+	if fn := GetOnSign(ctx); fn != nil {
+		t.Fatalf("expect no OnSign func")
+	}
+
+	var actual SigningDetails
+	ctx = SetOnSign(ctx, func(ctx context.Context, details SigningDetails) {
+		actual = details
+	})
+
+	expect := SigningDetails{
+		SignedHeaders: []string{"host", "x-amz-date"},
+		Timestamp:     time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+	}
+	GetOnSign(ctx)(ctx, expect)
+
+	if diff := cmp.Diff(expect, actual); len(diff) != 0 {
+		t.Errorf("expect signing details to match\n%s", diff)
+	}
+}
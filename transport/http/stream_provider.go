@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// StreamProvider is implemented by the input of raw passthrough operations,
+// whose input is the request body stream instead of a serialized structure.
+type StreamProvider interface {
+	// Returns the reader of the request body. May return nil if the request
+	// has no body.
+	InputStream() io.Reader
+}
+
+// AddStreamPassthroughMiddleware adds the middleware serializing the input of
+// raw passthrough operations to the end of the stack's Serialize step. The
+// input must implement StreamProvider, and its stream is used as the request
+// body without being encoded.
+//
+// The request's Content-Type header is set to application/octet-stream, if the
+// header is not already set.
+func AddStreamPassthroughMiddleware(stack *middleware.Stack) error {
+	return stack.Serialize.Add(&streamPassthrough{}, middleware.After)
+}
+
+type streamPassthrough struct{}
+
+// ID returns the middleware identifier.
+func (*streamPassthrough) ID() string { return "StreamPassthroughSerializer" }
+
+// HandleSerialize sets the stream of the input as the request's body.
+func (*streamPassthrough) HandleSerialize(
+	ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+) (
+	out middleware.SerializeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	provider, ok := in.Parameters.(StreamProvider)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown input type %T, expect %T",
+			in.Parameters, (*StreamProvider)(nil))
+	}
+
+	stream := provider.InputStream()
+	if stream == nil {
+		return next.HandleSerialize(ctx, in)
+	}
+
+	if req, err = req.SetStream(stream); err != nil {
+		return out, metadata, fmt.Errorf("failed to set input stream, %w", err)
+	}
+	if len(req.Header.Get("Content-Type")) == 0 {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	in.Request = req
+
+	return next.HandleSerialize(ctx, in)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type mockStreamInput struct {
+	Body io.Reader
+}
+
+func (i *mockStreamInput) InputStream() io.Reader { return i.Body }
+
+func TestStreamPassthroughMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		Input             interface{}
+		ExpectBody        string
+		ExpectContentType string
+		ExpectErr         string
+	}{
+		"raw reader": {
+			Input: &mockStreamInput{
+				Body: ioutil.NopCloser(strings.NewReader("raw payload")),
+			},
+			ExpectBody:        "raw payload",
+			ExpectContentType: "application/octet-stream",
+		},
+		"nil stream": {
+			Input: &mockStreamInput{},
+		},
+		"not stream provider": {
+			Input:     struct{}{},
+			ExpectErr: "unknown input type",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			if err := AddStreamPassthroughMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var req *Request
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				req = input.(*Request)
+				return nil, middleware.Metadata{}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), c.Input)
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectContentType, req.Header.Get("Content-Type"); e != a {
+				t.Errorf("expect %q content type, got %q", e, a)
+			}
+
+			if len(c.ExpectBody) == 0 {
+				if stream := req.GetStream(); stream != nil {
+					t.Errorf("expect no stream, got %v", stream)
+				}
+				return
+			}
+
+			if e, a := c.Input.(*mockStreamInput).Body, req.GetStream(); e != a {
+				t.Errorf("expect input stream passed through, got %T", a)
+			}
+			actual, err := ioutil.ReadAll(req.GetStream())
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ExpectBody, string(actual); e != a {
+				t.Errorf("expect %q body, got %q", e, a)
+			}
+		})
+	}
+}
@@ -2,16 +2,49 @@ package http
 
 import "strings"
 
+// TrailingSlashMode provides the behavior for the trailing slash of a path
+// joined with JoinPathWithTrailingSlash.
+type TrailingSlashMode int
+
+// Enumeration values for TrailingSlashMode.
+const (
+	// PreserveTrailingSlash preserves the trailing slash of the added path.
+	// If the added path is empty, or the root path, the trailing slash of the
+	// base path is preserved.
+	PreserveTrailingSlash TrailingSlashMode = iota
+
+	// RemoveTrailingSlash removes all trailing slashes from the joined path,
+	// unless the joined path is the root path.
+	RemoveTrailingSlash
+
+	// AddTrailingSlash ensures the joined path ends with a single trailing
+	// slash, if it did not already end with one.
+	AddTrailingSlash
+)
+
 // JoinPath returns an absolute URL path composed of the two paths provided.
-// Enforces that the returned path begins with '/'. If added path is empty the
+// Enforces that the returned path begins with '/'. The trailing slash of the
+// added path is preserved. If added path is empty, or the root path, the
 // returned path suffix will match the first parameter suffix.
 func JoinPath(a, b string) string {
+	return JoinPathWithTrailingSlash(a, b, PreserveTrailingSlash)
+}
+
+// JoinPathWithTrailingSlash returns an absolute URL path composed of the two
+// paths provided, with the trailing slash of the joined path handled as
+// specified by the mode. Enforces that the returned path begins with '/'.
+//
+// Some services treat paths with and without a trailing slash as different
+// resources, in which case PreserveTrailingSlash should be used.
+func JoinPathWithTrailingSlash(a, b string, mode TrailingSlashMode) string {
 	if len(a) == 0 {
 		a = "/"
 	} else if a[0] != '/' {
 		a = "/" + a
 	}
 
+	// The root path adds nothing to the base path, including a trailing
+	// slash.
 	if len(b) != 0 && b[0] == '/' {
 		b = b[1:]
 	}
@@ -20,7 +53,20 @@ func JoinPath(a, b string) string {
 		a = a + "/"
 	}
 
-	return a + b
+	p := a + b
+
+	switch mode {
+	case RemoveTrailingSlash:
+		for len(p) > 1 && p[len(p)-1] == '/' {
+			p = p[:len(p)-1]
+		}
+	case AddTrailingSlash:
+		if p[len(p)-1] != '/' {
+			p += "/"
+		}
+	}
+
+	return p
 }
 
 // JoinRawQuery returns an absolute raw query expression. Any duplicate '&'
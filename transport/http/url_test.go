@@ -50,6 +50,26 @@ func TestJoinPath(t *testing.T) {
 			A: "foo//", B: "//bar",
 			Expect: "/foo///bar",
 		},
+		10: {
+			A: "/foo", B: "/",
+			Expect: "/foo",
+		},
+		11: {
+			A: "/foo", B: "/bar/",
+			Expect: "/foo/bar/",
+		},
+		12: {
+			A: "/foo/", B: "/bar",
+			Expect: "/foo/bar",
+		},
+		13: {
+			A: "/", B: "/",
+			Expect: "/",
+		},
+		14: {
+			A: "/foo/", B: "/",
+			Expect: "/foo/",
+		},
 	}
 
 	for i, c := range cases {
@@ -62,6 +82,60 @@ func TestJoinPath(t *testing.T) {
 	}
 }
 
+func TestJoinPathWithTrailingSlash(t *testing.T) {
+	cases := []struct {
+		A, B   string
+		Mode   TrailingSlashMode
+		Expect string
+	}{
+		0: {
+			A: "/foo", B: "/bar/", Mode: PreserveTrailingSlash,
+			Expect: "/foo/bar/",
+		},
+		1: {
+			A: "/foo/", B: "/bar", Mode: PreserveTrailingSlash,
+			Expect: "/foo/bar",
+		},
+		2: {
+			A: "/foo/", B: "", Mode: PreserveTrailingSlash,
+			Expect: "/foo/",
+		},
+		3: {
+			A: "/foo", B: "/bar/", Mode: RemoveTrailingSlash,
+			Expect: "/foo/bar",
+		},
+		4: {
+			A: "/foo/", B: "", Mode: RemoveTrailingSlash,
+			Expect: "/foo",
+		},
+		5: {
+			A: "", B: "/", Mode: RemoveTrailingSlash,
+			Expect: "/",
+		},
+		6: {
+			A: "/foo", B: "/bar", Mode: AddTrailingSlash,
+			Expect: "/foo/bar/",
+		},
+		7: {
+			A: "/foo", B: "/bar/", Mode: AddTrailingSlash,
+			Expect: "/foo/bar/",
+		},
+		8: {
+			A: "/foo", B: "", Mode: AddTrailingSlash,
+			Expect: "/foo/",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("%d:%s,%s:%s", i, c.A, c.B, c.Expect), func(t *testing.T) {
+			actual := JoinPathWithTrailingSlash(c.A, c.B, c.Mode)
+			if e, a := c.Expect, actual; e != a {
+				t.Errorf("expect %v path, got %v", e, a)
+			}
+		})
+	}
+}
+
 func TestJoinRawQuery(t *testing.T) {
 	cases := []struct {
 		A, B   string
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// userAgentFeatureMetadataKey is the User-Agent component key the feature ids
+// active for an operation are appended with.
+const userAgentFeatureMetadataKey = "m"
+
+type userAgentFeaturesCtxKey struct{}
+
+// AddUserAgentFeature returns a context with the short feature id added to
+// the features active for the operation, (e.g. a request compression
+// middleware adding its feature id when it compresses the request body). The
+// features are appended to the request's User-Agent by the middleware added
+// with AddUserAgentFeaturesMiddleware.
+//
+// Feature ids should be short, and must not contain spaces or commas.
+// Features must be added before the end of the Build step to be included in
+// the User-Agent.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func AddUserAgentFeature(ctx context.Context, feature string) context.Context {
+	features := GetUserAgentFeatures(ctx)
+	for _, f := range features {
+		if f == feature {
+			return ctx
+		}
+	}
+
+	features = append(features, feature)
+	sort.Strings(features)
+
+	return middleware.WithStackValue(ctx, userAgentFeaturesCtxKey{}, features)
+}
+
+// GetUserAgentFeatures returns the sorted feature ids active for the
+// operation.
+//
+// Scoped to stack values. Use middleware#ClearStackValues to clear all stack
+// values.
+func GetUserAgentFeatures(ctx context.Context) []string {
+	v, _ := middleware.GetStackValue(ctx, userAgentFeaturesCtxKey{}).([]string)
+
+	// Copy the features so that additions do not modify the slice of a
+	// parent context.
+	return append([]string(nil), v...)
+}
+
+// AddUserAgentFeaturesMiddleware adds the middleware appending the features
+// active for the operation, see AddUserAgentFeature, to the request's
+// User-Agent header. The middleware is added to the end of the stack's Build
+// step. The features are appended as a single compact component, (e.g.
+// "m/A,B,Z").
+func AddUserAgentFeaturesMiddleware(stack *middleware.Stack) error {
+	return stack.Build.Add(&userAgentFeatures{}, middleware.After)
+}
+
+type userAgentFeatures struct{}
+
+// ID returns the middleware identifier.
+func (*userAgentFeatures) ID() string { return "UserAgentFeatures" }
+
+// HandleBuild appends the operation's active features to the User-Agent
+// header of the request.
+func (*userAgentFeatures) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	features := GetUserAgentFeatures(ctx)
+	if len(features) == 0 {
+		return next.HandleBuild(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	b := NewUserAgentBuilder()
+	if ua := req.Header.Get("User-Agent"); len(ua) != 0 {
+		b.AddKey(ua)
+	}
+	b.AddKeyValue(userAgentFeatureMetadataKey, strings.Join(features, ","))
+	req.Header.Set("User-Agent", b.Build())
+
+	return next.HandleBuild(ctx, in)
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestUserAgentFeaturesMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		UserAgent   string
+		Compression bool
+		Features    []string
+		Expect      string
+	}{
+		"no features": {
+			UserAgent: "smithy-go/1.0",
+			Expect:    "smithy-go/1.0",
+		},
+		"compression enabled": {
+			UserAgent:   "smithy-go/1.0",
+			Compression: true,
+			Expect:      "smithy-go/1.0 m/Z",
+		},
+		"multiple features sorted and unique": {
+			UserAgent:   "smithy-go/1.0",
+			Compression: true,
+			Features:    []string{"Z", "B", "A"},
+			Expect:      "smithy-go/1.0 m/A,B,Z",
+		},
+		"no user agent": {
+			Compression: true,
+			Expect:      "m/Z",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("UserAgent", func(
+				ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+			) (
+				middleware.SerializeOutput, middleware.Metadata, error,
+			) {
+				if len(c.UserAgent) != 0 {
+					in.Request.(*Request).Header.Set("User-Agent", c.UserAgent)
+				}
+				for _, f := range c.Features {
+					ctx = AddUserAgentFeature(ctx, f)
+				}
+				return next.HandleSerialize(ctx, in)
+			}), middleware.After)
+
+			// Mock request compression middleware, that advertises its
+			// feature when enabled.
+			stack.Build.Add(middleware.BuildMiddlewareFunc("RequestCompression", func(
+				ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+			) (
+				middleware.BuildOutput, middleware.Metadata, error,
+			) {
+				if c.Compression {
+					ctx = AddUserAgentFeature(ctx, "Z")
+				}
+				return next.HandleBuild(ctx, in)
+			}), middleware.After)
+
+			if err := AddUserAgentFeaturesMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var userAgent string
+			handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				interface{}, middleware.Metadata, error,
+			) {
+				userAgent = input.(*Request).Header.Get("User-Agent")
+				return nil, middleware.Metadata{}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.Expect, userAgent; e != a {
+				t.Errorf("expect %q user agent, got %q", e, a)
+			}
+		})
+	}
+}
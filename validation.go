@@ -138,3 +138,72 @@ func NewErrParamRequired(field string) *ParamRequiredError {
 		},
 	}
 }
+
+// OneOfField is a field of a set of mutually exclusive input fields, and if
+// the field is set.
+type OneOfField struct {
+	Name  string
+	IsSet bool
+}
+
+// A ParamOneOfError represents an error for a set of mutually exclusive
+// fields, where not exactly one, or more than one, of the fields were set.
+type ParamOneOfError struct {
+	invalidParamError
+
+	// The names of the mutually exclusive fields.
+	Fields []string
+
+	// The names of the fields that were set.
+	SetFields []string
+}
+
+// OneOf returns a ParamOneOfError if not exactly one of the fields is set.
+// Returns nil otherwise. Used to validate union-like input constraints,
+// (e.g. invalidParams.Add(err) if the returned error is not nil).
+func OneOf(fields ...OneOfField) InvalidParamError {
+	names, set := oneOfFields(fields)
+	switch len(set) {
+	case 1:
+		return nil
+	case 0:
+		return newErrParamOneOf(names, set, "exactly one field must be set, none set")
+	default:
+		return newErrParamOneOf(names, set,
+			fmt.Sprintf("exactly one field must be set, %d set (%s)", len(set), strings.Join(set, ", ")))
+	}
+}
+
+// AtMostOneOf returns a ParamOneOfError if more than one of the fields is
+// set. Returns nil otherwise.
+func AtMostOneOf(fields ...OneOfField) InvalidParamError {
+	names, set := oneOfFields(fields)
+	if len(set) <= 1 {
+		return nil
+	}
+
+	return newErrParamOneOf(names, set,
+		fmt.Sprintf("at most one field may be set, %d set (%s)", len(set), strings.Join(set, ", ")))
+}
+
+func newErrParamOneOf(names, set []string, reason string) *ParamOneOfError {
+	return &ParamOneOfError{
+		invalidParamError: invalidParamError{
+			field:  strings.Join(names, "|"),
+			reason: reason,
+		},
+		Fields:    names,
+		SetFields: set,
+	}
+}
+
+func oneOfFields(fields []OneOfField) (names, set []string) {
+	names = make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.Name)
+		if f.IsSet {
+			set = append(set, f.Name)
+		}
+	}
+	return names, set
+}
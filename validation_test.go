@@ -0,0 +1,78 @@
+package smithy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOneOf(t *testing.T) {
+	cases := map[string]struct {
+		Validate  func(...OneOfField) InvalidParamError
+		Fields    []OneOfField
+		ExpectErr string
+		ExpectSet []string
+	}{
+		"exactly one set": {
+			Validate: OneOf,
+			Fields:   []OneOfField{{"KeyId", true}, {"KeyAlias", false}},
+		},
+		"both set": {
+			Validate:  OneOf,
+			Fields:    []OneOfField{{"KeyId", true}, {"KeyAlias", true}},
+			ExpectErr: "1 validation error(s) found.\n- exactly one field must be set, 2 set (KeyId, KeyAlias), EncryptInput.KeyId|KeyAlias.\n",
+			ExpectSet: []string{"KeyId", "KeyAlias"},
+		},
+		"none set": {
+			Validate:  OneOf,
+			Fields:    []OneOfField{{"KeyId", false}, {"KeyAlias", false}},
+			ExpectErr: "1 validation error(s) found.\n- exactly one field must be set, none set, EncryptInput.KeyId|KeyAlias.\n",
+		},
+		"at most one, none set": {
+			Validate: AtMostOneOf,
+			Fields:   []OneOfField{{"KeyId", false}, {"KeyAlias", false}},
+		},
+		"at most one, one set": {
+			Validate: AtMostOneOf,
+			Fields:   []OneOfField{{"KeyId", false}, {"KeyAlias", true}},
+		},
+		"at most one, both set": {
+			Validate:  AtMostOneOf,
+			Fields:    []OneOfField{{"KeyId", true}, {"KeyAlias", true}, {"KeyArn", false}},
+			ExpectErr: "1 validation error(s) found.\n- at most one field may be set, 2 set (KeyId, KeyAlias), EncryptInput.KeyId|KeyAlias|KeyArn.\n",
+			ExpectSet: []string{"KeyId", "KeyAlias"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			invalidParams := InvalidParamsError{Context: "EncryptInput"}
+			if err := c.Validate(c.Fields...); err != nil {
+				invalidParams.Add(err)
+			}
+
+			if len(c.ExpectErr) == 0 {
+				if e, a := 0, invalidParams.Len(); e != a {
+					t.Fatalf("expect %v errors, got %v, %v", e, a, invalidParams)
+				}
+				return
+			}
+
+			if e, a := 1, invalidParams.Len(); e != a {
+				t.Fatalf("expect %v errors, got %v", e, a)
+			}
+			if e, a := c.ExpectErr, invalidParams.Error(); e != a {
+				t.Errorf("expect %q error, got %q", e, a)
+			}
+
+			var oneOfErr *ParamOneOfError
+			if !errors.As(invalidParams.Errs()[0], &oneOfErr) {
+				t.Fatalf("expect %T error, got %v", oneOfErr, invalidParams.Errs()[0])
+			}
+			if diff := cmp.Diff(c.ExpectSet, oneOfErr.SetFields); len(diff) != 0 {
+				t.Errorf("expect set fields to match\n%s", diff)
+			}
+		})
+	}
+}